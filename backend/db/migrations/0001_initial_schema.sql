@@ -1,3 +1,6 @@
+-- ベースラインのスキーマ（mysqldefで管理していたdb/schema.sqlと同じ）。作成済みのデータベースにはIF NOT EXISTSで何もしない
+-- 以降のスキーマ変更はこのファイルを書き換えず、新しい番号のファイルを追加する
+
 CREATE TABLE IF NOT EXISTS user (
     id INT AUTO_INCREMENT PRIMARY KEY,
     username VARCHAR(255) UNIQUE NOT NULL,
@@ -9,4 +12,4 @@ CREATE TABLE IF NOT EXISTS score (
     user_id INT NOT NULL,
     _value INT NOT NULL,
     CONSTRAINT fk_score_user_id FOREIGN KEY (user_id) REFERENCES user(id) ON DELETE CASCADE
-);
\ No newline at end of file
+);
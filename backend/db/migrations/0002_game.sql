@@ -0,0 +1,31 @@
+-- 終了したゲームの結果
+CREATE TABLE IF NOT EXISTS game (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    uuid CHAR(36) NOT NULL,
+    room_id INT NOT NULL,
+    mode VARCHAR(32) NOT NULL,
+    seed BIGINT NOT NULL,
+    started_at DATETIME(3) NOT NULL,
+    ended_at DATETIME(3) NOT NULL,
+    initial_board JSON NOT NULL,
+    UNIQUE KEY uq_game_uuid (uuid),
+    INDEX idx_game_ended_at (ended_at)
+);
+
+CREATE TABLE IF NOT EXISTS game_player (
+    game_id INT NOT NULL,
+    user_id INT NOT NULL,
+    score INT NOT NULL,
+    ranking INT NOT NULL,
+    cleared_regions INT NOT NULL,
+    accepted_formulas INT NOT NULL DEFAULT 0,
+    rejected_formulas INT NOT NULL DEFAULT 0,
+    PRIMARY KEY (game_id, user_id),
+    INDEX idx_game_player_user_id (user_id),
+    CONSTRAINT fk_game_player_game_id FOREIGN KEY (game_id) REFERENCES game(id) ON DELETE CASCADE,
+    CONSTRAINT fk_game_player_user_id FOREIGN KEY (user_id) REFERENCES user(id) ON DELETE CASCADE
+);
+
+-- game_idはゲーム結果の保存以前に記録されたスコアではNULL
+ALTER TABLE score ADD COLUMN game_id INT NULL;
+ALTER TABLE score ADD CONSTRAINT fk_score_game_id FOREIGN KEY (game_id) REFERENCES game(id) ON DELETE SET NULL;
@@ -0,0 +1,5 @@
+-- created_atはリーダーボードの期間（日・週・月）での絞り込みに使う
+ALTER TABLE score ADD COLUMN created_at DATETIME(3) NOT NULL DEFAULT CURRENT_TIMESTAMP(3);
+CREATE INDEX idx_score_value ON score (_value, id);
+CREATE INDEX idx_score_created_at_value ON score (created_at, _value);
+CREATE INDEX idx_score_user_id_value ON score (user_id, _value);
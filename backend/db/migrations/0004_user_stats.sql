@@ -0,0 +1,14 @@
+-- ユーザーごとの通算成績。ゲーム終了時にgame_playerと同じトランザクションで更新する
+CREATE TABLE IF NOT EXISTS user_stats (
+    user_id INT PRIMARY KEY,
+    games_played INT NOT NULL DEFAULT 0,
+    wins INT NOT NULL DEFAULT 0,
+    best_score INT NOT NULL DEFAULT 0,
+    total_score BIGINT NOT NULL DEFAULT 0,
+    current_streak INT NOT NULL DEFAULT 0,
+    longest_streak INT NOT NULL DEFAULT 0,
+    accepted_formulas INT NOT NULL DEFAULT 0,
+    rejected_formulas INT NOT NULL DEFAULT 0,
+    updated_at DATETIME(3) NOT NULL DEFAULT CURRENT_TIMESTAMP(3) ON UPDATE CURRENT_TIMESTAMP(3),
+    CONSTRAINT fk_user_stats_user_id FOREIGN KEY (user_id) REFERENCES user(id) ON DELETE CASCADE
+);
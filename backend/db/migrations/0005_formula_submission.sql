@@ -0,0 +1,19 @@
+-- 数式の提出ごとの記録。提出時点ではgameの行はまだないため、game.uuidで紐づける
+CREATE TABLE IF NOT EXISTS formula_submission (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    game_uuid CHAR(36) NOT NULL,
+    room_id INT NOT NULL,
+    user_id INT NOT NULL,
+    submitted_version INT NOT NULL,
+    board_version INT NOT NULL,
+    expression VARCHAR(255) NOT NULL,
+    outcome VARCHAR(16) NOT NULL,
+    matched_regions JSON NOT NULL,
+    gained_score INT NOT NULL,
+    latency_us INT NOT NULL,
+    submitted_at DATETIME(3) NOT NULL,
+    INDEX idx_formula_submission_game_uuid (game_uuid),
+    INDEX idx_formula_submission_user_id (user_id, submitted_at),
+    INDEX idx_formula_submission_outcome (outcome, submitted_at),
+    CONSTRAINT fk_formula_submission_user_id FOREIGN KEY (user_id) REFERENCES user(id) ON DELETE CASCADE
+);
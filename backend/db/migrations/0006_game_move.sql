@@ -0,0 +1,15 @@
+-- ゲーム中に受理された数式（リプレイ用）。changesは適用後のバージョンで消した組
+CREATE TABLE IF NOT EXISTS game_move (
+    game_id INT NOT NULL,
+    seq INT NOT NULL,
+    user_id INT NOT NULL,
+    expression VARCHAR(255) NOT NULL,
+    submitted_version INT NOT NULL,
+    version INT NOT NULL,
+    changes JSON NOT NULL,
+    gained_score INT NOT NULL,
+    submitted_at DATETIME(3) NOT NULL,
+    PRIMARY KEY (game_id, seq),
+    CONSTRAINT fk_game_move_game_id FOREIGN KEY (game_id) REFERENCES game(id) ON DELETE CASCADE,
+    CONSTRAINT fk_game_move_user_id FOREIGN KEY (user_id) REFERENCES user(id) ON DELETE CASCADE
+);
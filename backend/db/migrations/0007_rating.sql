@@ -0,0 +1,25 @@
+-- ユーザーごとのレーティング（Glicko方式）。2人以上のゲームの終了時に更新する
+CREATE TABLE IF NOT EXISTS user_rating (
+    user_id INT PRIMARY KEY,
+    rating DOUBLE NOT NULL,
+    deviation DOUBLE NOT NULL,
+    games_played INT NOT NULL DEFAULT 0,
+    updated_at DATETIME(3) NOT NULL DEFAULT CURRENT_TIMESTAMP(3) ON UPDATE CURRENT_TIMESTAMP(3),
+    INDEX idx_user_rating_rating (rating),
+    CONSTRAINT fk_user_rating_user_id FOREIGN KEY (user_id) REFERENCES user(id) ON DELETE CASCADE
+);
+
+-- ゲームごとのレーティングの変化
+CREATE TABLE IF NOT EXISTS rating_history (
+    game_id INT NOT NULL,
+    user_id INT NOT NULL,
+    rating_before DOUBLE NOT NULL,
+    deviation_before DOUBLE NOT NULL,
+    rating_after DOUBLE NOT NULL,
+    deviation_after DOUBLE NOT NULL,
+    created_at DATETIME(3) NOT NULL DEFAULT CURRENT_TIMESTAMP(3),
+    PRIMARY KEY (game_id, user_id),
+    INDEX idx_rating_history_user_id (user_id, created_at),
+    CONSTRAINT fk_rating_history_game_id FOREIGN KEY (game_id) REFERENCES game(id) ON DELETE CASCADE,
+    CONSTRAINT fk_rating_history_user_id FOREIGN KEY (user_id) REFERENCES user(id) ON DELETE CASCADE
+);
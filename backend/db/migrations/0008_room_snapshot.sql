@@ -0,0 +1,9 @@
+-- 再起動後に復元するroomの状態。盤面はシードと受理された数式から再構築するため保存しない
+-- 複数インスタンス構成ではインスタンスごとにroomを持つため、instance_idで区別する
+CREATE TABLE IF NOT EXISTS room_snapshot (
+    instance_id VARCHAR(64) NOT NULL,
+    room_id INT NOT NULL,
+    state JSON NOT NULL,
+    saved_at DATETIME(3) NOT NULL,
+    PRIMARY KEY (instance_id, room_id)
+);
@@ -0,0 +1,17 @@
+-- リフレッシュトークン。トークンそのものは保存せずSHA-256のハッシュだけを持つ
+-- family_idはログインごとのセッションで、ローテーションで発行したトークンは同じfamily_idを引き継ぐ
+-- used_atはローテーション済み、revoked_atはログアウトまたは再利用の検出でセッションごと失効したことを表す
+CREATE TABLE IF NOT EXISTS refresh_token (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    user_id INT NOT NULL,
+    family_id CHAR(36) NOT NULL,
+    token_hash CHAR(64) NOT NULL,
+    expires_at DATETIME(3) NOT NULL,
+    created_at DATETIME(3) NOT NULL,
+    used_at DATETIME(3) NULL,
+    revoked_at DATETIME(3) NULL,
+    UNIQUE KEY uq_refresh_token_hash (token_hash),
+    INDEX idx_refresh_token_family_id (family_id),
+    INDEX idx_refresh_token_expires_at (expires_at),
+    CONSTRAINT fk_refresh_token_user_id FOREIGN KEY (user_id) REFERENCES user(id) ON DELETE CASCADE
+);
@@ -0,0 +1,5 @@
+-- ゲストユーザー。ワンクリックで作られ、パスワードを持たない
+-- guest_expires_atを過ぎても本登録（アップグレード）されなかったゲストは削除される（成績・スコアもCASCADEで消える）
+ALTER TABLE user ADD COLUMN is_guest BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE user ADD COLUMN guest_expires_at DATETIME(3) NULL;
+CREATE INDEX idx_user_guest_expires_at ON user (is_guest, guest_expires_at);
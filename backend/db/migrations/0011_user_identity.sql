@@ -0,0 +1,11 @@
+-- 外部のOAuth2/OIDCプロバイダーのアカウントとuserの紐付け。subjectはIDトークンのsub
+CREATE TABLE IF NOT EXISTS user_identity (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    user_id INT NOT NULL,
+    provider VARCHAR(64) NOT NULL,
+    subject VARCHAR(255) NOT NULL,
+    created_at DATETIME(3) NOT NULL,
+    UNIQUE KEY uq_user_identity_provider_subject (provider, subject),
+    INDEX idx_user_identity_user_id (user_id),
+    CONSTRAINT fk_user_identity_user_id FOREIGN KEY (user_id) REFERENCES user(id) ON DELETE CASCADE
+);
@@ -0,0 +1,15 @@
+-- ゲーム結果の保存（game・game_player）以前に記録されたスコアを、1件ずつmode=legacyのgameに移す
+-- 日時・シード・盤面・相手は記録されていないため、日時は1970-01-01、盤面は空とする（リプレイは提供しない）
+-- uuidはscore.idから決める（ランダムなv4のUUIDとは重ならない）
+INSERT INTO game (uuid, room_id, mode, seed, started_at, ended_at, initial_board)
+SELECT CONCAT('00000000-0000-0000-0000-', LPAD(id, 12, '0')), 0, 'legacy', 0, '1970-01-01 00:00:00', '1970-01-01 00:00:00', '[]'
+FROM score WHERE game_id IS NULL;
+
+INSERT INTO game_player (game_id, user_id, score, ranking, cleared_regions)
+SELECT game.id, score.user_id, score._value, 1, 0
+FROM score JOIN game ON game.uuid = CONCAT('00000000-0000-0000-0000-', LPAD(score.id, 12, '0'))
+WHERE score.game_id IS NULL;
+
+UPDATE score SET game_id = (
+    SELECT game.id FROM game WHERE game.uuid = CONCAT('00000000-0000-0000-0000-', LPAD(score.id, 12, '0'))
+) WHERE game_id IS NULL;
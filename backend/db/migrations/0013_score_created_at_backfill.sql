@@ -0,0 +1,6 @@
+-- 0003で追加したcreated_atは、既存のスコアではマイグレーションの時刻になっている
+-- ゲームのスコアはゲームの終了時刻に、ゲームのないスコアは古い日時（1970-01-01）にする（期間別のリーダーボードには載らず、全期間には載る）
+UPDATE score SET created_at = COALESCE(
+    (SELECT game.ended_at FROM game WHERE game.id = score.game_id),
+    '1970-01-01 00:00:00'
+);
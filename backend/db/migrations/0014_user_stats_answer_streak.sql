@@ -0,0 +1,3 @@
+-- 最長連続正解数。ゲーム中に同じプレイヤーが続けて正解した回数（Room.StreakCount）の、全ゲームを通した最大値
+-- current_streak・longest_streakは連勝数のまま変えない
+ALTER TABLE user_stats ADD COLUMN longest_answer_streak INT NOT NULL DEFAULT 0;
@@ -0,0 +1,13 @@
+-- 0012でmode=legacyのgameに移したスコアを通算成績に加える（user_statsはゲーム終了時にしか更新されないため、移行したスコアが反映されていない）
+-- legacyのgameは1人プレイとして扱い、ゲーム数・スコアだけを加える（勝敗・連勝・数式の数・連続正解数は記録されていない）
+-- 削除済みのユーザー（期限切れのゲストなど）の行は外部キーを満たさないため除く
+INSERT INTO user_stats (user_id, games_played, best_score, total_score)
+SELECT game_player.user_id, COUNT(*), MAX(game_player.score), SUM(game_player.score)
+FROM game_player
+JOIN game ON game.id = game_player.game_id
+JOIN user ON user.id = game_player.user_id
+WHERE game.mode = 'legacy'
+GROUP BY game_player.user_id
+ON DUPLICATE KEY UPDATE user_stats.games_played = user_stats.games_played + VALUES(games_played),
+    user_stats.best_score = GREATEST(user_stats.best_score, VALUES(best_score)),
+    user_stats.total_score = user_stats.total_score + VALUES(total_score);
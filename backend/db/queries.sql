@@ -22,8 +22,137 @@ SELECT id FROM user WHERE username = ?;
 -- name: GetUserByUsername :one
 SELECT * FROM user WHERE username = ?;
 
+-- name: CreateGuestUser :execresult
+INSERT INTO user (username,is_guest,guest_expires_at) VALUES(?,TRUE,?);
+
+-- name: UpgradeGuestUser :execresult
+UPDATE user SET username = ?, password_hash = ?, is_guest = FALSE, guest_expires_at = NULL WHERE id = ? AND is_guest = TRUE AND guest_expires_at IS NOT NULL;
+
+-- 終了したゲームに参加した期限切れのゲストは、リプレイ・レーティングの履歴を残すため削除せずに匿名化する
+-- 匿名化したゲストはis_guest = TRUEでguest_expires_atがNULL（本登録・再度の掃除の対象にならない）
+-- name: AnonymizeExpiredGuestUsers :execresult
+UPDATE user SET username = CONCAT('guest-deleted-', id), guest_expires_at = NULL
+WHERE is_guest = TRUE AND guest_expires_at < ? AND EXISTS (SELECT 1 FROM game_player WHERE game_player.user_id = user.id);
+
+-- name: DeleteExpiredGuestUsers :execresult
+DELETE FROM user WHERE is_guest = TRUE AND guest_expires_at < ?;
+
 -- name: CreateScore :execresult
 INSERT INTO score (user_id,_value) VALUES(?,?);
 
+-- name: CreateGameScore :execresult
+INSERT INTO score (user_id,_value,game_id) VALUES(?,?,?);
+
+-- name: CreateGame :execresult
+INSERT INTO game (uuid,room_id,mode,seed,started_at,ended_at,initial_board) VALUES(?,?,?,?,?,?,?);
+
+-- name: CreateGameMove :exec
+INSERT INTO game_move (game_id,seq,user_id,expression,submitted_version,version,changes,gained_score,submitted_at) VALUES(?,?,?,?,?,?,?,?,?);
+
+-- name: GetGame :one
+SELECT * FROM game WHERE id = ?;
+
+-- name: ListGamePlayers :many
+SELECT game_player.user_id, user.username, game_player.score, game_player.ranking, game_player.cleared_regions, game_player.accepted_formulas, game_player.rejected_formulas
+FROM game_player JOIN user ON game_player.user_id = user.id WHERE game_player.game_id = ? ORDER BY game_player.ranking, game_player.user_id;
+
+-- name: ListGameMoves :many
+SELECT * FROM game_move WHERE game_id = ? ORDER BY seq;
+
+-- name: CreateGamePlayer :exec
+INSERT INTO game_player (game_id,user_id,score,ranking,cleared_regions,accepted_formulas,rejected_formulas) VALUES(?,?,?,?,?,?,?);
+
+-- name: GetUserStats :one
+SELECT * FROM user_stats WHERE user_id = ?;
+
+-- name: GetUserStatsForUpdate :one
+SELECT * FROM user_stats WHERE user_id = ? FOR UPDATE;
+
+-- name: UpsertUserStats :exec
+INSERT INTO user_stats (user_id,games_played,wins,best_score,total_score,current_streak,longest_streak,accepted_formulas,rejected_formulas,longest_answer_streak)
+VALUES(?,?,?,?,?,?,?,?,?,?)
+ON DUPLICATE KEY UPDATE games_played = VALUES(games_played), wins = VALUES(wins), best_score = VALUES(best_score),
+    total_score = VALUES(total_score), current_streak = VALUES(current_streak), longest_streak = VALUES(longest_streak),
+    accepted_formulas = VALUES(accepted_formulas), rejected_formulas = VALUES(rejected_formulas),
+    longest_answer_streak = VALUES(longest_answer_streak);
+
 -- name: GetTop10Scores :many
-SELECT user.username,score._value FROM score JOIN user ON score.user_id = user.id ORDER BY value DESC limit 10;
+SELECT user.username,score._value FROM score JOIN user ON score.user_id = user.id ORDER BY score._value DESC limit 10;
+
+-- name: ListLeaderboardScores :many
+SELECT score.id, score.user_id, user.username, score._value, score.created_at FROM score JOIN user ON score.user_id = user.id
+WHERE score.created_at >= ? AND (score._value < ? OR (score._value = ? AND score.id > ?))
+ORDER BY score._value DESC, score.id ASC LIMIT ?;
+
+-- name: ListLeaderboardBestScores :many
+SELECT score.user_id, user.username, MAX(score._value) AS best_value FROM score JOIN user ON score.user_id = user.id
+WHERE score.created_at >= ?
+GROUP BY score.user_id, user.username
+HAVING MAX(score._value) < ? OR (MAX(score._value) = ? AND score.user_id > ?)
+ORDER BY best_value DESC, score.user_id ASC LIMIT ?;
+
+-- name: GetUserBestScore :one
+SELECT MAX(_value) AS best_value FROM score WHERE user_id = ? AND created_at >= ?;
+
+-- name: CountScoresAbove :one
+SELECT COUNT(*) FROM score WHERE created_at >= ? AND _value > ?;
+
+-- name: CountBestScoresAbove :one
+SELECT COUNT(*) FROM (SELECT MAX(_value) AS best_value FROM score WHERE created_at >= ? GROUP BY user_id) AS best WHERE best.best_value > ?;
+
+-- name: CreateFormulaSubmission :exec
+INSERT INTO formula_submission (game_uuid,room_id,user_id,submitted_version,board_version,expression,outcome,matched_regions,gained_score,latency_us,submitted_at)
+VALUES(?,?,?,?,?,?,?,?,?,?,?);
+
+-- name: GetUserRatingForUpdate :one
+SELECT * FROM user_rating WHERE user_id = ? FOR UPDATE;
+
+-- name: UpsertUserRating :exec
+INSERT INTO user_rating (user_id,rating,deviation,games_played) VALUES(?,?,?,?)
+ON DUPLICATE KEY UPDATE rating = VALUES(rating), deviation = VALUES(deviation), games_played = VALUES(games_played);
+
+-- name: CreateRatingHistory :exec
+INSERT INTO rating_history (game_id,user_id,rating_before,deviation_before,rating_after,deviation_after) VALUES(?,?,?,?,?,?);
+
+-- name: ListRatings :many
+SELECT user_rating.user_id, user.username, user_rating.rating, user_rating.deviation, user_rating.games_played
+FROM user_rating JOIN user ON user_rating.user_id = user.id
+ORDER BY user_rating.rating DESC, user_rating.user_id ASC LIMIT ? OFFSET ?;
+
+-- name: UpsertRoomSnapshot :exec
+INSERT INTO room_snapshot (instance_id,room_id,state,saved_at) VALUES(?,?,?,?)
+ON DUPLICATE KEY UPDATE state = VALUES(state), saved_at = VALUES(saved_at);
+
+-- name: ListRoomSnapshots :many
+SELECT * FROM room_snapshot WHERE instance_id = ? ORDER BY room_id;
+
+-- name: CreateRefreshToken :exec
+INSERT INTO refresh_token (user_id,family_id,token_hash,expires_at,created_at) VALUES(?,?,?,?,?);
+
+-- name: GetRefreshTokenByHash :one
+SELECT * FROM refresh_token WHERE token_hash = ?;
+
+-- name: MarkRefreshTokenUsed :execresult
+UPDATE refresh_token SET used_at = ? WHERE id = ? AND used_at IS NULL AND revoked_at IS NULL;
+
+-- name: RevokeRefreshTokenFamily :exec
+UPDATE refresh_token SET revoked_at = ? WHERE family_id = ? AND revoked_at IS NULL;
+
+-- name: RevokeAnonymizedGuestRefreshTokens :exec
+UPDATE refresh_token SET revoked_at = ?
+WHERE revoked_at IS NULL AND user_id IN (SELECT id FROM user WHERE is_guest = TRUE AND guest_expires_at IS NULL);
+
+-- name: CountRevokedRefreshTokens :one
+SELECT COUNT(*) FROM refresh_token WHERE family_id = ? AND revoked_at IS NOT NULL;
+
+-- name: DeleteExpiredRefreshTokens :exec
+DELETE FROM refresh_token WHERE expires_at < ?;
+
+-- name: CreateUserIdentity :exec
+INSERT INTO user_identity (user_id,provider,subject,created_at) VALUES(?,?,?,?);
+
+-- name: GetUserIdentity :one
+SELECT * FROM user_identity WHERE provider = ? AND subject = ?;
+
+-- name: CountUserIdentities :one
+SELECT COUNT(*) FROM user_identity WHERE user_id = ?;
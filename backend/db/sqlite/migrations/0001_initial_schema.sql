@@ -0,0 +1,14 @@
+-- SQLite版のベースラインのスキーマ（ローカル開発・テスト用）。db/migrationsのMariaDB版と同じ変更を同じ番号で持つ
+-- AUTO_INCREMENTはINTEGER PRIMARY KEY AUTOINCREMENT、JSONはTEXT、DATETIME(3)はDATETIMEとし、ON UPDATEはクエリで更新する
+
+CREATE TABLE IF NOT EXISTS user (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    username VARCHAR(255) UNIQUE NOT NULL,
+    password_hash VARCHAR(255)
+);
+
+CREATE TABLE IF NOT EXISTS score (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id INT NOT NULL REFERENCES user(id) ON DELETE CASCADE,
+    _value INT NOT NULL
+);
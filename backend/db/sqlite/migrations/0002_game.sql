@@ -0,0 +1,25 @@
+CREATE TABLE IF NOT EXISTS game (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    uuid CHAR(36) NOT NULL UNIQUE,
+    room_id INT NOT NULL,
+    mode VARCHAR(32) NOT NULL,
+    seed BIGINT NOT NULL,
+    started_at DATETIME NOT NULL,
+    ended_at DATETIME NOT NULL,
+    initial_board TEXT NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_game_ended_at ON game (ended_at);
+
+CREATE TABLE IF NOT EXISTS game_player (
+    game_id INT NOT NULL REFERENCES game(id) ON DELETE CASCADE,
+    user_id INT NOT NULL REFERENCES user(id) ON DELETE CASCADE,
+    score INT NOT NULL,
+    ranking INT NOT NULL,
+    cleared_regions INT NOT NULL,
+    accepted_formulas INT NOT NULL DEFAULT 0,
+    rejected_formulas INT NOT NULL DEFAULT 0,
+    PRIMARY KEY (game_id, user_id)
+);
+CREATE INDEX IF NOT EXISTS idx_game_player_user_id ON game_player (user_id);
+
+ALTER TABLE score ADD COLUMN game_id INT NULL REFERENCES game(id) ON DELETE SET NULL;
@@ -0,0 +1,14 @@
+-- SQLiteのADD COLUMNはCURRENT_TIMESTAMPを既定値にできないため、テーブルを作り直す
+CREATE TABLE score_new (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id INT NOT NULL REFERENCES user(id) ON DELETE CASCADE,
+    _value INT NOT NULL,
+    game_id INT NULL REFERENCES game(id) ON DELETE SET NULL,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+INSERT INTO score_new (id, user_id, _value, game_id) SELECT id, user_id, _value, game_id FROM score;
+DROP TABLE score;
+ALTER TABLE score_new RENAME TO score;
+CREATE INDEX IF NOT EXISTS idx_score_value ON score (_value, id);
+CREATE INDEX IF NOT EXISTS idx_score_created_at_value ON score (created_at, _value);
+CREATE INDEX IF NOT EXISTS idx_score_user_id_value ON score (user_id, _value);
@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS user_stats (
+    user_id INT PRIMARY KEY REFERENCES user(id) ON DELETE CASCADE,
+    games_played INT NOT NULL DEFAULT 0,
+    wins INT NOT NULL DEFAULT 0,
+    best_score INT NOT NULL DEFAULT 0,
+    total_score BIGINT NOT NULL DEFAULT 0,
+    current_streak INT NOT NULL DEFAULT 0,
+    longest_streak INT NOT NULL DEFAULT 0,
+    accepted_formulas INT NOT NULL DEFAULT 0,
+    rejected_formulas INT NOT NULL DEFAULT 0,
+    updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
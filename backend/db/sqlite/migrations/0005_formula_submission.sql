@@ -0,0 +1,17 @@
+CREATE TABLE IF NOT EXISTS formula_submission (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    game_uuid CHAR(36) NOT NULL,
+    room_id INT NOT NULL,
+    user_id INT NOT NULL REFERENCES user(id) ON DELETE CASCADE,
+    submitted_version INT NOT NULL,
+    board_version INT NOT NULL,
+    expression VARCHAR(255) NOT NULL,
+    outcome VARCHAR(16) NOT NULL,
+    matched_regions TEXT NOT NULL,
+    gained_score INT NOT NULL,
+    latency_us INT NOT NULL,
+    submitted_at DATETIME NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_formula_submission_game_uuid ON formula_submission (game_uuid);
+CREATE INDEX IF NOT EXISTS idx_formula_submission_user_id ON formula_submission (user_id, submitted_at);
+CREATE INDEX IF NOT EXISTS idx_formula_submission_outcome ON formula_submission (outcome, submitted_at);
@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS game_move (
+    game_id INT NOT NULL REFERENCES game(id) ON DELETE CASCADE,
+    seq INT NOT NULL,
+    user_id INT NOT NULL REFERENCES user(id) ON DELETE CASCADE,
+    expression VARCHAR(255) NOT NULL,
+    submitted_version INT NOT NULL,
+    version INT NOT NULL,
+    changes TEXT NOT NULL,
+    gained_score INT NOT NULL,
+    submitted_at DATETIME NOT NULL,
+    PRIMARY KEY (game_id, seq)
+);
@@ -0,0 +1,20 @@
+CREATE TABLE IF NOT EXISTS user_rating (
+    user_id INT PRIMARY KEY REFERENCES user(id) ON DELETE CASCADE,
+    rating DOUBLE NOT NULL,
+    deviation DOUBLE NOT NULL,
+    games_played INT NOT NULL DEFAULT 0,
+    updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS idx_user_rating_rating ON user_rating (rating);
+
+CREATE TABLE IF NOT EXISTS rating_history (
+    game_id INT NOT NULL REFERENCES game(id) ON DELETE CASCADE,
+    user_id INT NOT NULL REFERENCES user(id) ON DELETE CASCADE,
+    rating_before DOUBLE NOT NULL,
+    deviation_before DOUBLE NOT NULL,
+    rating_after DOUBLE NOT NULL,
+    deviation_after DOUBLE NOT NULL,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (game_id, user_id)
+);
+CREATE INDEX IF NOT EXISTS idx_rating_history_user_id ON rating_history (user_id, created_at);
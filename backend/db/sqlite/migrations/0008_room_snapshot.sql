@@ -0,0 +1,7 @@
+CREATE TABLE IF NOT EXISTS room_snapshot (
+    instance_id VARCHAR(64) NOT NULL,
+    room_id INT NOT NULL,
+    state TEXT NOT NULL,
+    saved_at DATETIME NOT NULL,
+    PRIMARY KEY (instance_id, room_id)
+);
@@ -0,0 +1,3 @@
+ALTER TABLE user ADD COLUMN is_guest BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE user ADD COLUMN guest_expires_at DATETIME NULL;
+CREATE INDEX IF NOT EXISTS idx_user_guest_expires_at ON user (is_guest, guest_expires_at);
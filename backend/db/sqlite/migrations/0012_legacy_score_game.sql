@@ -0,0 +1,13 @@
+-- 盤面のJSONはアプリケーションと同じくBLOBで保存する（TEXTはjson.RawMessageに読み込めない）
+INSERT INTO game (uuid, room_id, mode, seed, started_at, ended_at, initial_board)
+SELECT printf('00000000-0000-0000-0000-%012d', id), 0, 'legacy', 0, '1970-01-01 00:00:00', '1970-01-01 00:00:00', CAST('[]' AS BLOB)
+FROM score WHERE game_id IS NULL;
+
+INSERT INTO game_player (game_id, user_id, score, ranking, cleared_regions)
+SELECT game.id, score.user_id, score._value, 1, 0
+FROM score JOIN game ON game.uuid = printf('00000000-0000-0000-0000-%012d', score.id)
+WHERE score.game_id IS NULL;
+
+UPDATE score SET game_id = (
+    SELECT game.id FROM game WHERE game.uuid = printf('00000000-0000-0000-0000-%012d', score.id)
+) WHERE game_id IS NULL;
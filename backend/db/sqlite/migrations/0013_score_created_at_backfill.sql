@@ -0,0 +1,4 @@
+UPDATE score SET created_at = COALESCE(
+    (SELECT game.ended_at FROM game WHERE game.id = score.game_id),
+    '1970-01-01 00:00:00'
+);
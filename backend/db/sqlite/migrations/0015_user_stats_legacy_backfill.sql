@@ -0,0 +1,11 @@
+INSERT INTO user_stats (user_id, games_played, best_score, total_score)
+SELECT game_player.user_id, COUNT(*), MAX(game_player.score), SUM(game_player.score)
+FROM game_player
+JOIN game ON game.id = game_player.game_id
+JOIN user ON user.id = game_player.user_id
+WHERE game.mode = 'legacy'
+GROUP BY game_player.user_id
+ON CONFLICT (user_id) DO UPDATE SET games_played = user_stats.games_played + excluded.games_played,
+    best_score = MAX(user_stats.best_score, excluded.best_score),
+    total_score = user_stats.total_score + excluded.total_score,
+    updated_at = CURRENT_TIMESTAMP;
@@ -0,0 +1,25 @@
+-- SQLite版で書き方が異なるクエリ（それ以外はdb/queries.sqlをそのまま使う）
+-- FOR UPDATEはなく、書き込みトランザクションはデータベース全体をロックする
+-- ON DUPLICATE KEY UPDATEはON CONFLICT ... DO UPDATE、ON UPDATE CURRENT_TIMESTAMPはupdated_atの明示的な更新
+
+-- name: GetUserStatsForUpdate :one
+SELECT * FROM user_stats WHERE user_id = ?;
+
+-- name: UpsertUserStats :exec
+INSERT INTO user_stats (user_id,games_played,wins,best_score,total_score,current_streak,longest_streak,accepted_formulas,rejected_formulas,longest_answer_streak)
+VALUES(?,?,?,?,?,?,?,?,?,?)
+ON CONFLICT (user_id) DO UPDATE SET games_played = excluded.games_played, wins = excluded.wins, best_score = excluded.best_score,
+    total_score = excluded.total_score, current_streak = excluded.current_streak, longest_streak = excluded.longest_streak,
+    accepted_formulas = excluded.accepted_formulas, rejected_formulas = excluded.rejected_formulas,
+    longest_answer_streak = excluded.longest_answer_streak, updated_at = CURRENT_TIMESTAMP;
+
+-- name: GetUserRatingForUpdate :one
+SELECT * FROM user_rating WHERE user_id = ?;
+
+-- name: UpsertUserRating :exec
+INSERT INTO user_rating (user_id,rating,deviation,games_played) VALUES(?,?,?,?)
+ON CONFLICT (user_id) DO UPDATE SET rating = excluded.rating, deviation = excluded.deviation, games_played = excluded.games_played, updated_at = CURRENT_TIMESTAMP;
+
+-- name: UpsertRoomSnapshot :exec
+INSERT INTO room_snapshot (instance_id,room_id,state,saved_at) VALUES(?,?,?,?)
+ON CONFLICT (instance_id, room_id) DO UPDATE SET state = excluded.state, saved_at = excluded.saved_at;
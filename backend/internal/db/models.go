@@ -6,16 +6,128 @@ package db
 
 import (
 	"database/sql"
+	"encoding/json"
+	"time"
 )
 
+type FormulaSubmission struct {
+	ID               int64           `json:"id"`
+	GameUuid         string          `json:"game_uuid"`
+	RoomID           int32           `json:"room_id"`
+	UserID           int32           `json:"user_id"`
+	SubmittedVersion int32           `json:"submitted_version"`
+	BoardVersion     int32           `json:"board_version"`
+	Expression       string          `json:"expression"`
+	Outcome          string          `json:"outcome"`
+	MatchedRegions   json.RawMessage `json:"matched_regions"`
+	GainedScore      int32           `json:"gained_score"`
+	LatencyUs        int32           `json:"latency_us"`
+	SubmittedAt      time.Time       `json:"submitted_at"`
+}
+
+type Game struct {
+	ID           int32           `json:"id"`
+	Uuid         string          `json:"uuid"`
+	RoomID       int32           `json:"room_id"`
+	Mode         string          `json:"mode"`
+	Seed         int64           `json:"seed"`
+	StartedAt    time.Time       `json:"started_at"`
+	EndedAt      time.Time       `json:"ended_at"`
+	InitialBoard json.RawMessage `json:"initial_board"`
+}
+
+type GameMove struct {
+	GameID           int32           `json:"game_id"`
+	Seq              int32           `json:"seq"`
+	UserID           int32           `json:"user_id"`
+	Expression       string          `json:"expression"`
+	SubmittedVersion int32           `json:"submitted_version"`
+	Version          int32           `json:"version"`
+	Changes          json.RawMessage `json:"changes"`
+	GainedScore      int32           `json:"gained_score"`
+	SubmittedAt      time.Time       `json:"submitted_at"`
+}
+
+type GamePlayer struct {
+	GameID           int32 `json:"game_id"`
+	UserID           int32 `json:"user_id"`
+	Score            int32 `json:"score"`
+	Ranking          int32 `json:"ranking"`
+	ClearedRegions   int32 `json:"cleared_regions"`
+	AcceptedFormulas int32 `json:"accepted_formulas"`
+	RejectedFormulas int32 `json:"rejected_formulas"`
+}
+
+type RatingHistory struct {
+	GameID          int32     `json:"game_id"`
+	UserID          int32     `json:"user_id"`
+	RatingBefore    float64   `json:"rating_before"`
+	DeviationBefore float64   `json:"deviation_before"`
+	RatingAfter     float64   `json:"rating_after"`
+	DeviationAfter  float64   `json:"deviation_after"`
+	CreatedAt       time.Time `json:"created_at"`
+}
+
+type RefreshToken struct {
+	ID        int64        `json:"id"`
+	UserID    int32        `json:"user_id"`
+	FamilyID  string       `json:"family_id"`
+	TokenHash string       `json:"token_hash"`
+	ExpiresAt time.Time    `json:"expires_at"`
+	CreatedAt time.Time    `json:"created_at"`
+	UsedAt    sql.NullTime `json:"used_at"`
+	RevokedAt sql.NullTime `json:"revoked_at"`
+}
+
+type RoomSnapshot struct {
+	InstanceID string          `json:"instance_id"`
+	RoomID     int32           `json:"room_id"`
+	State      json.RawMessage `json:"state"`
+	SavedAt    time.Time       `json:"saved_at"`
+}
+
 type Score struct {
-	ID     int32 `json:"id"`
-	UserID int32 `json:"user_id"`
-	Value  int32 `json:"_value"`
+	ID        int32         `json:"id"`
+	UserID    int32         `json:"user_id"`
+	Value     int32         `json:"_value"`
+	GameID    sql.NullInt32 `json:"game_id"`
+	CreatedAt time.Time     `json:"created_at"`
 }
 
 type User struct {
-	ID           int32          `json:"id"`
-	Username     string         `json:"username"`
-	PasswordHash sql.NullString `json:"password_hash"`
+	ID             int32          `json:"id"`
+	Username       string         `json:"username"`
+	PasswordHash   sql.NullString `json:"password_hash"`
+	IsGuest        bool           `json:"is_guest"`
+	GuestExpiresAt sql.NullTime   `json:"guest_expires_at"`
+}
+
+type UserIdentity struct {
+	ID        int32     `json:"id"`
+	UserID    int32     `json:"user_id"`
+	Provider  string    `json:"provider"`
+	Subject   string    `json:"subject"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type UserRating struct {
+	UserID      int32     `json:"user_id"`
+	Rating      float64   `json:"rating"`
+	Deviation   float64   `json:"deviation"`
+	GamesPlayed int32     `json:"games_played"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+type UserStat struct {
+	UserID              int32     `json:"user_id"`
+	GamesPlayed         int32     `json:"games_played"`
+	Wins                int32     `json:"wins"`
+	BestScore           int32     `json:"best_score"`
+	TotalScore          int64     `json:"total_score"`
+	CurrentStreak       int32     `json:"current_streak"`
+	LongestStreak       int32     `json:"longest_streak"`
+	AcceptedFormulas    int32     `json:"accepted_formulas"`
+	RejectedFormulas    int32     `json:"rejected_formulas"`
+	UpdatedAt           time.Time `json:"updated_at"`
+	LongestAnswerStreak int32     `json:"longest_answer_streak"`
 }
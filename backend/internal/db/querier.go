@@ -7,19 +7,56 @@ package db
 import (
 	"context"
 	"database/sql"
+	"time"
 )
 
 type Querier interface {
+	AnonymizeExpiredGuestUsers(ctx context.Context, guestExpiresAt sql.NullTime) (sql.Result, error)
+	CountBestScoresAbove(ctx context.Context, arg CountBestScoresAboveParams) (int64, error)
+	CountRevokedRefreshTokens(ctx context.Context, familyID string) (int64, error)
+	CountScoresAbove(ctx context.Context, arg CountScoresAboveParams) (int64, error)
+	CountUserIdentities(ctx context.Context, userID int32) (int64, error)
+	CreateFormulaSubmission(ctx context.Context, arg CreateFormulaSubmissionParams) error
+	CreateGame(ctx context.Context, arg CreateGameParams) (sql.Result, error)
+	CreateGameMove(ctx context.Context, arg CreateGameMoveParams) error
+	CreateGamePlayer(ctx context.Context, arg CreateGamePlayerParams) error
+	CreateGameScore(ctx context.Context, arg CreateGameScoreParams) (sql.Result, error)
+	CreateGuestUser(ctx context.Context, arg CreateGuestUserParams) (sql.Result, error)
+	CreateRatingHistory(ctx context.Context, arg CreateRatingHistoryParams) error
+	CreateRefreshToken(ctx context.Context, arg CreateRefreshTokenParams) error
 	CreateScore(ctx context.Context, arg CreateScoreParams) (sql.Result, error)
 	CreateUser(ctx context.Context, username string) (sql.Result, error)
+	CreateUserIdentity(ctx context.Context, arg CreateUserIdentityParams) error
 	CreateUserWithPassword(ctx context.Context, arg CreateUserWithPasswordParams) (sql.Result, error)
+	DeleteExpiredGuestUsers(ctx context.Context, guestExpiresAt sql.NullTime) (sql.Result, error)
+	DeleteExpiredRefreshTokens(ctx context.Context, expiresAt time.Time) error
 	DeleteUser(ctx context.Context, id int32) error
+	GetGame(ctx context.Context, id int32) (Game, error)
+	GetRefreshTokenByHash(ctx context.Context, tokenHash string) (RefreshToken, error)
 	GetTop10Scores(ctx context.Context) ([]GetTop10ScoresRow, error)
 	GetUser(ctx context.Context, id int32) (User, error)
 	GetUserByUsername(ctx context.Context, username string) (User, error)
+	GetUserBestScore(ctx context.Context, arg GetUserBestScoreParams) (sql.NullInt32, error)
 	GetUserIDByUsername(ctx context.Context, username string) (int32, error)
+	GetUserIdentity(ctx context.Context, arg GetUserIdentityParams) (UserIdentity, error)
+	GetUserRatingForUpdate(ctx context.Context, userID int32) (UserRating, error)
+	GetUserStats(ctx context.Context, userID int32) (UserStat, error)
+	GetUserStatsForUpdate(ctx context.Context, userID int32) (UserStat, error)
+	ListGameMoves(ctx context.Context, gameID int32) ([]GameMove, error)
+	ListGamePlayers(ctx context.Context, gameID int32) ([]ListGamePlayersRow, error)
+	ListLeaderboardBestScores(ctx context.Context, arg ListLeaderboardBestScoresParams) ([]ListLeaderboardBestScoresRow, error)
+	ListLeaderboardScores(ctx context.Context, arg ListLeaderboardScoresParams) ([]ListLeaderboardScoresRow, error)
+	ListRatings(ctx context.Context, arg ListRatingsParams) ([]ListRatingsRow, error)
+	ListRoomSnapshots(ctx context.Context, instanceID string) ([]RoomSnapshot, error)
 	ListUsers(ctx context.Context) ([]User, error)
+	MarkRefreshTokenUsed(ctx context.Context, arg MarkRefreshTokenUsedParams) (sql.Result, error)
+	RevokeAnonymizedGuestRefreshTokens(ctx context.Context, revokedAt sql.NullTime) error
+	RevokeRefreshTokenFamily(ctx context.Context, arg RevokeRefreshTokenFamilyParams) error
 	UpdateUser(ctx context.Context, arg UpdateUserParams) error
+	UpgradeGuestUser(ctx context.Context, arg UpgradeGuestUserParams) (sql.Result, error)
+	UpsertRoomSnapshot(ctx context.Context, arg UpsertRoomSnapshotParams) error
+	UpsertUserRating(ctx context.Context, arg UpsertUserRatingParams) error
+	UpsertUserStats(ctx context.Context, arg UpsertUserStatsParams) error
 }
 
 var _ Querier = (*Queries)(nil)
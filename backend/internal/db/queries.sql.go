@@ -8,8 +8,268 @@ package db
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
+	"time"
 )
 
+const AnonymizeExpiredGuestUsers = `-- name: AnonymizeExpiredGuestUsers :execresult
+UPDATE user SET username = CONCAT('guest-deleted-', id), guest_expires_at = NULL
+WHERE is_guest = TRUE AND guest_expires_at < ? AND EXISTS (SELECT 1 FROM game_player WHERE game_player.user_id = user.id)
+`
+
+func (q *Queries) AnonymizeExpiredGuestUsers(ctx context.Context, guestExpiresAt sql.NullTime) (sql.Result, error) {
+	return q.db.ExecContext(ctx, AnonymizeExpiredGuestUsers, guestExpiresAt)
+}
+
+const CountBestScoresAbove = `-- name: CountBestScoresAbove :one
+SELECT COUNT(*) FROM (SELECT MAX(_value) AS best_value FROM score WHERE created_at >= ? GROUP BY user_id) AS best WHERE best.best_value > ?
+`
+
+type CountBestScoresAboveParams struct {
+	CreatedAt time.Time   `json:"created_at"`
+	BestValue interface{} `json:"best_value"`
+}
+
+func (q *Queries) CountBestScoresAbove(ctx context.Context, arg CountBestScoresAboveParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, CountBestScoresAbove, arg.CreatedAt, arg.BestValue)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const CountRevokedRefreshTokens = `-- name: CountRevokedRefreshTokens :one
+SELECT COUNT(*) FROM refresh_token WHERE family_id = ? AND revoked_at IS NOT NULL
+`
+
+func (q *Queries) CountRevokedRefreshTokens(ctx context.Context, familyID string) (int64, error) {
+	row := q.db.QueryRowContext(ctx, CountRevokedRefreshTokens, familyID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const CountScoresAbove = `-- name: CountScoresAbove :one
+SELECT COUNT(*) FROM score WHERE created_at >= ? AND _value > ?
+`
+
+type CountScoresAboveParams struct {
+	CreatedAt time.Time `json:"created_at"`
+	Value     int32     `json:"_value"`
+}
+
+func (q *Queries) CountScoresAbove(ctx context.Context, arg CountScoresAboveParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, CountScoresAbove, arg.CreatedAt, arg.Value)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const CountUserIdentities = `-- name: CountUserIdentities :one
+SELECT COUNT(*) FROM user_identity WHERE user_id = ?
+`
+
+func (q *Queries) CountUserIdentities(ctx context.Context, userID int32) (int64, error) {
+	row := q.db.QueryRowContext(ctx, CountUserIdentities, userID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const CreateFormulaSubmission = `-- name: CreateFormulaSubmission :exec
+INSERT INTO formula_submission (game_uuid,room_id,user_id,submitted_version,board_version,expression,outcome,matched_regions,gained_score,latency_us,submitted_at)
+VALUES(?,?,?,?,?,?,?,?,?,?,?)
+`
+
+type CreateFormulaSubmissionParams struct {
+	GameUuid         string          `json:"game_uuid"`
+	RoomID           int32           `json:"room_id"`
+	UserID           int32           `json:"user_id"`
+	SubmittedVersion int32           `json:"submitted_version"`
+	BoardVersion     int32           `json:"board_version"`
+	Expression       string          `json:"expression"`
+	Outcome          string          `json:"outcome"`
+	MatchedRegions   json.RawMessage `json:"matched_regions"`
+	GainedScore      int32           `json:"gained_score"`
+	LatencyUs        int32           `json:"latency_us"`
+	SubmittedAt      time.Time       `json:"submitted_at"`
+}
+
+func (q *Queries) CreateFormulaSubmission(ctx context.Context, arg CreateFormulaSubmissionParams) error {
+	_, err := q.db.ExecContext(ctx, CreateFormulaSubmission,
+		arg.GameUuid,
+		arg.RoomID,
+		arg.UserID,
+		arg.SubmittedVersion,
+		arg.BoardVersion,
+		arg.Expression,
+		arg.Outcome,
+		arg.MatchedRegions,
+		arg.GainedScore,
+		arg.LatencyUs,
+		arg.SubmittedAt,
+	)
+	return err
+}
+
+const CreateGame = `-- name: CreateGame :execresult
+INSERT INTO game (uuid,room_id,mode,seed,started_at,ended_at,initial_board) VALUES(?,?,?,?,?,?,?)
+`
+
+type CreateGameParams struct {
+	Uuid         string          `json:"uuid"`
+	RoomID       int32           `json:"room_id"`
+	Mode         string          `json:"mode"`
+	Seed         int64           `json:"seed"`
+	StartedAt    time.Time       `json:"started_at"`
+	EndedAt      time.Time       `json:"ended_at"`
+	InitialBoard json.RawMessage `json:"initial_board"`
+}
+
+func (q *Queries) CreateGame(ctx context.Context, arg CreateGameParams) (sql.Result, error) {
+	return q.db.ExecContext(ctx, CreateGame,
+		arg.Uuid,
+		arg.RoomID,
+		arg.Mode,
+		arg.Seed,
+		arg.StartedAt,
+		arg.EndedAt,
+		arg.InitialBoard,
+	)
+}
+
+const CreateGameMove = `-- name: CreateGameMove :exec
+INSERT INTO game_move (game_id,seq,user_id,expression,submitted_version,version,changes,gained_score,submitted_at) VALUES(?,?,?,?,?,?,?,?,?)
+`
+
+type CreateGameMoveParams struct {
+	GameID           int32           `json:"game_id"`
+	Seq              int32           `json:"seq"`
+	UserID           int32           `json:"user_id"`
+	Expression       string          `json:"expression"`
+	SubmittedVersion int32           `json:"submitted_version"`
+	Version          int32           `json:"version"`
+	Changes          json.RawMessage `json:"changes"`
+	GainedScore      int32           `json:"gained_score"`
+	SubmittedAt      time.Time       `json:"submitted_at"`
+}
+
+func (q *Queries) CreateGameMove(ctx context.Context, arg CreateGameMoveParams) error {
+	_, err := q.db.ExecContext(ctx, CreateGameMove,
+		arg.GameID,
+		arg.Seq,
+		arg.UserID,
+		arg.Expression,
+		arg.SubmittedVersion,
+		arg.Version,
+		arg.Changes,
+		arg.GainedScore,
+		arg.SubmittedAt,
+	)
+	return err
+}
+
+const CreateGamePlayer = `-- name: CreateGamePlayer :exec
+INSERT INTO game_player (game_id,user_id,score,ranking,cleared_regions,accepted_formulas,rejected_formulas) VALUES(?,?,?,?,?,?,?)
+`
+
+type CreateGamePlayerParams struct {
+	GameID           int32 `json:"game_id"`
+	UserID           int32 `json:"user_id"`
+	Score            int32 `json:"score"`
+	Ranking          int32 `json:"ranking"`
+	ClearedRegions   int32 `json:"cleared_regions"`
+	AcceptedFormulas int32 `json:"accepted_formulas"`
+	RejectedFormulas int32 `json:"rejected_formulas"`
+}
+
+func (q *Queries) CreateGamePlayer(ctx context.Context, arg CreateGamePlayerParams) error {
+	_, err := q.db.ExecContext(ctx, CreateGamePlayer,
+		arg.GameID,
+		arg.UserID,
+		arg.Score,
+		arg.Ranking,
+		arg.ClearedRegions,
+		arg.AcceptedFormulas,
+		arg.RejectedFormulas,
+	)
+	return err
+}
+
+const CreateGameScore = `-- name: CreateGameScore :execresult
+INSERT INTO score (user_id,_value,game_id) VALUES(?,?,?)
+`
+
+type CreateGameScoreParams struct {
+	UserID int32         `json:"user_id"`
+	Value  int32         `json:"_value"`
+	GameID sql.NullInt32 `json:"game_id"`
+}
+
+func (q *Queries) CreateGameScore(ctx context.Context, arg CreateGameScoreParams) (sql.Result, error) {
+	return q.db.ExecContext(ctx, CreateGameScore, arg.UserID, arg.Value, arg.GameID)
+}
+
+const CreateRatingHistory = `-- name: CreateRatingHistory :exec
+INSERT INTO rating_history (game_id,user_id,rating_before,deviation_before,rating_after,deviation_after) VALUES(?,?,?,?,?,?)
+`
+
+type CreateRatingHistoryParams struct {
+	GameID          int32   `json:"game_id"`
+	UserID          int32   `json:"user_id"`
+	RatingBefore    float64 `json:"rating_before"`
+	DeviationBefore float64 `json:"deviation_before"`
+	RatingAfter     float64 `json:"rating_after"`
+	DeviationAfter  float64 `json:"deviation_after"`
+}
+
+func (q *Queries) CreateRatingHistory(ctx context.Context, arg CreateRatingHistoryParams) error {
+	_, err := q.db.ExecContext(ctx, CreateRatingHistory,
+		arg.GameID,
+		arg.UserID,
+		arg.RatingBefore,
+		arg.DeviationBefore,
+		arg.RatingAfter,
+		arg.DeviationAfter,
+	)
+	return err
+}
+
+const CreateGuestUser = `-- name: CreateGuestUser :execresult
+INSERT INTO user (username,is_guest,guest_expires_at) VALUES(?,TRUE,?)
+`
+
+type CreateGuestUserParams struct {
+	Username       string       `json:"username"`
+	GuestExpiresAt sql.NullTime `json:"guest_expires_at"`
+}
+
+func (q *Queries) CreateGuestUser(ctx context.Context, arg CreateGuestUserParams) (sql.Result, error) {
+	return q.db.ExecContext(ctx, CreateGuestUser, arg.Username, arg.GuestExpiresAt)
+}
+
+const CreateRefreshToken = `-- name: CreateRefreshToken :exec
+INSERT INTO refresh_token (user_id,family_id,token_hash,expires_at,created_at) VALUES(?,?,?,?,?)
+`
+
+type CreateRefreshTokenParams struct {
+	UserID    int32     `json:"user_id"`
+	FamilyID  string    `json:"family_id"`
+	TokenHash string    `json:"token_hash"`
+	ExpiresAt time.Time `json:"expires_at"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func (q *Queries) CreateRefreshToken(ctx context.Context, arg CreateRefreshTokenParams) error {
+	_, err := q.db.ExecContext(ctx, CreateRefreshToken,
+		arg.UserID,
+		arg.FamilyID,
+		arg.TokenHash,
+		arg.ExpiresAt,
+		arg.CreatedAt,
+	)
+	return err
+}
+
 const CreateScore = `-- name: CreateScore :execresult
 INSERT INTO score (user_id,_value) VALUES(?,?)
 `
@@ -31,6 +291,27 @@ func (q *Queries) CreateUser(ctx context.Context, username string) (sql.Result,
 	return q.db.ExecContext(ctx, CreateUser, username)
 }
 
+const CreateUserIdentity = `-- name: CreateUserIdentity :exec
+INSERT INTO user_identity (user_id,provider,subject,created_at) VALUES(?,?,?,?)
+`
+
+type CreateUserIdentityParams struct {
+	UserID    int32     `json:"user_id"`
+	Provider  string    `json:"provider"`
+	Subject   string    `json:"subject"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func (q *Queries) CreateUserIdentity(ctx context.Context, arg CreateUserIdentityParams) error {
+	_, err := q.db.ExecContext(ctx, CreateUserIdentity,
+		arg.UserID,
+		arg.Provider,
+		arg.Subject,
+		arg.CreatedAt,
+	)
+	return err
+}
+
 const CreateUserWithPassword = `-- name: CreateUserWithPassword :execresult
 INSERT INTO user (username,password_hash) VALUES(?,?)
 `
@@ -44,6 +325,23 @@ func (q *Queries) CreateUserWithPassword(ctx context.Context, arg CreateUserWith
 	return q.db.ExecContext(ctx, CreateUserWithPassword, arg.Username, arg.PasswordHash)
 }
 
+const DeleteExpiredGuestUsers = `-- name: DeleteExpiredGuestUsers :execresult
+DELETE FROM user WHERE is_guest = TRUE AND guest_expires_at < ?
+`
+
+func (q *Queries) DeleteExpiredGuestUsers(ctx context.Context, guestExpiresAt sql.NullTime) (sql.Result, error) {
+	return q.db.ExecContext(ctx, DeleteExpiredGuestUsers, guestExpiresAt)
+}
+
+const DeleteExpiredRefreshTokens = `-- name: DeleteExpiredRefreshTokens :exec
+DELETE FROM refresh_token WHERE expires_at < ?
+`
+
+func (q *Queries) DeleteExpiredRefreshTokens(ctx context.Context, expiresAt time.Time) error {
+	_, err := q.db.ExecContext(ctx, DeleteExpiredRefreshTokens, expiresAt)
+	return err
+}
+
 const DeleteUser = `-- name: DeleteUser :exec
 DELETE FROM user WHERE id = ?
 `
@@ -53,8 +351,48 @@ func (q *Queries) DeleteUser(ctx context.Context, id int32) error {
 	return err
 }
 
+const GetGame = `-- name: GetGame :one
+SELECT id, uuid, room_id, mode, seed, started_at, ended_at, initial_board FROM game WHERE id = ?
+`
+
+func (q *Queries) GetGame(ctx context.Context, id int32) (Game, error) {
+	row := q.db.QueryRowContext(ctx, GetGame, id)
+	var i Game
+	err := row.Scan(
+		&i.ID,
+		&i.Uuid,
+		&i.RoomID,
+		&i.Mode,
+		&i.Seed,
+		&i.StartedAt,
+		&i.EndedAt,
+		&i.InitialBoard,
+	)
+	return i, err
+}
+
+const GetRefreshTokenByHash = `-- name: GetRefreshTokenByHash :one
+SELECT id, user_id, family_id, token_hash, expires_at, created_at, used_at, revoked_at FROM refresh_token WHERE token_hash = ?
+`
+
+func (q *Queries) GetRefreshTokenByHash(ctx context.Context, tokenHash string) (RefreshToken, error) {
+	row := q.db.QueryRowContext(ctx, GetRefreshTokenByHash, tokenHash)
+	var i RefreshToken
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.FamilyID,
+		&i.TokenHash,
+		&i.ExpiresAt,
+		&i.CreatedAt,
+		&i.UsedAt,
+		&i.RevokedAt,
+	)
+	return i, err
+}
+
 const GetTop10Scores = `-- name: GetTop10Scores :many
-SELECT user.username,score._value FROM score JOIN user ON score.user_id = user.id ORDER BY value DESC limit 10
+SELECT user.username,score._value FROM score JOIN user ON score.user_id = user.id ORDER BY score._value DESC limit 10
 `
 
 type GetTop10ScoresRow struct {
@@ -86,27 +424,55 @@ func (q *Queries) GetTop10Scores(ctx context.Context) ([]GetTop10ScoresRow, erro
 }
 
 const GetUser = `-- name: GetUser :one
-SELECT id, username, password_hash FROM user WHERE id = ?
+SELECT id, username, password_hash, is_guest, guest_expires_at FROM user WHERE id = ?
 `
 
 func (q *Queries) GetUser(ctx context.Context, id int32) (User, error) {
 	row := q.db.QueryRowContext(ctx, GetUser, id)
 	var i User
-	err := row.Scan(&i.ID, &i.Username, &i.PasswordHash)
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.PasswordHash,
+		&i.IsGuest,
+		&i.GuestExpiresAt,
+	)
 	return i, err
 }
 
 const GetUserByUsername = `-- name: GetUserByUsername :one
-SELECT id, username, password_hash FROM user WHERE username = ?
+SELECT id, username, password_hash, is_guest, guest_expires_at FROM user WHERE username = ?
 `
 
 func (q *Queries) GetUserByUsername(ctx context.Context, username string) (User, error) {
 	row := q.db.QueryRowContext(ctx, GetUserByUsername, username)
 	var i User
-	err := row.Scan(&i.ID, &i.Username, &i.PasswordHash)
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.PasswordHash,
+		&i.IsGuest,
+		&i.GuestExpiresAt,
+	)
 	return i, err
 }
 
+const GetUserBestScore = `-- name: GetUserBestScore :one
+SELECT MAX(_value) AS best_value FROM score WHERE user_id = ? AND created_at >= ?
+`
+
+type GetUserBestScoreParams struct {
+	UserID    int32     `json:"user_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func (q *Queries) GetUserBestScore(ctx context.Context, arg GetUserBestScoreParams) (sql.NullInt32, error) {
+	row := q.db.QueryRowContext(ctx, GetUserBestScore, arg.UserID, arg.CreatedAt)
+	var best_value sql.NullInt32
+	err := row.Scan(&best_value)
+	return best_value, err
+}
+
 const GetUserIDByUsername = `-- name: GetUserIDByUsername :one
 SELECT id FROM user WHERE username = ?
 `
@@ -118,8 +484,364 @@ func (q *Queries) GetUserIDByUsername(ctx context.Context, username string) (int
 	return id, err
 }
 
+const GetUserIdentity = `-- name: GetUserIdentity :one
+SELECT id, user_id, provider, subject, created_at FROM user_identity WHERE provider = ? AND subject = ?
+`
+
+type GetUserIdentityParams struct {
+	Provider string `json:"provider"`
+	Subject  string `json:"subject"`
+}
+
+func (q *Queries) GetUserIdentity(ctx context.Context, arg GetUserIdentityParams) (UserIdentity, error) {
+	row := q.db.QueryRowContext(ctx, GetUserIdentity, arg.Provider, arg.Subject)
+	var i UserIdentity
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Provider,
+		&i.Subject,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const GetUserRatingForUpdate = `-- name: GetUserRatingForUpdate :one
+SELECT user_id, rating, deviation, games_played, updated_at FROM user_rating WHERE user_id = ? FOR UPDATE
+`
+
+func (q *Queries) GetUserRatingForUpdate(ctx context.Context, userID int32) (UserRating, error) {
+	row := q.db.QueryRowContext(ctx, GetUserRatingForUpdate, userID)
+	var i UserRating
+	err := row.Scan(
+		&i.UserID,
+		&i.Rating,
+		&i.Deviation,
+		&i.GamesPlayed,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const GetUserStats = `-- name: GetUserStats :one
+SELECT user_id, games_played, wins, best_score, total_score, current_streak, longest_streak, accepted_formulas, rejected_formulas, updated_at, longest_answer_streak FROM user_stats WHERE user_id = ?
+`
+
+func (q *Queries) GetUserStats(ctx context.Context, userID int32) (UserStat, error) {
+	row := q.db.QueryRowContext(ctx, GetUserStats, userID)
+	var i UserStat
+	err := row.Scan(
+		&i.UserID,
+		&i.GamesPlayed,
+		&i.Wins,
+		&i.BestScore,
+		&i.TotalScore,
+		&i.CurrentStreak,
+		&i.LongestStreak,
+		&i.AcceptedFormulas,
+		&i.RejectedFormulas,
+		&i.UpdatedAt,
+		&i.LongestAnswerStreak,
+	)
+	return i, err
+}
+
+const GetUserStatsForUpdate = `-- name: GetUserStatsForUpdate :one
+SELECT user_id, games_played, wins, best_score, total_score, current_streak, longest_streak, accepted_formulas, rejected_formulas, updated_at, longest_answer_streak FROM user_stats WHERE user_id = ? FOR UPDATE
+`
+
+func (q *Queries) GetUserStatsForUpdate(ctx context.Context, userID int32) (UserStat, error) {
+	row := q.db.QueryRowContext(ctx, GetUserStatsForUpdate, userID)
+	var i UserStat
+	err := row.Scan(
+		&i.UserID,
+		&i.GamesPlayed,
+		&i.Wins,
+		&i.BestScore,
+		&i.TotalScore,
+		&i.CurrentStreak,
+		&i.LongestStreak,
+		&i.AcceptedFormulas,
+		&i.RejectedFormulas,
+		&i.UpdatedAt,
+		&i.LongestAnswerStreak,
+	)
+	return i, err
+}
+
+const ListGameMoves = `-- name: ListGameMoves :many
+SELECT game_id, seq, user_id, expression, submitted_version, version, changes, gained_score, submitted_at FROM game_move WHERE game_id = ? ORDER BY seq
+`
+
+func (q *Queries) ListGameMoves(ctx context.Context, gameID int32) ([]GameMove, error) {
+	rows, err := q.db.QueryContext(ctx, ListGameMoves, gameID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []GameMove{}
+	for rows.Next() {
+		var i GameMove
+		if err := rows.Scan(
+			&i.GameID,
+			&i.Seq,
+			&i.UserID,
+			&i.Expression,
+			&i.SubmittedVersion,
+			&i.Version,
+			&i.Changes,
+			&i.GainedScore,
+			&i.SubmittedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const ListGamePlayers = `-- name: ListGamePlayers :many
+SELECT game_player.user_id, user.username, game_player.score, game_player.ranking, game_player.cleared_regions, game_player.accepted_formulas, game_player.rejected_formulas
+FROM game_player JOIN user ON game_player.user_id = user.id WHERE game_player.game_id = ? ORDER BY game_player.ranking, game_player.user_id
+`
+
+type ListGamePlayersRow struct {
+	UserID           int32  `json:"user_id"`
+	Username         string `json:"username"`
+	Score            int32  `json:"score"`
+	Ranking          int32  `json:"ranking"`
+	ClearedRegions   int32  `json:"cleared_regions"`
+	AcceptedFormulas int32  `json:"accepted_formulas"`
+	RejectedFormulas int32  `json:"rejected_formulas"`
+}
+
+func (q *Queries) ListGamePlayers(ctx context.Context, gameID int32) ([]ListGamePlayersRow, error) {
+	rows, err := q.db.QueryContext(ctx, ListGamePlayers, gameID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListGamePlayersRow{}
+	for rows.Next() {
+		var i ListGamePlayersRow
+		if err := rows.Scan(
+			&i.UserID,
+			&i.Username,
+			&i.Score,
+			&i.Ranking,
+			&i.ClearedRegions,
+			&i.AcceptedFormulas,
+			&i.RejectedFormulas,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const ListLeaderboardBestScores = `-- name: ListLeaderboardBestScores :many
+SELECT score.user_id, user.username, MAX(score._value) AS best_value FROM score JOIN user ON score.user_id = user.id
+WHERE score.created_at >= ?
+GROUP BY score.user_id, user.username
+HAVING MAX(score._value) < ? OR (MAX(score._value) = ? AND score.user_id > ?)
+ORDER BY best_value DESC, score.user_id ASC LIMIT ?
+`
+
+type ListLeaderboardBestScoresParams struct {
+	CreatedAt time.Time   `json:"created_at"`
+	Value     interface{} `json:"_value"`
+	Value_2   interface{} `json:"_value_2"`
+	UserID    int32       `json:"user_id"`
+	Limit     int32       `json:"limit"`
+}
+
+type ListLeaderboardBestScoresRow struct {
+	UserID    int32  `json:"user_id"`
+	Username  string `json:"username"`
+	BestValue int32  `json:"best_value"`
+}
+
+func (q *Queries) ListLeaderboardBestScores(ctx context.Context, arg ListLeaderboardBestScoresParams) ([]ListLeaderboardBestScoresRow, error) {
+	rows, err := q.db.QueryContext(ctx, ListLeaderboardBestScores,
+		arg.CreatedAt,
+		arg.Value,
+		arg.Value_2,
+		arg.UserID,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListLeaderboardBestScoresRow{}
+	for rows.Next() {
+		var i ListLeaderboardBestScoresRow
+		if err := rows.Scan(&i.UserID, &i.Username, &i.BestValue); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const ListLeaderboardScores = `-- name: ListLeaderboardScores :many
+SELECT score.id, score.user_id, user.username, score._value, score.created_at FROM score JOIN user ON score.user_id = user.id
+WHERE score.created_at >= ? AND (score._value < ? OR (score._value = ? AND score.id > ?))
+ORDER BY score._value DESC, score.id ASC LIMIT ?
+`
+
+type ListLeaderboardScoresParams struct {
+	CreatedAt time.Time `json:"created_at"`
+	Value     int32     `json:"_value"`
+	Value_2   int32     `json:"_value_2"`
+	ID        int32     `json:"id"`
+	Limit     int32     `json:"limit"`
+}
+
+type ListLeaderboardScoresRow struct {
+	ID        int32     `json:"id"`
+	UserID    int32     `json:"user_id"`
+	Username  string    `json:"username"`
+	Value     int32     `json:"_value"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func (q *Queries) ListLeaderboardScores(ctx context.Context, arg ListLeaderboardScoresParams) ([]ListLeaderboardScoresRow, error) {
+	rows, err := q.db.QueryContext(ctx, ListLeaderboardScores,
+		arg.CreatedAt,
+		arg.Value,
+		arg.Value_2,
+		arg.ID,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListLeaderboardScoresRow{}
+	for rows.Next() {
+		var i ListLeaderboardScoresRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.Username,
+			&i.Value,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const ListRatings = `-- name: ListRatings :many
+SELECT user_rating.user_id, user.username, user_rating.rating, user_rating.deviation, user_rating.games_played
+FROM user_rating JOIN user ON user_rating.user_id = user.id
+ORDER BY user_rating.rating DESC, user_rating.user_id ASC LIMIT ? OFFSET ?
+`
+
+type ListRatingsParams struct {
+	Limit  int32 `json:"limit"`
+	Offset int32 `json:"offset"`
+}
+
+type ListRatingsRow struct {
+	UserID      int32   `json:"user_id"`
+	Username    string  `json:"username"`
+	Rating      float64 `json:"rating"`
+	Deviation   float64 `json:"deviation"`
+	GamesPlayed int32   `json:"games_played"`
+}
+
+func (q *Queries) ListRatings(ctx context.Context, arg ListRatingsParams) ([]ListRatingsRow, error) {
+	rows, err := q.db.QueryContext(ctx, ListRatings, arg.Limit, arg.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListRatingsRow{}
+	for rows.Next() {
+		var i ListRatingsRow
+		if err := rows.Scan(
+			&i.UserID,
+			&i.Username,
+			&i.Rating,
+			&i.Deviation,
+			&i.GamesPlayed,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const ListRoomSnapshots = `-- name: ListRoomSnapshots :many
+SELECT instance_id, room_id, state, saved_at FROM room_snapshot WHERE instance_id = ? ORDER BY room_id
+`
+
+func (q *Queries) ListRoomSnapshots(ctx context.Context, instanceID string) ([]RoomSnapshot, error) {
+	rows, err := q.db.QueryContext(ctx, ListRoomSnapshots, instanceID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []RoomSnapshot{}
+	for rows.Next() {
+		var i RoomSnapshot
+		if err := rows.Scan(
+			&i.InstanceID,
+			&i.RoomID,
+			&i.State,
+			&i.SavedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const ListUsers = `-- name: ListUsers :many
-SELECT id, username, password_hash FROM user
+SELECT id, username, password_hash, is_guest, guest_expires_at FROM user
 `
 
 func (q *Queries) ListUsers(ctx context.Context) ([]User, error) {
@@ -131,7 +853,13 @@ func (q *Queries) ListUsers(ctx context.Context) ([]User, error) {
 	items := []User{}
 	for rows.Next() {
 		var i User
-		if err := rows.Scan(&i.ID, &i.Username, &i.PasswordHash); err != nil {
+		if err := rows.Scan(
+			&i.ID,
+			&i.Username,
+			&i.PasswordHash,
+			&i.IsGuest,
+			&i.GuestExpiresAt,
+		); err != nil {
 			return nil, err
 		}
 		items = append(items, i)
@@ -145,6 +873,43 @@ func (q *Queries) ListUsers(ctx context.Context) ([]User, error) {
 	return items, nil
 }
 
+const MarkRefreshTokenUsed = `-- name: MarkRefreshTokenUsed :execresult
+UPDATE refresh_token SET used_at = ? WHERE id = ? AND used_at IS NULL AND revoked_at IS NULL
+`
+
+type MarkRefreshTokenUsedParams struct {
+	UsedAt sql.NullTime `json:"used_at"`
+	ID     int64        `json:"id"`
+}
+
+func (q *Queries) MarkRefreshTokenUsed(ctx context.Context, arg MarkRefreshTokenUsedParams) (sql.Result, error) {
+	return q.db.ExecContext(ctx, MarkRefreshTokenUsed, arg.UsedAt, arg.ID)
+}
+
+const RevokeAnonymizedGuestRefreshTokens = `-- name: RevokeAnonymizedGuestRefreshTokens :exec
+UPDATE refresh_token SET revoked_at = ?
+WHERE revoked_at IS NULL AND user_id IN (SELECT id FROM user WHERE is_guest = TRUE AND guest_expires_at IS NULL)
+`
+
+func (q *Queries) RevokeAnonymizedGuestRefreshTokens(ctx context.Context, revokedAt sql.NullTime) error {
+	_, err := q.db.ExecContext(ctx, RevokeAnonymizedGuestRefreshTokens, revokedAt)
+	return err
+}
+
+const RevokeRefreshTokenFamily = `-- name: RevokeRefreshTokenFamily :exec
+UPDATE refresh_token SET revoked_at = ? WHERE family_id = ? AND revoked_at IS NULL
+`
+
+type RevokeRefreshTokenFamilyParams struct {
+	RevokedAt sql.NullTime `json:"revoked_at"`
+	FamilyID  string       `json:"family_id"`
+}
+
+func (q *Queries) RevokeRefreshTokenFamily(ctx context.Context, arg RevokeRefreshTokenFamilyParams) error {
+	_, err := q.db.ExecContext(ctx, RevokeRefreshTokenFamily, arg.RevokedAt, arg.FamilyID)
+	return err
+}
+
 const UpdateUser = `-- name: UpdateUser :exec
 UPDATE user SET username = ? WHERE id = ?
 `
@@ -158,3 +923,99 @@ func (q *Queries) UpdateUser(ctx context.Context, arg UpdateUserParams) error {
 	_, err := q.db.ExecContext(ctx, UpdateUser, arg.Username, arg.ID)
 	return err
 }
+
+const UpgradeGuestUser = `-- name: UpgradeGuestUser :execresult
+UPDATE user SET username = ?, password_hash = ?, is_guest = FALSE, guest_expires_at = NULL WHERE id = ? AND is_guest = TRUE AND guest_expires_at IS NOT NULL
+`
+
+type UpgradeGuestUserParams struct {
+	Username     string         `json:"username"`
+	PasswordHash sql.NullString `json:"password_hash"`
+	ID           int32          `json:"id"`
+}
+
+func (q *Queries) UpgradeGuestUser(ctx context.Context, arg UpgradeGuestUserParams) (sql.Result, error) {
+	return q.db.ExecContext(ctx, UpgradeGuestUser, arg.Username, arg.PasswordHash, arg.ID)
+}
+
+const UpsertRoomSnapshot = `-- name: UpsertRoomSnapshot :exec
+INSERT INTO room_snapshot (instance_id,room_id,state,saved_at) VALUES(?,?,?,?)
+ON DUPLICATE KEY UPDATE state = VALUES(state), saved_at = VALUES(saved_at)
+`
+
+type UpsertRoomSnapshotParams struct {
+	InstanceID string          `json:"instance_id"`
+	RoomID     int32           `json:"room_id"`
+	State      json.RawMessage `json:"state"`
+	SavedAt    time.Time       `json:"saved_at"`
+}
+
+func (q *Queries) UpsertRoomSnapshot(ctx context.Context, arg UpsertRoomSnapshotParams) error {
+	_, err := q.db.ExecContext(ctx, UpsertRoomSnapshot,
+		arg.InstanceID,
+		arg.RoomID,
+		arg.State,
+		arg.SavedAt,
+	)
+	return err
+}
+
+const UpsertUserRating = `-- name: UpsertUserRating :exec
+INSERT INTO user_rating (user_id,rating,deviation,games_played) VALUES(?,?,?,?)
+ON DUPLICATE KEY UPDATE rating = VALUES(rating), deviation = VALUES(deviation), games_played = VALUES(games_played)
+`
+
+type UpsertUserRatingParams struct {
+	UserID      int32   `json:"user_id"`
+	Rating      float64 `json:"rating"`
+	Deviation   float64 `json:"deviation"`
+	GamesPlayed int32   `json:"games_played"`
+}
+
+func (q *Queries) UpsertUserRating(ctx context.Context, arg UpsertUserRatingParams) error {
+	_, err := q.db.ExecContext(ctx, UpsertUserRating,
+		arg.UserID,
+		arg.Rating,
+		arg.Deviation,
+		arg.GamesPlayed,
+	)
+	return err
+}
+
+const UpsertUserStats = `-- name: UpsertUserStats :exec
+INSERT INTO user_stats (user_id,games_played,wins,best_score,total_score,current_streak,longest_streak,accepted_formulas,rejected_formulas,longest_answer_streak)
+VALUES(?,?,?,?,?,?,?,?,?,?)
+ON DUPLICATE KEY UPDATE games_played = VALUES(games_played), wins = VALUES(wins), best_score = VALUES(best_score),
+    total_score = VALUES(total_score), current_streak = VALUES(current_streak), longest_streak = VALUES(longest_streak),
+    accepted_formulas = VALUES(accepted_formulas), rejected_formulas = VALUES(rejected_formulas),
+    longest_answer_streak = VALUES(longest_answer_streak)
+`
+
+type UpsertUserStatsParams struct {
+	UserID              int32 `json:"user_id"`
+	GamesPlayed         int32 `json:"games_played"`
+	Wins                int32 `json:"wins"`
+	BestScore           int32 `json:"best_score"`
+	TotalScore          int64 `json:"total_score"`
+	CurrentStreak       int32 `json:"current_streak"`
+	LongestStreak       int32 `json:"longest_streak"`
+	AcceptedFormulas    int32 `json:"accepted_formulas"`
+	RejectedFormulas    int32 `json:"rejected_formulas"`
+	LongestAnswerStreak int32 `json:"longest_answer_streak"`
+}
+
+func (q *Queries) UpsertUserStats(ctx context.Context, arg UpsertUserStatsParams) error {
+	_, err := q.db.ExecContext(ctx, UpsertUserStats,
+		arg.UserID,
+		arg.GamesPlayed,
+		arg.Wins,
+		arg.BestScore,
+		arg.TotalScore,
+		arg.CurrentStreak,
+		arg.LongestStreak,
+		arg.AcceptedFormulas,
+		arg.RejectedFormulas,
+		arg.LongestAnswerStreak,
+	)
+	return err
+}
@@ -0,0 +1,108 @@
+package domain
+
+import (
+	"context"
+	"sort"
+	"time"
+)
+
+const (
+	// GameModeStandard は通常の対戦モード
+	GameModeStandard = "standard"
+	// GameModeLegacy はゲーム結果の保存以前のスコアをマイグレーションで移したゲーム（リプレイの記録がない）
+	GameModeLegacy = "legacy"
+)
+
+// GameResult は終了したゲームの結果
+type GameResult struct {
+	GameUUID  string
+	RoomID    int
+	Mode      string
+	Seed      int64
+	StartedAt time.Time
+	EndedAt   time.Time
+	Players   []GamePlayerResult // 順位順
+	// リプレイ用の記録
+	InitialBoard [][]int
+	Moves        []GameMove
+}
+
+// GamePlayerResult はゲーム終了時のプレイヤーごとの結果
+type GamePlayerResult struct {
+	UserID              int
+	Username            string
+	Score               int
+	Rank                int // 同点は同順位（1, 1, 3, ...）
+	ClearedRegions      int
+	AcceptedFormulas    int
+	RejectedFormulas    int
+	LongestAnswerStreak int  // このゲームでの最長連続正解数
+	Left                bool // ゲームの途中で退出した（スコアに関わらず残ったプレイヤーより下の順位）
+}
+
+// GameResultRepository persists finished game results
+type GameResultRepository interface {
+	// SaveGameResult はゲームと各プレイヤーの結果を1つのトランザクションで保存し、ゲームIDを返す
+	SaveGameResult(ctx context.Context, result GameResult) (int64, error)
+	// GetGameReplay はゲームのリプレイ用の記録を返す。存在しない場合はErrGameNotFound、記録がない場合はErrReplayNotRecorded
+	GetGameReplay(ctx context.Context, gameID int64) (*GameReplay, error)
+}
+
+// Result returns the final result of the game with players ranked by score.
+// 途中で退出したプレイヤーは残ったプレイヤーより下の順位とする
+func (r *Room) Result(endedAt time.Time) GameResult {
+	players := make([]GamePlayerResult, 0, len(r.Players)+len(r.Leavers))
+	seen := make(map[int]bool, len(r.Players)+len(r.Leavers))
+	for _, p := range r.Players {
+		players = append(players, newGamePlayerResult(p, false))
+		seen[p.ID] = true
+	}
+	for _, p := range r.Leavers {
+		// 退出後に同じゲームへ戻ったプレイヤーは残ったプレイヤーとして数え、退出を繰り返しても1人として数える
+		if seen[p.ID] {
+			continue
+		}
+		players = append(players, newGamePlayerResult(p, true))
+		seen[p.ID] = true
+	}
+
+	sort.SliceStable(players, func(i, j int) bool {
+		if players[i].Left != players[j].Left {
+			return !players[i].Left
+		}
+		return players[i].Score > players[j].Score
+	})
+	for i := range players {
+		if i > 0 && players[i].Score == players[i-1].Score && players[i].Left == players[i-1].Left {
+			players[i].Rank = players[i-1].Rank
+		} else {
+			players[i].Rank = i + 1
+		}
+	}
+
+	return GameResult{
+		GameUUID:  r.GameUUID,
+		RoomID:    r.ID,
+		Mode:      GameModeStandard,
+		Seed:      r.Seed,
+		StartedAt: r.GameStartedAt,
+		EndedAt:   endedAt,
+		Players:   players,
+		// ResetRoomで失われないよう記録をコピーする
+		InitialBoard: r.InitialBoard,
+		Moves:        append([]GameMove(nil), r.Moves...),
+	}
+}
+
+func newGamePlayerResult(p Player, left bool) GamePlayerResult {
+	return GamePlayerResult{
+		UserID:              p.ID,
+		Username:            p.UserName,
+		Score:               p.Score,
+		ClearedRegions:      p.ClearedRegions,
+		AcceptedFormulas:    p.AcceptedFormulas,
+		RejectedFormulas:    p.RejectedFormulas,
+		LongestAnswerStreak: p.LongestAnswerStreak,
+		Left:                left,
+	}
+}
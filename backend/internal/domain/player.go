@@ -3,11 +3,15 @@ package domain
 import "time"
 
 type Player struct {
-	ID              int
-	UserName        string
-	IsReady         bool
-	HasClosedResult bool // 結果表示を閉じたかどうか
-	Score           int
-	IsConnected     bool       // WebSocket接続状態
-	LastSeenAt      *time.Time // 最後に確認された時刻（切断時に設定）
+	ID                  int
+	UserName            string
+	IsReady             bool
+	HasClosedResult     bool // 結果表示を閉じたかどうか
+	Score               int
+	ClearedRegions      int        // このゲームで消した組数
+	AcceptedFormulas    int        // このゲームで受理された数式の数
+	RejectedFormulas    int        // このゲームで拒否された数式の数
+	LongestAnswerStreak int        // このゲームでの最長連続正解数
+	IsConnected         bool       // WebSocket接続状態
+	LastSeenAt          *time.Time // 最後に確認された時刻（切断時に設定）
 }
@@ -0,0 +1,100 @@
+package domain
+
+import "math"
+
+// Glicko方式のレーティングのパラメータ
+const (
+	InitialRating    = 1500.0
+	InitialDeviation = 350.0 // 未対戦のユーザーの不確かさ（最大値）
+	MinDeviation     = 30.0
+	DeviationGrowth  = 35.0 // 1ゲームごとに不確かさへ加える量（対戦間の実力の変化を見込む）
+)
+
+// glickoQ はGlickoの定数 ln(10)/400
+var glickoQ = math.Ln10 / 400
+
+// Rating はユーザーのレーティングと、その不確かさ（rating deviation）
+type Rating struct {
+	UserID      int
+	Rating      float64
+	Deviation   float64
+	GamesPlayed int
+}
+
+// NewRating returns the rating of a user who has not played a rated game yet
+func NewRating(userID int) Rating {
+	return Rating{
+		UserID:    userID,
+		Rating:    InitialRating,
+		Deviation: InitialDeviation,
+	}
+}
+
+// UpdateRatings updates the ratings from the final placements of one game.
+// 順位を全ペアの対戦結果（上位の勝ち、同順位は引き分け）とみなし、1つの評価期間としてGlicko方式で更新する
+// 戻り値はplayersと同じ順序。2人未満のゲームはレーティングに影響しないためnilを返す
+func UpdateRatings(players []GamePlayerResult, current map[int]Rating) []Rating {
+	if len(players) < 2 {
+		return nil
+	}
+
+	before := make([]Rating, len(players))
+	for i, p := range players {
+		rating, ok := current[p.UserID]
+		if !ok {
+			rating = NewRating(p.UserID)
+		}
+		// 前回のゲームからの実力の変化を見込んで不確かさを広げる
+		rating.Deviation = math.Min(math.Sqrt(rating.Deviation*rating.Deviation+DeviationGrowth*DeviationGrowth), InitialDeviation)
+		before[i] = rating
+	}
+
+	updated := make([]Rating, len(players))
+	for i, p := range players {
+		self := before[i]
+		var variance, delta float64
+		for j, opponent := range players {
+			if i == j {
+				continue
+			}
+			g := glickoG(before[j].Deviation)
+			e := glickoExpected(self.Rating, before[j].Rating, g)
+			variance += g * g * e * (1 - e)
+			delta += g * (placementScore(p.Rank, opponent.Rank) - e)
+		}
+
+		// d^2 = 1 / (q^2 * Σ g^2 E (1-E))
+		dSquared := 1 / (glickoQ * glickoQ * variance)
+		precision := 1/(self.Deviation*self.Deviation) + 1/dSquared
+
+		updated[i] = Rating{
+			UserID:      self.UserID,
+			Rating:      self.Rating + glickoQ/precision*delta,
+			Deviation:   math.Max(math.Sqrt(1/precision), MinDeviation),
+			GamesPlayed: self.GamesPlayed + 1,
+		}
+	}
+	return updated
+}
+
+// glickoG は相手の不確かさに応じて結果の重みを下げる係数
+func glickoG(deviation float64) float64 {
+	return 1 / math.Sqrt(1+3*glickoQ*glickoQ*deviation*deviation/(math.Pi*math.Pi))
+}
+
+// glickoExpected はratingの側が勝つ期待値
+func glickoExpected(rating, opponentRating, g float64) float64 {
+	return 1 / (1 + math.Pow(10, -g*(rating-opponentRating)/400))
+}
+
+// placementScore は順位から見たペアの対戦結果（勝ち1、引き分け0.5、負け0）
+func placementScore(rank, opponentRank int) float64 {
+	switch {
+	case rank < opponentRank:
+		return 1
+	case rank == opponentRank:
+		return 0.5
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,51 @@
+package domain
+
+import "testing"
+
+func TestUpdateRatings(t *testing.T) {
+	players := []GamePlayerResult{
+		{UserID: 1, Rank: 1},
+		{UserID: 2, Rank: 2},
+		{UserID: 3, Rank: 2},
+		{UserID: 4, Rank: 4},
+	}
+	current := map[int]Rating{
+		// 経験を積んだ強いプレイヤーは不確かさが小さいため変動も小さい
+		4: {UserID: 4, Rating: 1800, Deviation: 60, GamesPlayed: 40},
+	}
+
+	updated := UpdateRatings(players, current)
+	if len(updated) != len(players) {
+		t.Fatalf("UpdateRatings() returned %d ratings, want %d", len(updated), len(players))
+	}
+	for i, r := range updated {
+		if r.UserID != players[i].UserID {
+			t.Errorf("updated[%d].UserID = %d, want %d", i, r.UserID, players[i].UserID)
+		}
+		if r.Deviation >= InitialDeviation || r.Deviation < MinDeviation {
+			t.Errorf("user %d: deviation %.1f out of range", r.UserID, r.Deviation)
+		}
+	}
+
+	if updated[0].Rating <= InitialRating {
+		t.Errorf("winner rating = %.1f, want above %.0f", updated[0].Rating, InitialRating)
+	}
+	if updated[1].Rating != updated[2].Rating {
+		t.Errorf("tied players got %.3f and %.3f, want equal", updated[1].Rating, updated[2].Rating)
+	}
+	if updated[3].Rating >= 1800 {
+		t.Errorf("last place rating = %.1f, want below 1800", updated[3].Rating)
+	}
+	if gain, loss := updated[0].Rating-InitialRating, 1800-updated[3].Rating; loss >= gain {
+		t.Errorf("certain player moved %.1f, uncertain winner moved %.1f; want the certain one to move less", loss, gain)
+	}
+	if updated[3].GamesPlayed != 41 {
+		t.Errorf("GamesPlayed = %d, want 41", updated[3].GamesPlayed)
+	}
+}
+
+func TestUpdateRatings_IgnoresSinglePlayerGames(t *testing.T) {
+	if updated := UpdateRatings([]GamePlayerResult{{UserID: 1, Rank: 1}}, nil); updated != nil {
+		t.Errorf("UpdateRatings() = %v, want nil", updated)
+	}
+}
@@ -0,0 +1,95 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"time"
+)
+
+var (
+	// ErrGameNotFound is returned when no finished game has the requested ID
+	ErrGameNotFound = errors.New("game not found")
+	// ErrReplayNotRecorded is returned for games without a replay record (GameModeLegacy)
+	ErrReplayNotRecorded = errors.New("replay not recorded for game")
+)
+
+// GameMove はゲーム中に受理された1回の数式提出
+type GameMove struct {
+	UserID           int
+	Expression       string
+	SubmittedVersion int       // クライアントが見ていた盤面のバージョン
+	Version          int       // 適用後の盤面のバージョン
+	Changes          []Matches // 適用後のバージョンのChangeHistory（消した組）
+	GainedScore      int
+	SubmittedAt      time.Time
+}
+
+// GameReplay は終了したゲームを再現するための記録
+type GameReplay struct {
+	GameID       int64
+	GameUUID     string
+	RoomID       int
+	Seed         int64
+	StartedAt    time.Time
+	EndedAt      time.Time
+	InitialBoard [][]int
+	Players      []GamePlayerResult // 順位順
+	Moves        []GameMove         // 受理順
+}
+
+// ReplayResult は記録から再構築した盤面とスコア
+type ReplayResult struct {
+	Boards []GameBoard // Boards[i]はバージョンi+1の盤面（ChangeHistoryは含まない）
+	Scores map[int]int // userID -> スコア
+	Final  GameBoard   // 最終盤面（ChangeHistoryを含む）
+}
+
+// Replay reconstructs every board version from the seed and the accepted moves.
+// 各手をAttemptMoveで同じ判定にかけ、記録どおりに受理され同じ組を消すことを確かめる
+func (g GameReplay) Replay() (*ReplayResult, error) {
+	board := NewSeededBoard(g.Seed)
+	if !reflect.DeepEqual(board.Board, g.InitialBoard) {
+		return nil, fmt.Errorf("initial board does not match seed %d", g.Seed)
+	}
+
+	// スコアは実際のゲームと同じ連続正解の計算で求める
+	room := &Room{}
+	for _, p := range g.Players {
+		room.Players = append(room.Players, Player{ID: p.UserID})
+	}
+
+	result := &ReplayResult{
+		Boards: []GameBoard{board.Copy()},
+		Scores: make(map[int]int),
+	}
+	for i, move := range g.Moves {
+		if board.Version+1 != move.Version {
+			return nil, fmt.Errorf("move %d: version %d does not follow board version %d", i, move.Version, board.Version)
+		}
+
+		attempt := AttemptMove(&board, move.Expression, move.SubmittedVersion)
+		if attempt.Outcome != MoveSuccess {
+			return nil, fmt.Errorf("move %d: %q was not accepted on replay: %s", i, move.Expression, attempt.Message)
+		}
+		if !reflect.DeepEqual(attempt.Matches, move.Changes) {
+			return nil, fmt.Errorf("move %d: %q cleared different regions on replay", i, move.Expression)
+		}
+
+		gainScore, ok := room.AwardCorrectAnswer(move.UserID, len(attempt.Matches))
+		if !ok {
+			return nil, fmt.Errorf("move %d: user %d is not a player of this game", i, move.UserID)
+		}
+		if gainScore != move.GainedScore {
+			return nil, fmt.Errorf("move %d: gained %d on replay, recorded %d", i, gainScore, move.GainedScore)
+		}
+
+		result.Boards = append(result.Boards, board.Copy())
+	}
+
+	for _, p := range room.Players {
+		result.Scores[p.ID] = p.Score
+	}
+	result.Final = board
+	return result, nil
+}
@@ -4,8 +4,14 @@ import (
 	"fmt"
 	"math/rand"
 	"sort"
+	"time"
+
+	"github.com/google/uuid"
 )
 
+// GameDuration はゲーム1回あたりの制限時間
+const GameDuration = 120 * time.Second
+
 // RoomState represents the game state of a room
 type RoomState int
 
@@ -15,6 +21,7 @@ const (
 	StateCountdown                          // START(カウントダウン中)
 	StateGameInProgress                     // 実際にゲーム開始(盤面情報配信)
 	StateGameEnded                          // ゲーム終了(全員結果表示を閉じるのまち)
+	StatePaused                             // ゲーム一時停止中(残り時間を凍結)
 )
 
 // String returns the string representation of RoomState
@@ -30,6 +37,8 @@ func (rs RoomState) String() string {
 		return "GameInProgress"
 	case StateGameEnded:
 		return "GameEnded"
+	case StatePaused:
+		return "Paused"
 	default:
 		return "Unknown"
 	}
@@ -42,9 +51,18 @@ type Room struct {
 	IsOpened            bool
 	Players             []Player
 	ResultLog           []Result
-	State               RoomState // ステートマシンの現在の状態
-	LastCorrectPlayerID int       //直前の正解者のID
-	StreakCount         int       //連続正解の回数
+	State               RoomState     // ステートマシンの現在の状態
+	LastCorrectPlayerID int           //直前の正解者のID
+	StreakCount         int           //連続正解の回数
+	GameEndsAt          time.Time     // ゲーム終了予定時刻（進行中のみ有効）
+	PausedRemaining     time.Duration // 一時停止時点の残り時間
+	AutoPaused          bool          // 全員切断による自動一時停止かどうか
+	Seed                int64         // 盤面生成に使う乱数シード（ゲーム開始時に決定）
+	GameStartedAt       time.Time     // ゲーム開始時刻（カウントダウン完了時）
+	GameUUID            string        // ゲームごとの識別子（ゲーム開始時に採番）
+	InitialBoard        [][]int       // ゲーム開始時の盤面（リプレイ用）
+	Moves               []GameMove    // このゲームで受理された数式（受理順）
+	Leavers             []Player      // このゲームの途中で退出したプレイヤー（結果では最下位として扱う）
 }
 
 type GameBoard struct {
@@ -53,6 +71,7 @@ type GameBoard struct {
 	Size    int
 	// バージョンごとの変更履歴を記録
 	ChangeHistory map[int][]Matches // version -> 変更された行/列のリスト
+	rng           *rand.Rand        // シード付き盤面の乱数生成器（nilの場合はグローバルな乱数）
 }
 
 type Result struct {
@@ -83,12 +102,22 @@ type Position struct {
 
 // 新規盤面の作成
 func NewBoard() GameBoard {
+	return newBoard(nil)
+}
+
+// NewSeededBoard creates a board whose initial numbers and refills are determined by the seed
+func NewSeededBoard(seed int64) GameBoard {
+	return newBoard(rand.New(rand.NewSource(seed)))
+}
+
+func newBoard(rng *rand.Rand) GameBoard {
 	size := 4
 	gb := &GameBoard{
 		Version:       1,
 		Board:         make([][]int, size),
 		Size:          size,
 		ChangeHistory: make(map[int][]Matches),
+		rng:           rng,
 	}
 	//盤面の初期化
 	for i := range gb.Board {
@@ -101,58 +130,134 @@ func NewBoard() GameBoard {
 	return *gb
 }
 
-// AttemptMoveWithVersion はバージョンを考慮した細かい衝突検出付きの処理（新仕様）
-func AttemptMoveWithVersion(gb *GameBoard, expression string, submittedVersion int) (bool, string, int) {
+// MoveOutcome は数式提出の判定結果の分類
+type MoveOutcome string
+
+const (
+	MoveSuccess  MoveOutcome = "success"
+	MoveConflict MoveOutcome = "conflict"  // 他のプレイヤーの更新と衝突、または不正なバージョン
+	MoveInvalid  MoveOutcome = "invalid"   // 数式として解釈できない
+	MoveNot10    MoveOutcome = "not_10"    // 計算結果が10にならない
+	MoveNoRegion MoveOutcome = "no_region" // 数式の数字の組が盤面上に見つからない
+)
+
+// IsRejection reports whether the outcome counts as a rejected formula in the stats.
+// 他のプレイヤーとの衝突は数式の誤りではないため数えない
+func (o MoveOutcome) IsRejection() bool {
+	switch o {
+	case MoveInvalid, MoveNot10, MoveNoRegion:
+		return true
+	}
+	return false
+}
+
+// MoveResult は数式提出の判定結果
+type MoveResult struct {
+	Outcome MoveOutcome
+	Message string    // 失敗時のエラーメッセージ
+	Matches []Matches // 数式の数字の組と一致した行・列（成功時は消した組）
+}
+
+// AttemptMove checks the formula against the board and applies it when accepted.
+// 判定の順序はAttemptMoveWithVersionと同じ（盤面の一致 → 衝突 → 計算）
+func AttemptMove(gb *GameBoard, expression string, submittedVersion int) MoveResult {
 	matches, found := FindAllMatchingLinesWithSets(gb, expression)
 	if !found {
-		return false, "エラー: その計算式で使える数字の組み合わせは、盤面上に見つかりません。", 0
+		outcome := MoveNoRegion
+		if _, err := ExtractAndSortNumbers(expression); err != nil {
+			outcome = MoveInvalid
+		}
+		return MoveResult{Outcome: outcome, Message: "エラー: その計算式で使える数字の組み合わせは、盤面上に見つかりません。"}
 	}
 
 	// バージョン衝突チェック（細かい衝突検出）
 	hasConflict, conflictMsg := gb.CheckConflictWithPositions(submittedVersion, matches)
 	if hasConflict {
-		return false, conflictMsg, 0
+		return MoveResult{Outcome: MoveConflict, Message: conflictMsg, Matches: matches}
 	}
 
 	// 新しいRPN専用計算システムを使用
 	calculator := NewFormulaCalculator()
 	evalResult, err := calculator.EvaluateFormula(expression)
 	if err != nil {
-		return false, fmt.Sprintf("エラー: 無効な数式です (%s)", err.Error()), 0
+		return MoveResult{Outcome: MoveInvalid, Message: fmt.Sprintf("エラー: 無効な数式です (%s)", err.Error()), Matches: matches}
 	}
 
 	// 結果が10かどうかをチェック
 	if !calculator.CheckTarget10(evalResult) {
 		// solvePoland.tsと同じ形式でより詳細な結果を返す
+		var message string
 		resultType := calculator.CheckResultType(evalResult)
 		switch resultType {
 		case "Not an integer":
-			return false, "エラー: 計算結果が整数になりません。", 0
+			message = "エラー: 計算結果が整数になりません。"
 		case "Not 10":
-			return false, fmt.Sprintf("エラー: 計算結果が10になりません。(結果: %.0f)", evalResult), 0
+			message = fmt.Sprintf("エラー: 計算結果が10になりません。(結果: %.0f)", evalResult)
 		default:
-			return false, fmt.Sprintf("エラー: 計算結果が10になりません。(結果: %.6f)", evalResult), 0
+			message = fmt.Sprintf("エラー: 計算結果が10になりません。(結果: %.6f)", evalResult)
 		}
+		return MoveResult{Outcome: MoveNot10, Message: message, Matches: matches}
 	}
 
 	// 検証をクリアしたら盤面を更新（新仕様）
 	gb.UpdateLinesWithPositions(matches)
 
+	return MoveResult{Outcome: MoveSuccess, Matches: matches}
+}
+
+// AttemptMoveWithVersion はバージョンを考慮した細かい衝突検出付きの処理（新仕様）
+func AttemptMoveWithVersion(gb *GameBoard, expression string, submittedVersion int) (bool, string, int) {
+	result := AttemptMove(gb, expression, submittedVersion)
+	if result.Outcome != MoveSuccess {
+		return false, result.Message, 0
+	}
 	// 成功時は true と空のメッセージ、マッチ数を返す
-	return true, "", len(matches)
+	return true, "", len(result.Matches)
+}
+
+// Copy returns a deep copy of the board without change history
+func (gb *GameBoard) Copy() GameBoard {
+	board := GameBoard{
+		Version:       gb.Version,
+		Size:          gb.Size,
+		Board:         make([][]int, len(gb.Board)),
+		ChangeHistory: make(map[int][]Matches),
+	}
+	for i := range gb.Board {
+		board.Board[i] = make([]int, len(gb.Board[i]))
+		copy(board.Board[i], gb.Board[i])
+	}
+	return board
+}
+
+// Flatten returns the board content as a row-major slice
+func (gb *GameBoard) Flatten() []int {
+	content := make([]int, 0, gb.Size*gb.Size)
+	for i := 0; i < gb.Size; i++ {
+		content = append(content, gb.Board[i]...)
+	}
+	return content
+}
+
+// randomNumber は1から9の乱数を返す（シード付き盤面では盤面の乱数生成器を使用）
+func (gb GameBoard) randomNumber() int {
+	if gb.rng != nil {
+		return gb.rng.Intn(9) + 1
+	}
+	return rand.Intn(9) + 1
 }
 
 // 指定の列を1から9のランダムな整数で埋める
 func (gb GameBoard) PopulateRow(row int) {
 	for i := 0; i < gb.Size; i++ {
-		gb.Board[row][i] = rand.Intn(9) + 1 //1-9の乱数
+		gb.Board[row][i] = gb.randomNumber() //1-9の乱数
 	}
 }
 
 // 指定の行を1から9のランダムな整数で埋める
 func (gb GameBoard) PopulateColumn(col int) {
 	for i := 0; i < gb.Size; i++ {
-		gb.Board[i][col] = rand.Intn(9) + 1 // 1-9の乱数
+		gb.Board[i][col] = gb.randomNumber() // 1-9の乱数
 	}
 }
 
@@ -194,7 +299,7 @@ func (gb *GameBoard) UpdateLinesWithPositions(matches []Matches) error {
 		if pos.Row < 0 || pos.Row >= gb.Size || pos.Col < 0 || pos.Col >= gb.Size {
 			return fmt.Errorf("無効なマス位置: (%d, %d)", pos.Row, pos.Col)
 		}
-		gb.Board[pos.Row][pos.Col] = rand.Intn(9) + 1
+		gb.Board[pos.Row][pos.Col] = gb.randomNumber()
 	}
 
 	gb.Version++
@@ -293,7 +398,9 @@ func (r *Room) CanTransitionTo(newState RoomState) bool {
 	case StateCountdown:
 		return newState == StateGameInProgress || newState == StateWaitingForPlayers
 	case StateGameInProgress:
-		return newState == StateGameEnded || newState == StateWaitingForPlayers
+		return newState == StateGameEnded || newState == StatePaused || newState == StateWaitingForPlayers
+	case StatePaused:
+		return newState == StateGameInProgress || newState == StateWaitingForPlayers
 	case StateGameEnded:
 		return newState == StateWaitingForPlayers
 	default:
@@ -363,7 +470,183 @@ func (r *Room) CompleteCountdown() error {
 	if r.State != StateCountdown {
 		return fmt.Errorf("room is not in countdown state")
 	}
-	return r.TransitionTo(StateGameInProgress)
+	if err := r.TransitionTo(StateGameInProgress); err != nil {
+		return err
+	}
+	now := time.Now()
+	r.GameStartedAt = now
+	r.GameEndsAt = now.Add(GameDuration)
+	r.PausedRemaining = 0
+	r.AutoPaused = false
+	r.Seed = now.UnixNano()
+	r.GameUUID = uuid.NewString()
+	r.InitialBoard = nil
+	r.Moves = nil
+	r.Leavers = nil
+	r.LastCorrectPlayerID = 0
+	r.StreakCount = 0
+	// スコア・消した組数・数式の正誤はゲームごとに数える
+	for i := range r.Players {
+		r.Players[i].Score = 0
+		r.Players[i].ClearedRegions = 0
+		r.Players[i].AcceptedFormulas = 0
+		r.Players[i].RejectedFormulas = 0
+		r.Players[i].LongestAnswerStreak = 0
+	}
+	return nil
+}
+
+// PauseGame freezes the remaining game time and transitions to paused state
+func (r *Room) PauseGame(now time.Time, auto bool) error {
+	if r.State != StateGameInProgress {
+		return fmt.Errorf("game is not in progress")
+	}
+	remaining := r.RemainingTime(now)
+	if err := r.TransitionTo(StatePaused); err != nil {
+		return err
+	}
+	r.PausedRemaining = remaining
+	r.AutoPaused = auto
+	return nil
+}
+
+// ResumeGame resumes the game with the remaining time frozen at pause
+func (r *Room) ResumeGame(now time.Time) error {
+	if r.State != StatePaused {
+		return fmt.Errorf("game is not paused")
+	}
+	if err := r.TransitionTo(StateGameInProgress); err != nil {
+		return err
+	}
+	r.GameEndsAt = now.Add(r.PausedRemaining)
+	r.PausedRemaining = 0
+	r.AutoPaused = false
+	return nil
+}
+
+// RemainingTime returns the remaining game time at the given moment
+func (r *Room) RemainingTime(now time.Time) time.Duration {
+	var remaining time.Duration
+	switch r.State {
+	case StateGameInProgress:
+		remaining = r.GameEndsAt.Sub(now)
+	case StatePaused:
+		remaining = r.PausedRemaining
+	}
+	if remaining < 0 {
+		return 0
+	}
+	return remaining
+}
+
+// GameClock はゲームタイマーの状態のスナップショット
+type GameClock struct {
+	State      RoomState
+	Remaining  time.Duration
+	AutoPaused bool
+}
+
+// Clock returns a snapshot of the game clock at the given moment
+func (r *Room) Clock(now time.Time) GameClock {
+	return GameClock{
+		State:      r.State,
+		Remaining:  r.RemainingTime(now),
+		AutoPaused: r.AutoPaused,
+	}
+}
+
+// RoomSnapshot は再接続したクライアントに送るroomの状態のコピー
+type RoomSnapshot struct {
+	ID                  int
+	Name                string
+	State               RoomState
+	IsOpened            bool
+	Players             []Player
+	Board               *GameBoard // 現在の盤面（ゲーム開始前はnil）
+	LastCorrectPlayerID int
+	StreakCount         int
+	GameEndsAt          time.Time // ゲーム進行中・一時停止中のみ有効
+	Remaining           time.Duration
+}
+
+// Snapshot returns a deep copy of the room state at the given moment
+func (r *Room) Snapshot(now time.Time) RoomSnapshot {
+	snapshot := RoomSnapshot{
+		ID:                  r.ID,
+		Name:                r.Name,
+		State:               r.State,
+		IsOpened:            r.IsOpened,
+		Players:             make([]Player, len(r.Players)),
+		LastCorrectPlayerID: r.LastCorrectPlayerID,
+		StreakCount:         r.StreakCount,
+		Remaining:           r.RemainingTime(now),
+	}
+	copy(snapshot.Players, r.Players)
+
+	if r.State == StateGameInProgress || r.State == StatePaused {
+		snapshot.GameEndsAt = now.Add(snapshot.Remaining)
+		if len(r.GameBoards) > 0 {
+			board := r.GameBoards[len(r.GameBoards)-1].Copy()
+			snapshot.Board = &board
+		}
+	}
+	return snapshot
+}
+
+// HasConnectedPlayers checks if at least one player is still connected
+func (r *Room) HasConnectedPlayers() bool {
+	for _, player := range r.Players {
+		if player.IsConnected {
+			return true
+		}
+	}
+	return false
+}
+
+// AddGameBoard appends a board to the room.
+// ゲーム進行中に追加された盤面はリプレイ用の初期盤面として記録する
+func (r *Room) AddGameBoard(board GameBoard) {
+	r.GameBoards = append(r.GameBoards, board)
+	if r.State == StateGameInProgress && r.InitialBoard == nil {
+		r.InitialBoard = board.Copy().Board
+	}
+}
+
+// AwardCorrectAnswer は連続正解数を更新し、消した組数に応じたスコアをプレイヤーに加算する
+// スコア計算: 消した組数 * (5+5*"連続正解数")点。プレイヤーがいなければfalseを返す
+func (r *Room) AwardCorrectAnswer(playerID int, matchCount int) (int, bool) {
+	for i := range r.Players {
+		if r.Players[i].ID != playerID {
+			continue
+		}
+		// 連続正解数をカウント
+		if r.LastCorrectPlayerID == playerID {
+			r.StreakCount++
+		} else {
+			r.StreakCount = 1
+			r.LastCorrectPlayerID = playerID
+		}
+
+		if r.StreakCount > r.Players[i].LongestAnswerStreak {
+			r.Players[i].LongestAnswerStreak = r.StreakCount
+		}
+
+		gainScore := matchCount * (5 + 5*r.StreakCount)
+		r.Players[i].Score += gainScore
+		r.Players[i].ClearedRegions += matchCount
+		r.Players[i].AcceptedFormulas++
+		return gainScore, true
+	}
+	return 0, false
+}
+
+// RecordLeaver records a player who left the game in progress.
+// 退出でレーティングの低下を避けられないよう、ゲーム結果には最下位として残す。ゲーム中でなければ何もしない
+func (r *Room) RecordLeaver(player Player) {
+	if r.State != StateGameInProgress && r.State != StatePaused {
+		return
+	}
+	r.Leavers = append(r.Leavers, player)
 }
 
 // EndGame ends the current game
@@ -398,6 +681,7 @@ func (r *Room) ResetRoom() error {
 	// 連続正解情報もリセット
 	r.LastCorrectPlayerID = 0
 	r.StreakCount = 0
+	r.Leavers = nil
 	return r.TransitionTo(StateWaitingForPlayers)
 }
 
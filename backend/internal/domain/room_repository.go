@@ -0,0 +1,117 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// ErrRoomNotFound is matched by the error returned for a room that does not exist
+var ErrRoomNotFound = errors.New("room not found")
+
+// RoomNotFoundError はroomが存在しないことを表すエラー（errors.Is(err, ErrRoomNotFound)で判定できる）
+type RoomNotFoundError struct {
+	RoomID int
+}
+
+func (e *RoomNotFoundError) Error() string {
+	return fmt.Sprintf("room with ID %d not found", e.RoomID)
+}
+
+func (e *RoomNotFoundError) Is(target error) bool {
+	return target == ErrRoomNotFound
+}
+
+// RoomRepository stores the rooms.
+// ViewRoom・UpdateRoomはroomをロックしてfnを呼ぶ。GetRoom・ListRoomsが返したroomの内容を
+// ロックの外で読み書きしないこと（IDのみ不変）
+type RoomRepository interface {
+	GetRoom(roomID int) (*Room, error)
+	ListRooms() []*Room // ID順
+	SaveRoom(room *Room) error
+	DeleteRoom(roomID int) error
+	// ViewRoom は読み取り用にroomをロックしてfnを呼ぶ
+	ViewRoom(roomID int, fn func(room *Room) error) error
+	// UpdateRoom は更新用にroomをロックしてfnを呼ぶ。fnがエラーを返した場合はnilとそのエラーを返す
+	UpdateRoom(roomID int, fn func(room *Room) error) (*Room, error)
+}
+
+type memoryRoomRepository struct {
+	mutex sync.RWMutex
+	rooms map[int]*Room
+}
+
+// NewMemoryRoomRepository returns a repository that keeps the rooms in memory.
+// 全roomを1つのRWMutexで保護する
+func NewMemoryRoomRepository() RoomRepository {
+	return &memoryRoomRepository{
+		rooms: make(map[int]*Room),
+	}
+}
+
+func (m *memoryRoomRepository) GetRoom(roomID int) (*Room, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	room, exists := m.rooms[roomID]
+	if !exists {
+		return nil, &RoomNotFoundError{RoomID: roomID}
+	}
+	return room, nil
+}
+
+func (m *memoryRoomRepository) ListRooms() []*Room {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	rooms := make([]*Room, 0, len(m.rooms))
+	for _, room := range m.rooms {
+		rooms = append(rooms, room)
+	}
+	sort.Slice(rooms, func(i, j int) bool { return rooms[i].ID < rooms[j].ID })
+	return rooms
+}
+
+func (m *memoryRoomRepository) SaveRoom(room *Room) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.rooms[room.ID] = room
+	return nil
+}
+
+func (m *memoryRoomRepository) DeleteRoom(roomID int) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if _, exists := m.rooms[roomID]; !exists {
+		return &RoomNotFoundError{RoomID: roomID}
+	}
+	delete(m.rooms, roomID)
+	return nil
+}
+
+func (m *memoryRoomRepository) ViewRoom(roomID int, fn func(room *Room) error) error {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	room, exists := m.rooms[roomID]
+	if !exists {
+		return &RoomNotFoundError{RoomID: roomID}
+	}
+	return fn(room)
+}
+
+func (m *memoryRoomRepository) UpdateRoom(roomID int, fn func(room *Room) error) (*Room, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	room, exists := m.rooms[roomID]
+	if !exists {
+		return nil, &RoomNotFoundError{RoomID: roomID}
+	}
+	if err := fn(room); err != nil {
+		return nil, err
+	}
+	return room, nil
+}
@@ -0,0 +1,169 @@
+package domain
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"time"
+)
+
+// RoomRecord はroomを永続化するための状態
+// 盤面そのものは保存せず、シードと受理された数式からリプレイと同じ手順で再構築する
+type RoomRecord struct {
+	ID                  int
+	Name                string
+	State               RoomState
+	IsOpened            bool
+	Players             []Player
+	LastCorrectPlayerID int
+	StreakCount         int
+	GameEndsAt          time.Time     // ゲーム進行中のみ有効
+	PausedRemaining     time.Duration // 一時停止中のみ有効
+	AutoPaused          bool
+	Seed                int64
+	GameStartedAt       time.Time
+	GameUUID            string
+	InitialBoard        [][]int
+	Moves               []GameMove
+	Leavers             []Player
+	SavedAt             time.Time
+}
+
+// RoomStore saves room records to durable storage and loads them at startup.
+// SaveRoomはroomのロックを保持したまま呼ばれるため、実装はブロックせずに戻ること
+type RoomStore interface {
+	SaveRoom(record RoomRecord)
+	LoadRooms(ctx context.Context) ([]RoomRecord, error)
+}
+
+// Record returns a deep copy of the room state to be persisted
+func (r *Room) Record(now time.Time) RoomRecord {
+	record := RoomRecord{
+		ID:                  r.ID,
+		Name:                r.Name,
+		State:               r.State,
+		IsOpened:            r.IsOpened,
+		Players:             make([]Player, len(r.Players)),
+		LastCorrectPlayerID: r.LastCorrectPlayerID,
+		StreakCount:         r.StreakCount,
+		GameEndsAt:          r.GameEndsAt,
+		PausedRemaining:     r.PausedRemaining,
+		AutoPaused:          r.AutoPaused,
+		Seed:                r.Seed,
+		GameStartedAt:       r.GameStartedAt,
+		GameUUID:            r.GameUUID,
+		Moves:               make([]GameMove, len(r.Moves)),
+		Leavers:             append([]Player(nil), r.Leavers...),
+		SavedAt:             now,
+	}
+	copy(record.Players, r.Players)
+	copy(record.Moves, r.Moves)
+	if r.InitialBoard != nil {
+		record.InitialBoard = make([][]int, len(r.InitialBoard))
+		for i, row := range r.InitialBoard {
+			record.InitialBoard[i] = append([]int(nil), row...)
+		}
+	}
+	return record
+}
+
+// RestoreRoom rebuilds a room from a persisted record.
+// 再起動で全員の接続が切れているため、プレイヤーは切断中として復元する
+// 進行中のゲームは終了予定時刻までの残り時間で自動一時停止とし、再接続したプレイヤーが再開する
+// 残り時間がない・カウントダウン中だった・盤面を再構築できないゲームは中断して待機状態に戻し、
+// その理由をエラーとして返す（エラーの場合もroomは利用できる）
+func RestoreRoom(record RoomRecord, now time.Time) (*Room, error) {
+	room := NewRoom(record.ID, record.Name)
+	room.State = record.State
+	room.IsOpened = record.IsOpened
+	room.LastCorrectPlayerID = record.LastCorrectPlayerID
+	room.StreakCount = record.StreakCount
+	room.Seed = record.Seed
+	room.GameStartedAt = record.GameStartedAt
+	room.GameUUID = record.GameUUID
+	room.InitialBoard = record.InitialBoard
+	room.Moves = record.Moves
+	room.Leavers = record.Leavers
+	room.Players = make([]Player, len(record.Players))
+	for i, p := range record.Players {
+		p.IsConnected = false
+		lastSeenAt := now
+		p.LastSeenAt = &lastSeenAt
+		room.Players[i] = p
+	}
+
+	switch record.State {
+	case StateCountdown:
+		// カウントダウンを進めるゴルーチンは再起動で失われている
+		room.AbortGame()
+		return room, fmt.Errorf("game in room %d was counting down", record.ID)
+	case StateGameInProgress, StatePaused:
+		remaining := record.PausedRemaining
+		if record.State == StateGameInProgress {
+			remaining = record.GameEndsAt.Sub(now)
+		}
+		if remaining <= 0 {
+			room.AbortGame()
+			return room, fmt.Errorf("game in room %d ran out of time while the server was down", record.ID)
+		}
+
+		board, err := rebuildBoard(record)
+		if err != nil {
+			room.AbortGame()
+			return room, fmt.Errorf("failed to rebuild board of room %d: %w", record.ID, err)
+		}
+		room.GameBoards = []GameBoard{board}
+		if room.InitialBoard == nil {
+			room.InitialBoard = NewSeededBoard(record.Seed).Board
+		}
+
+		room.State = StatePaused
+		room.PausedRemaining = remaining
+		// ホストによる一時停止はそのまま、進行中だったゲームは全員切断による自動一時停止とする
+		room.AutoPaused = record.State == StateGameInProgress || record.AutoPaused
+	}
+	return room, nil
+}
+
+// rebuildBoard はシードから初期盤面を生成し、受理された数式で消した組を順に補充し直す
+// 補充される数字は盤面の乱数生成器から同じ順に引かれるため、ゲーム中と同じ盤面・変更履歴になる
+func rebuildBoard(record RoomRecord) (GameBoard, error) {
+	board := NewSeededBoard(record.Seed)
+	if record.InitialBoard != nil && !reflect.DeepEqual(board.Board, record.InitialBoard) {
+		return GameBoard{}, fmt.Errorf("initial board does not match seed %d", record.Seed)
+	}
+	for i, move := range record.Moves {
+		if err := board.UpdateLinesWithPositions(move.Changes); err != nil {
+			return GameBoard{}, fmt.Errorf("move %d: %w", i+1, err)
+		}
+		if board.Version != move.Version {
+			return GameBoard{}, fmt.Errorf("move %d: board version %d, recorded %d", i+1, board.Version, move.Version)
+		}
+	}
+	return board, nil
+}
+
+// AbortGame discards the current game and returns the room to the waiting state.
+// プレイヤーは残し、READY状態とスコアをリセットする
+func (r *Room) AbortGame() {
+	r.State = StateWaitingForPlayers
+	r.IsOpened = true
+	r.GameBoards = []GameBoard{NewBoard()}
+	r.GameEndsAt = time.Time{}
+	r.PausedRemaining = 0
+	r.AutoPaused = false
+	r.InitialBoard = nil
+	r.Moves = nil
+	r.Leavers = nil
+	r.LastCorrectPlayerID = 0
+	r.StreakCount = 0
+	for i := range r.Players {
+		r.Players[i].IsReady = false
+		r.Players[i].HasClosedResult = false
+		r.Players[i].Score = 0
+		r.Players[i].ClearedRegions = 0
+		r.Players[i].AcceptedFormulas = 0
+		r.Players[i].RejectedFormulas = 0
+		r.Players[i].LongestAnswerStreak = 0
+	}
+}
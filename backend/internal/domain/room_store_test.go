@@ -0,0 +1,99 @@
+package domain
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+// gameInProgress は受理された数式を2つ適用した進行中のroomを作る
+func gameInProgress(t *testing.T, now time.Time) *Room {
+	t.Helper()
+	room := NewRoom(1, "Room 1")
+	room.Players = []Player{{ID: 1, UserName: "alice", IsReady: true, IsConnected: true}}
+	room.State = StateCountdown
+	if err := room.CompleteCountdown(); err != nil {
+		t.Fatalf("CompleteCountdown() error = %v", err)
+	}
+	room.GameEndsAt = now.Add(60 * time.Second)
+	room.AddGameBoard(NewSeededBoard(room.Seed))
+
+	board := &room.GameBoards[len(room.GameBoards)-1]
+	for _, changes := range [][]Matches{
+		{{Linetype: "row", Index: 0, Positions: []Position{{0, 0}, {0, 1}, {0, 2}, {0, 3}}}},
+		{{Linetype: "col", Index: 2, Positions: []Position{{0, 2}, {1, 2}, {2, 2}, {3, 2}}}},
+	} {
+		board.UpdateLinesWithPositions(changes)
+		gainScore, _ := room.AwardCorrectAnswer(1, len(changes))
+		room.Moves = append(room.Moves, GameMove{UserID: 1, Version: board.Version, Changes: changes, GainedScore: gainScore})
+	}
+	return room
+}
+
+func TestRestoreRoom_ResumesGameInProgress(t *testing.T) {
+	now := time.Now()
+	room := gameInProgress(t, now)
+	record := room.Record(now)
+
+	restored, err := RestoreRoom(record, now.Add(20*time.Second))
+	if err != nil {
+		t.Fatalf("RestoreRoom() error = %v", err)
+	}
+
+	// 全員切断中として自動一時停止し、停止していた間の時間は残り時間から引かれる
+	if restored.State != StatePaused || !restored.AutoPaused {
+		t.Errorf("state = %s (auto %v), want auto-paused", restored.State, restored.AutoPaused)
+	}
+	if restored.PausedRemaining != 40*time.Second {
+		t.Errorf("PausedRemaining = %v, want 40s", restored.PausedRemaining)
+	}
+	if restored.Players[0].IsConnected || restored.Players[0].Score != room.Players[0].Score {
+		t.Errorf("player = %+v", restored.Players[0])
+	}
+
+	want := room.GameBoards[len(room.GameBoards)-1]
+	got := restored.GameBoards[len(restored.GameBoards)-1]
+	if got.Version != want.Version || !reflect.DeepEqual(got.Board, want.Board) || !reflect.DeepEqual(got.ChangeHistory, want.ChangeHistory) {
+		t.Errorf("rebuilt board = %v (version %d), want %v (version %d)", got.Board, got.Version, want.Board, want.Version)
+	}
+
+	// 補充の乱数も続きから引かれるため、以後の手も元のゲームと同じ盤面になる
+	changes := []Matches{{Linetype: "row", Index: 3, Positions: []Position{{3, 0}, {3, 1}, {3, 2}, {3, 3}}}}
+	want.UpdateLinesWithPositions(changes)
+	got.UpdateLinesWithPositions(changes)
+	if !reflect.DeepEqual(got.Board, want.Board) {
+		t.Errorf("board after next move = %v, want %v", got.Board, want.Board)
+	}
+}
+
+func TestRestoreRoom_AbortsUnrecoverableGames(t *testing.T) {
+	now := time.Now()
+
+	expired := gameInProgress(t, now).Record(now)
+	tampered := gameInProgress(t, now).Record(now)
+	tampered.Moves[1].Version = 10
+	countdown := NewRoom(2, "Room 2")
+	countdown.Players = []Player{{ID: 1, IsReady: true}}
+	countdown.State = StateCountdown
+
+	for name, record := range map[string]RoomRecord{
+		"expired":   expired,
+		"tampered":  tampered,
+		"countdown": countdown.Record(now),
+	} {
+		restoredAt := now
+		if name == "expired" {
+			restoredAt = now.Add(2 * time.Minute)
+		}
+		room, err := RestoreRoom(record, restoredAt)
+		if err == nil {
+			t.Errorf("%s: RestoreRoom() error = nil, want abort reason", name)
+		}
+		if room.State != StateWaitingForPlayers || !room.IsOpened || len(room.Moves) != 0 {
+			t.Errorf("%s: state = %s, opened %v, moves %d, want aborted", name, room.State, room.IsOpened, len(room.Moves))
+		}
+		if len(room.Players) != 1 || room.Players[0].IsReady || room.Players[0].Score != 0 {
+			t.Errorf("%s: players = %+v, want kept and reset", name, room.Players)
+		}
+	}
+}
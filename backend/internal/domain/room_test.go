@@ -0,0 +1,183 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRoom_PauseAndResumeGame(t *testing.T) {
+	room := NewRoom(1, "Room 1")
+	room.State = StateGameInProgress
+	now := time.Now()
+	room.GameEndsAt = now.Add(90 * time.Second)
+
+	if err := room.PauseGame(now, false); err != nil {
+		t.Fatalf("Unexpected error on pause: %v", err)
+	}
+	if room.State != StatePaused {
+		t.Errorf("Expected state %s, got %s", StatePaused, room.State)
+	}
+
+	// 一時停止中は残り時間が減らない
+	later := now.Add(30 * time.Second)
+	if remaining := room.RemainingTime(later); remaining != 90*time.Second {
+		t.Errorf("Expected remaining 90s while paused, got %v", remaining)
+	}
+
+	if err := room.PauseGame(later, false); err == nil {
+		t.Errorf("Expected error when pausing an already paused game")
+	}
+
+	if err := room.ResumeGame(later); err != nil {
+		t.Fatalf("Unexpected error on resume: %v", err)
+	}
+	if room.State != StateGameInProgress {
+		t.Errorf("Expected state %s, got %s", StateGameInProgress, room.State)
+	}
+	if !room.GameEndsAt.Equal(later.Add(90 * time.Second)) {
+		t.Errorf("Expected game to end at %v, got %v", later.Add(90*time.Second), room.GameEndsAt)
+	}
+
+	if err := room.ResumeGame(later); err == nil {
+		t.Errorf("Expected error when resuming a game that is not paused")
+	}
+}
+
+func TestRoom_ResultRanksPlayers(t *testing.T) {
+	room := NewRoom(1, "Room 1")
+	room.Players = []Player{
+		{ID: 1, Score: 30, ClearedRegions: 2},
+		{ID: 2, Score: 50, ClearedRegions: 3},
+		{ID: 3, Score: 30, ClearedRegions: 1},
+		{ID: 4, Score: 0},
+	}
+
+	result := room.Result(time.Now())
+
+	want := []struct {
+		userID int
+		rank   int
+	}{
+		{2, 1},
+		{1, 2},
+		{3, 2}, // 同点は同順位
+		{4, 4},
+	}
+	if len(result.Players) != len(want) {
+		t.Fatalf("Expected %d players, got %d", len(want), len(result.Players))
+	}
+	for i, w := range want {
+		got := result.Players[i]
+		if got.UserID != w.userID || got.Rank != w.rank {
+			t.Errorf("Position %d: expected user %d rank %d, got user %d rank %d", i, w.userID, w.rank, got.UserID, got.Rank)
+		}
+	}
+}
+
+func TestRoom_ResultRanksLeaversLast(t *testing.T) {
+	room := NewRoom(1, "Room 1")
+	room.State = StateGameInProgress
+	room.Players = []Player{
+		{ID: 1, Score: 80},
+		{ID: 2, Score: 10},
+		{ID: 3, Score: 0},
+	}
+	room.RecordLeaver(room.Players[0])
+	room.Players = room.Players[1:]
+
+	result := room.Result(time.Now())
+
+	want := []struct {
+		userID int
+		rank   int
+		left   bool
+	}{
+		{2, 1, false},
+		{3, 2, false},
+		{1, 3, true}, // 退出したプレイヤーはスコアが高くても最下位
+	}
+	if len(result.Players) != len(want) {
+		t.Fatalf("Expected %d players, got %d", len(want), len(result.Players))
+	}
+	for i, w := range want {
+		got := result.Players[i]
+		if got.UserID != w.userID || got.Rank != w.rank || got.Left != w.left {
+			t.Errorf("Position %d: expected user %d rank %d left %v, got user %d rank %d left %v", i, w.userID, w.rank, w.left, got.UserID, got.Rank, got.Left)
+		}
+	}
+
+	// ゲーム中でなければ退出を記録しない
+	room.State = StateWaitingForPlayers
+	room.RecordLeaver(Player{ID: 4})
+	if len(room.Leavers) != 1 {
+		t.Errorf("Expected 1 leaver, got %d", len(room.Leavers))
+	}
+}
+
+func TestRoom_AwardCorrectAnswerTracksLongestAnswerStreak(t *testing.T) {
+	room := NewRoom(1, "Room 1")
+	room.Players = []Player{{ID: 1}, {ID: 2}}
+
+	for _, playerID := range []int{1, 1, 1, 2, 1} {
+		if _, ok := room.AwardCorrectAnswer(playerID, 1); !ok {
+			t.Fatalf("AwardCorrectAnswer(%d) did not find the player", playerID)
+		}
+	}
+
+	// 他のプレイヤーの正解で連続正解は途切れるが、最長記録は残る
+	if got := room.Players[0].LongestAnswerStreak; got != 3 {
+		t.Errorf("player 1 LongestAnswerStreak = %d, want 3", got)
+	}
+	if got := room.Players[1].LongestAnswerStreak; got != 1 {
+		t.Errorf("player 2 LongestAnswerStreak = %d, want 1", got)
+	}
+	if room.StreakCount != 1 {
+		t.Errorf("StreakCount = %d, want 1", room.StreakCount)
+	}
+}
+
+func TestMoveOutcome_IsRejection(t *testing.T) {
+	tests := map[MoveOutcome]bool{
+		MoveSuccess:  false,
+		MoveConflict: false, // 衝突は誤答ではない
+		MoveInvalid:  true,
+		MoveNot10:    true,
+		MoveNoRegion: true,
+	}
+	for outcome, want := range tests {
+		if got := outcome.IsRejection(); got != want {
+			t.Errorf("%s.IsRejection() = %v, want %v", outcome, got, want)
+		}
+	}
+}
+
+func TestNewSeededBoard_IsDeterministic(t *testing.T) {
+	first := NewSeededBoard(42)
+	second := NewSeededBoard(42)
+
+	if !equalBoards(first.Board, second.Board) {
+		t.Fatalf("Expected identical initial boards for the same seed")
+	}
+
+	// 同じ位置の更新は同じ数字で補充される
+	matches := []Matches{{Linetype: "row", Index: 0, Positions: []Position{{0, 0}, {0, 1}, {0, 2}, {0, 3}}}}
+	first.UpdateLinesWithPositions(matches)
+	second.UpdateLinesWithPositions(matches)
+	if !equalBoards(first.Board, second.Board) {
+		t.Errorf("Expected identical boards after refill for the same seed")
+	}
+}
+
+func equalBoards(a, b [][]int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		for j := range a[i] {
+			if a[i][j] != b[i][j] {
+				return false
+			}
+		}
+	}
+	return true
+}
@@ -0,0 +1,24 @@
+package domain
+
+import "time"
+
+// FormulaSubmission は1回の数式提出の記録（難易度の分析・判定の調査・衝突検出の調整に使う）
+type FormulaSubmission struct {
+	GameUUID         string
+	RoomID           int
+	UserID           int
+	SubmittedVersion int // クライアントが見ていた盤面のバージョン
+	BoardVersion     int // 判定時点の盤面のバージョン
+	Expression       string
+	Outcome          MoveOutcome
+	Matches          []Matches // 数式の数字の組と一致した行・列
+	GainedScore      int
+	Latency          time.Duration // 受け付けから判定完了までの時間
+	SubmittedAt      time.Time
+}
+
+// SubmissionLogger records formula submissions.
+// 呼び出し元をブロックしないこと（書き込みは非同期に行う）
+type SubmissionLogger interface {
+	LogSubmission(submission FormulaSubmission)
+}
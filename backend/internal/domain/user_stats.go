@@ -0,0 +1,68 @@
+package domain
+
+import "context"
+
+// UserStats はユーザーの通算成績（ゲーム終了時に更新される集計値）
+type UserStats struct {
+	UserID              int
+	GamesPlayed         int
+	Wins                int
+	BestScore           int
+	TotalScore          int
+	CurrentStreak       int // 現在の連勝数（1位で終えたゲームの連続数）
+	LongestStreak       int // 最長連勝数
+	AcceptedFormulas    int
+	RejectedFormulas    int // 衝突を除いた誤答の数
+	LongestAnswerStreak int // 1ゲーム内の最長連続正解数（Room.StreakCountの最大値）
+}
+
+// ApplyGameResult adds the result of one finished game to the stats.
+// 1位（同点を含む）を勝利とする。1人プレイのゲームは勝敗に数えず、連勝も途切れない
+// 連続正解数は1人プレイのゲームも含めて最大値を残す
+func (s *UserStats) ApplyGameResult(player GamePlayerResult, playerCount int) {
+	s.GamesPlayed++
+	s.TotalScore += player.Score
+	if player.Score > s.BestScore {
+		s.BestScore = player.Score
+	}
+	s.AcceptedFormulas += player.AcceptedFormulas
+	s.RejectedFormulas += player.RejectedFormulas
+	if player.LongestAnswerStreak > s.LongestAnswerStreak {
+		s.LongestAnswerStreak = player.LongestAnswerStreak
+	}
+
+	if playerCount < 2 {
+		return
+	}
+	if player.Rank == 1 {
+		s.Wins++
+		s.CurrentStreak++
+		if s.CurrentStreak > s.LongestStreak {
+			s.LongestStreak = s.CurrentStreak
+		}
+	} else {
+		s.CurrentStreak = 0
+	}
+}
+
+// AverageScore returns the average score per game
+func (s UserStats) AverageScore() float64 {
+	if s.GamesPlayed == 0 {
+		return 0
+	}
+	return float64(s.TotalScore) / float64(s.GamesPlayed)
+}
+
+// Accuracy returns the ratio of accepted formulas to all submitted formulas
+func (s UserStats) Accuracy() float64 {
+	total := s.AcceptedFormulas + s.RejectedFormulas
+	if total == 0 {
+		return 0
+	}
+	return float64(s.AcceptedFormulas) / float64(total)
+}
+
+// UserStatsRepository reads the per-user summary updated at game end
+type UserStatsRepository interface {
+	GetUserStats(ctx context.Context, userID int) (UserStats, error)
+}
@@ -0,0 +1,52 @@
+package domain
+
+import "testing"
+
+func TestUserStats_ApplyGameResult(t *testing.T) {
+	var stats UserStats
+	games := []struct {
+		rank         int
+		score        int
+		playerCount  int
+		answerStreak int
+	}{
+		{1, 50, 2, 2},
+		{1, 80, 3, 4},
+		{1, 10, 1, 1}, // 1人プレイは勝敗・連勝に影響しない
+		{1, 60, 2, 3},
+		{2, 20, 2, 1},
+		{1, 30, 2, 2},
+	}
+	for _, g := range games {
+		stats.ApplyGameResult(GamePlayerResult{
+			Score:               g.score,
+			Rank:                g.rank,
+			AcceptedFormulas:    3,
+			RejectedFormulas:    1,
+			LongestAnswerStreak: g.answerStreak,
+		}, g.playerCount)
+	}
+
+	if stats.GamesPlayed != 6 {
+		t.Errorf("GamesPlayed = %d, want 6", stats.GamesPlayed)
+	}
+	if stats.Wins != 4 {
+		t.Errorf("Wins = %d, want 4", stats.Wins)
+	}
+	if stats.LongestStreak != 3 || stats.CurrentStreak != 1 {
+		t.Errorf("LongestStreak = %d, CurrentStreak = %d, want 3 and 1", stats.LongestStreak, stats.CurrentStreak)
+	}
+	// 連勝数とは別に、ゲーム中の連続正解数の最大値を残す
+	if stats.LongestAnswerStreak != 4 {
+		t.Errorf("LongestAnswerStreak = %d, want 4", stats.LongestAnswerStreak)
+	}
+	if stats.BestScore != 80 {
+		t.Errorf("BestScore = %d, want 80", stats.BestScore)
+	}
+	if got := stats.AverageScore(); got != 250.0/6 {
+		t.Errorf("AverageScore() = %v, want %v", got, 250.0/6)
+	}
+	if got := stats.Accuracy(); got != 0.75 {
+		t.Errorf("Accuracy() = %v, want 0.75", got)
+	}
+}
@@ -9,26 +9,36 @@ import (
 
 type JWTService struct {
 	secretKey []byte
+	tokenTTL  time.Duration
 }
 
 type Claims struct {
 	UserID   int32  `json:"user_id"`
 	Username string `json:"username"`
+	// SessionID はトークンを発行したセッション（リフレッシュトークンのfamily_id）。ログアウトで失効する
+	SessionID string `json:"sid,omitempty"`
 	jwt.RegisteredClaims
 }
 
-func NewJWTService(secretKey string) *JWTService {
+func NewJWTService(secretKey string, tokenTTL time.Duration) *JWTService {
 	return &JWTService{
 		secretKey: []byte(secretKey),
+		tokenTTL:  tokenTTL,
 	}
 }
 
-func (j *JWTService) GenerateToken(userID int32, username string) (string, error) {
+// TokenTTL returns how long the access tokens stay valid
+func (j *JWTService) TokenTTL() time.Duration {
+	return j.tokenTTL
+}
+
+func (j *JWTService) GenerateToken(userID int32, username string, sessionID string) (string, error) {
 	claims := &Claims{
-		UserID:   userID,
-		Username: username,
+		UserID:    userID,
+		Username:  username,
+		SessionID: sessionID,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(24 * time.Hour)),
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(j.tokenTTL)),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 		},
 	}
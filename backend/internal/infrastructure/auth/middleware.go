@@ -4,6 +4,7 @@ import (
 	"context"
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/kaitoyama/kaitoyama-server-template/internal/usecase"
 	"github.com/labstack/echo/v4"
@@ -16,44 +17,81 @@ const UserContextKey contextKey = "user"
 type AuthService struct {
 	jwtService  *JWTService
 	userUsecase *usecase.UserUsecase
+	sessions    *SessionService
+	tickets     *TicketStore
 }
 
-func NewAuthService(jwtService *JWTService, userUsecase *usecase.UserUsecase) *AuthService {
+func NewAuthService(jwtService *JWTService, userUsecase *usecase.UserUsecase, sessions *SessionService, tickets *TicketStore) *AuthService {
 	return &AuthService{
 		jwtService:  jwtService,
 		userUsecase: userUsecase,
+		sessions:    sessions,
+		tickets:     tickets,
 	}
 }
 
 func (a *AuthService) AuthMiddleware() echo.MiddlewareFunc {
 	return func(next echo.HandlerFunc) echo.HandlerFunc {
 		return func(c echo.Context) error {
-			// X-Forwarded-Userヘッダーをチェック
-			forwardedUser := c.Request().Header.Get("X-Forwarded-User")
-			if forwardedUser != "" {
-				// X-Forwarded-Userが存在する場合の処理
-				user, err := a.handleForwardedUser(c, forwardedUser)
-				if err != nil {
-					return echo.NewHTTPError(http.StatusInternalServerError, "failed to process forwarded user")
-				}
-
-				// ユーザー情報をコンテキストに保存
-				claims := &Claims{
-					UserID:   int32(user.UserID),
-					Username: user.Username,
-				}
-				ctx := context.WithValue(c.Request().Context(), UserContextKey, claims)
-				c.SetRequest(c.Request().WithContext(ctx))
-
-				return next(c)
+			claims, err := a.Authenticate(c)
+			if err != nil {
+				return err
 			}
 
-			// JWT認証を試行
-			return a.handleJWTAuth(c, next)
+			// ユーザー情報をコンテキストに保存
+			ctx := context.WithValue(c.Request().Context(), UserContextKey, claims)
+			c.SetRequest(c.Request().WithContext(ctx))
+
+			return next(c)
 		}
 	}
 }
 
+// Authenticate identifies the user from X-Forwarded-User or the bearer token.
+// エラーはステータスコード付きの*echo.HTTPError
+func (a *AuthService) Authenticate(c echo.Context) (*Claims, error) {
+	// X-Forwarded-Userヘッダーをチェック
+	if forwardedUser := c.Request().Header.Get("X-Forwarded-User"); forwardedUser != "" {
+		return a.forwardedUserClaims(c, forwardedUser)
+	}
+
+	// JWT認証を試行
+	return a.handleJWTAuth(c)
+}
+
+// AuthenticateWebSocket identifies the user of a WebSocket upgrade request.
+// X-Forwarded-User・チケット（ticketクエリパラメータ）・JWT（Authorizationヘッダー）の順に確認する
+// アクセストークンはURLに載せるとアクセスログに残るため、クエリパラメータでは受け付けない
+func (a *AuthService) AuthenticateWebSocket(c echo.Context) (*Claims, error) {
+	if forwardedUser := c.Request().Header.Get("X-Forwarded-User"); forwardedUser != "" {
+		return a.forwardedUserClaims(c, forwardedUser)
+	}
+	if value := c.QueryParam("ticket"); value != "" {
+		claims, ok := a.tickets.Redeem(value)
+		if !ok {
+			return nil, echo.NewHTTPError(http.StatusUnauthorized, "invalid ticket")
+		}
+		return claims, nil
+	}
+	return a.handleJWTAuth(c)
+}
+
+// IssueWebSocketTicket issues a single-use ticket for the authenticated user's WebSocket upgrade
+func (a *AuthService) IssueWebSocketTicket(claims *Claims) (string, time.Time, error) {
+	return a.tickets.Issue(claims)
+}
+
+func (a *AuthService) forwardedUserClaims(c echo.Context, username string) (*Claims, error) {
+	user, err := a.handleForwardedUser(c, username)
+	if err != nil {
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, "failed to process forwarded user")
+	}
+	return &Claims{
+		UserID:   int32(user.UserID),
+		Username: user.Username,
+	}, nil
+}
+
 func (a *AuthService) handleForwardedUser(c echo.Context, username string) (*usecase.CreateUserResponse, error) {
 	// まず既存ユーザーを検索
 	existingUser, err := a.userUsecase.GetUserByUsername(c.Request().Context(), username)
@@ -74,29 +112,39 @@ func (a *AuthService) handleForwardedUser(c echo.Context, username string) (*use
 	return a.userUsecase.CreateUserWithoutPassword(c.Request().Context(), createReq)
 }
 
-func (a *AuthService) handleJWTAuth(c echo.Context, next echo.HandlerFunc) error {
+func (a *AuthService) handleJWTAuth(c echo.Context) (*Claims, error) {
 	authHeader := c.Request().Header.Get("Authorization")
 	if authHeader == "" {
-		return echo.NewHTTPError(http.StatusUnauthorized, "missing authorization header")
+		return nil, echo.NewHTTPError(http.StatusUnauthorized, "missing authorization header")
 	}
 
 	// Bearer tokenの形式チェック
 	parts := strings.Split(authHeader, " ")
 	if len(parts) != 2 || parts[0] != "Bearer" {
-		return echo.NewHTTPError(http.StatusUnauthorized, "invalid authorization header format")
+		return nil, echo.NewHTTPError(http.StatusUnauthorized, "invalid authorization header format")
 	}
 
-	token := parts[1]
+	return a.validateAccessToken(c, parts[1])
+}
+
+func (a *AuthService) validateAccessToken(c echo.Context, token string) (*Claims, error) {
 	claims, err := a.jwtService.ValidateToken(token)
 	if err != nil {
-		return echo.NewHTTPError(http.StatusUnauthorized, "invalid token")
+		return nil, echo.NewHTTPError(http.StatusUnauthorized, "invalid token")
 	}
 
-	// ユーザー情報をコンテキストに保存
-	ctx := context.WithValue(c.Request().Context(), UserContextKey, claims)
-	c.SetRequest(c.Request().WithContext(ctx))
+	// ログアウト・リフレッシュトークンの再利用で失効したセッションのトークンは有効期限内でも拒否
+	if claims.SessionID != "" {
+		revoked, err := a.sessions.IsRevoked(c.Request().Context(), claims.SessionID)
+		if err != nil {
+			return nil, echo.NewHTTPError(http.StatusInternalServerError, "failed to check token")
+		}
+		if revoked {
+			return nil, echo.NewHTTPError(http.StatusUnauthorized, "token revoked")
+		}
+	}
 
-	return next(c)
+	return claims, nil
 }
 
 // ユーザー情報を取得するヘルパー関数
@@ -0,0 +1,92 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	dbInfra "github.com/kaitoyama/kaitoyama-server-template/internal/infrastructure/db"
+	"github.com/kaitoyama/kaitoyama-server-template/internal/usecase"
+	"github.com/labstack/echo/v4"
+)
+
+func TestAuthService_AuthenticateWebSocket(t *testing.T) {
+	ctx := context.Background()
+	sessions, userID, database := newTestAuth(t, time.Hour)
+	userUsecase := usecase.NewUserUsecase(database.Queries(database), dbInfra.NewUserStatsRepository(database), time.Hour)
+	authService := NewAuthService(sessions.jwtService, userUsecase, sessions, NewTicketStore(time.Minute))
+
+	tokens, err := sessions.StartSession(ctx, userID, "alice")
+	if err != nil {
+		t.Fatalf("StartSession() error = %v", err)
+	}
+	ticket, _, err := authService.IssueWebSocketTicket(&Claims{UserID: userID, Username: "alice"})
+	if err != nil {
+		t.Fatalf("IssueWebSocketTicket() error = %v", err)
+	}
+
+	authenticate := func(target string, header http.Header) (*Claims, error) {
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		for key, values := range header {
+			req.Header[key] = values
+		}
+		return authService.AuthenticateWebSocket(echo.New().NewContext(req, httptest.NewRecorder()))
+	}
+
+	tests := []struct {
+		name       string
+		target     string
+		header     http.Header
+		wantUser   string
+		wantStatus int
+	}{
+		{name: "ticket", target: "/api/ws?ticket=" + ticket, wantUser: "alice"},
+		{name: "used ticket", target: "/api/ws?ticket=" + ticket, wantStatus: http.StatusUnauthorized},
+		{name: "token query", target: "/api/ws?token=" + tokens.AccessToken, wantStatus: http.StatusUnauthorized}, // URLのトークンはログに残るため受け付けない
+		{name: "authorization header", target: "/api/ws", header: http.Header{"Authorization": {"Bearer " + tokens.AccessToken}}, wantUser: "alice"},
+		{name: "forwarded user", target: "/api/ws", header: http.Header{"X-Forwarded-User": {"bob"}}, wantUser: "bob"},
+		{name: "username only", target: "/api/ws?username=alice", wantStatus: http.StatusUnauthorized},
+		{name: "invalid token", target: "/api/ws", header: http.Header{"Authorization": {"Bearer invalid"}}, wantStatus: http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims, err := authenticate(tt.target, tt.header)
+			if tt.wantStatus != 0 {
+				var httpErr *echo.HTTPError
+				if !errors.As(err, &httpErr) || httpErr.Code != tt.wantStatus {
+					t.Fatalf("AuthenticateWebSocket() error = %v, want status %d", err, tt.wantStatus)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("AuthenticateWebSocket() error = %v", err)
+			}
+			if claims.Username != tt.wantUser {
+				t.Errorf("username = %q, want %q", claims.Username, tt.wantUser)
+			}
+		})
+	}
+
+	// ログアウトしたセッションのアクセストークンは有効期限内でも拒否
+	if err := sessions.Logout(ctx, tokens.RefreshToken); err != nil {
+		t.Fatalf("Logout() error = %v", err)
+	}
+	if _, err := authenticate("/api/ws", http.Header{"Authorization": {"Bearer " + tokens.AccessToken}}); err == nil {
+		t.Error("AuthenticateWebSocket(revoked token) error = nil, want error")
+	}
+}
+
+func TestTicketStore_Expired(t *testing.T) {
+	store := NewTicketStore(time.Millisecond)
+	ticket, _, err := store.Issue(&Claims{UserID: 1, Username: "alice"})
+	if err != nil {
+		t.Fatalf("Issue() error = %v", err)
+	}
+	time.Sleep(5 * time.Millisecond)
+	if _, ok := store.Redeem(ticket); ok {
+		t.Error("Redeem(expired ticket) = true, want false")
+	}
+}
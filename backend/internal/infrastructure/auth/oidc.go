@@ -0,0 +1,339 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/kaitoyama/kaitoyama-server-template/internal/usecase"
+)
+
+var (
+	// ErrUnknownOIDCProvider is returned for a provider name that is not configured
+	ErrUnknownOIDCProvider = errors.New("unknown OIDC provider")
+	// ErrInvalidOIDCState is returned for an unknown, used or expired state
+	ErrInvalidOIDCState = errors.New("invalid OIDC state")
+	// ErrOIDCAuthentication is returned when the provider rejects the code or the ID token is invalid
+	ErrOIDCAuthentication = errors.New("OIDC authentication failed")
+)
+
+// OIDCProviderConfig is an external OAuth2/OIDC provider used for login
+type OIDCProviderConfig struct {
+	Name           string
+	Issuer         string
+	ClientID       string
+	ClientSecret   string
+	RedirectURL    string
+	Scopes         []string
+	UsernameClaim  string
+	LinkByUsername bool
+}
+
+// idTokenAlgorithms はIDトークンの署名として受け付けるアルゴリズム（noneやクライアントシークレットのHMACは受け付けない）
+var idTokenAlgorithms = []string{"RS256", "RS384", "RS512", "ES256", "ES384", "ES512"}
+
+// OIDCService runs the authorization code flow with PKCE against the configured providers.
+// 認可リクエストのstate・nonce・code_verifierはこのインスタンスのメモリにだけ保存する
+type OIDCService struct {
+	providers map[string]*OIDCProvider
+	stateTTL  time.Duration
+
+	mutex   sync.Mutex
+	pending map[string]pendingLogin
+}
+
+// pendingLogin はコールバックを待っている認可リクエスト
+type pendingLogin struct {
+	provider     string
+	codeVerifier string
+	nonce        string
+	expiresAt    time.Time
+}
+
+func NewOIDCService(configs []OIDCProviderConfig) *OIDCService {
+	client := &http.Client{Timeout: 10 * time.Second}
+	providers := make(map[string]*OIDCProvider, len(configs))
+	for _, config := range configs {
+		providers[config.Name] = newOIDCProvider(config, client)
+	}
+	return &OIDCService{
+		providers: providers,
+		stateTTL:  10 * time.Minute,
+		pending:   make(map[string]pendingLogin),
+	}
+}
+
+// Providers returns the names of the configured providers
+func (s *OIDCService) Providers() []string {
+	names := make([]string, 0, len(s.providers))
+	for name := range s.providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// AuthorizationURL starts a login and returns the provider's authorization URL and its state.
+// クライアントはstateを保存しておき、コールバックで受け取ったstateと一致することを確かめてからcodeを送る
+func (s *OIDCService) AuthorizationURL(ctx context.Context, providerName string) (string, string, error) {
+	provider, ok := s.providers[providerName]
+	if !ok {
+		return "", "", ErrUnknownOIDCProvider
+	}
+	metadata, err := provider.discover(ctx)
+	if err != nil {
+		return "", "", err
+	}
+
+	state, err := randomToken()
+	if err != nil {
+		return "", "", err
+	}
+	nonce, err := randomToken()
+	if err != nil {
+		return "", "", err
+	}
+	codeVerifier, err := randomToken()
+	if err != nil {
+		return "", "", err
+	}
+
+	now := time.Now()
+	s.mutex.Lock()
+	// 使われずに期限切れになったリクエストを発行のついでに削除
+	for key, p := range s.pending {
+		if !now.Before(p.expiresAt) {
+			delete(s.pending, key)
+		}
+	}
+	s.pending[state] = pendingLogin{
+		provider:     providerName,
+		codeVerifier: codeVerifier,
+		nonce:        nonce,
+		expiresAt:    now.Add(s.stateTTL),
+	}
+	s.mutex.Unlock()
+
+	challenge := sha256.Sum256([]byte(codeVerifier))
+	query := url.Values{
+		"response_type":         {"code"},
+		"client_id":             {provider.config.ClientID},
+		"redirect_uri":          {provider.config.RedirectURL},
+		"scope":                 {strings.Join(provider.config.Scopes, " ")},
+		"state":                 {state},
+		"nonce":                 {nonce},
+		"code_challenge":        {base64.RawURLEncoding.EncodeToString(challenge[:])},
+		"code_challenge_method": {"S256"},
+	}
+	separator := "?"
+	if strings.Contains(metadata.AuthorizationEndpoint, "?") {
+		separator = "&"
+	}
+	return metadata.AuthorizationEndpoint + separator + query.Encode(), state, nil
+}
+
+// Complete exchanges the authorization code and returns the account from the verified ID token.
+// stateは1回だけ使え、AuthorizationURLを呼んだプロバイダーのものでなければならない
+func (s *OIDCService) Complete(ctx context.Context, providerName, code, state string) (*usecase.ExternalIdentity, error) {
+	provider, ok := s.providers[providerName]
+	if !ok {
+		return nil, ErrUnknownOIDCProvider
+	}
+
+	s.mutex.Lock()
+	login, exists := s.pending[state]
+	delete(s.pending, state)
+	s.mutex.Unlock()
+	if !exists || login.provider != providerName || !time.Now().Before(login.expiresAt) {
+		return nil, ErrInvalidOIDCState
+	}
+
+	rawIDToken, err := provider.exchange(ctx, code, login.codeVerifier)
+	if err != nil {
+		return nil, err
+	}
+	return provider.verifyIDToken(ctx, rawIDToken, login.nonce)
+}
+
+// OIDCProvider is one OpenID Connect provider.
+// エンドポイントは初回の利用時にディスカバリーで取得する（起動時にプロバイダーへ接続できなくてもよい）
+type OIDCProvider struct {
+	config OIDCProviderConfig
+	client *http.Client
+
+	mutex    sync.Mutex
+	metadata *oidcMetadata
+	keys     *jwksCache
+}
+
+// oidcMetadata は/.well-known/openid-configurationのうち使う項目
+type oidcMetadata struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+func newOIDCProvider(config OIDCProviderConfig, client *http.Client) *OIDCProvider {
+	return &OIDCProvider{
+		config: config,
+		client: client,
+	}
+}
+
+func (p *OIDCProvider) discover(ctx context.Context) (*oidcMetadata, error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	if p.metadata != nil {
+		return p.metadata, nil
+	}
+
+	discoveryURL := strings.TrimSuffix(p.config.Issuer, "/") + "/.well-known/openid-configuration"
+	var metadata oidcMetadata
+	if err := getJSON(ctx, p.client, discoveryURL, &metadata); err != nil {
+		return nil, fmt.Errorf("failed to discover OIDC provider %s: %w", p.config.Name, err)
+	}
+	// なりすましたディスカバリー文書でIssuerをすり替えられないよう、設定したIssuerと一致することを確かめる
+	if metadata.Issuer != p.config.Issuer {
+		return nil, fmt.Errorf("OIDC provider %s: issuer %q does not match the configured issuer %q", p.config.Name, metadata.Issuer, p.config.Issuer)
+	}
+	if metadata.AuthorizationEndpoint == "" || metadata.TokenEndpoint == "" || metadata.JWKSURI == "" {
+		return nil, fmt.Errorf("OIDC provider %s: discovery document is missing endpoints", p.config.Name)
+	}
+
+	p.metadata = &metadata
+	p.keys = newJWKSCache(metadata.JWKSURI, p.client)
+	return p.metadata, nil
+}
+
+// exchange はトークンエンドポイントで認可コードをIDトークンに交換する
+func (p *OIDCProvider) exchange(ctx context.Context, code, codeVerifier string) (string, error) {
+	metadata, err := p.discover(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {p.config.RedirectURL},
+		"client_id":     {p.config.ClientID},
+		"code_verifier": {codeVerifier},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, metadata.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if p.config.ClientSecret != "" {
+		req.SetBasicAuth(url.QueryEscape(p.config.ClientID), url.QueryEscape(p.config.ClientSecret))
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to exchange code with OIDC provider %s: %w", p.config.Name, err)
+	}
+	defer resp.Body.Close()
+
+	var body struct {
+		IDToken          string `json:"id_token"`
+		Error            string `json:"error"`
+		ErrorDescription string `json:"error_description"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&body); err != nil {
+		return "", fmt.Errorf("failed to decode token response of OIDC provider %s: %w", p.config.Name, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		// 不正・使用済み・期限切れのコードはinvalid_grant
+		return "", fmt.Errorf("%w: token endpoint returned %d %s %s", ErrOIDCAuthentication, resp.StatusCode, body.Error, body.ErrorDescription)
+	}
+	if body.IDToken == "" {
+		return "", fmt.Errorf("%w: token response has no id_token (is the openid scope requested?)", ErrOIDCAuthentication)
+	}
+	return body.IDToken, nil
+}
+
+// verifyIDToken はIDトークンの署名・iss・aud・exp・nonceを検証してアカウントを返す
+func (p *OIDCProvider) verifyIDToken(ctx context.Context, rawIDToken, nonce string) (*usecase.ExternalIdentity, error) {
+	metadata, err := p.discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	claims := jwt.MapClaims{}
+	_, err = jwt.ParseWithClaims(rawIDToken, claims, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		return p.keys.key(ctx, kid)
+	},
+		jwt.WithValidMethods(idTokenAlgorithms),
+		jwt.WithIssuer(metadata.Issuer),
+		jwt.WithAudience(p.config.ClientID),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+		jwt.WithLeeway(30*time.Second),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid ID token: %w", ErrOIDCAuthentication, err)
+	}
+
+	if tokenNonce, _ := claims["nonce"].(string); tokenNonce != nonce {
+		return nil, fmt.Errorf("%w: ID token nonce does not match", ErrOIDCAuthentication)
+	}
+	// 複数のaudienceを持つトークンは、このクライアントに発行されたもの（azp）でなければならない
+	if audience, _ := claims.GetAudience(); len(audience) > 1 {
+		if azp, _ := claims["azp"].(string); azp != p.config.ClientID {
+			return nil, fmt.Errorf("%w: ID token was issued to another client", ErrOIDCAuthentication)
+		}
+	}
+	subject, _ := claims.GetSubject()
+	if subject == "" {
+		return nil, fmt.Errorf("%w: ID token has no sub", ErrOIDCAuthentication)
+	}
+	username, _ := claims[p.config.UsernameClaim].(string)
+
+	return &usecase.ExternalIdentity{
+		Provider:       p.config.Name,
+		Subject:        subject,
+		Username:       username,
+		LinkByUsername: p.config.LinkByUsername,
+	}, nil
+}
+
+func getJSON(ctx context.Context, client *http.Client, url string, v interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("GET %s returned %d", url, resp.StatusCode)
+	}
+	return json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(v)
+}
+
+// randomToken はstate・nonce・code_verifierに使う256ビットのランダムな文字列（RFC 7636の43文字）
+func randomToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate random token: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
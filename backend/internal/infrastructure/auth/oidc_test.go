@@ -0,0 +1,318 @@
+package auth
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// stubOIDCServer はディスカバリー・トークン・JWKSのエンドポイントだけを持つOIDCプロバイダーのスタブ
+// 認可エンドポイントの代わりに authorize でコードを発行する
+type stubOIDCServer struct {
+	*httptest.Server
+	t *testing.T
+
+	mutex       sync.Mutex
+	keyID       string
+	key         interface{} // *rsa.PrivateKey または *ecdsa.PrivateKey
+	method      jwt.SigningMethod
+	codes       map[string]stubAuthorization
+	jwksFetches int
+	// claims はIDトークンのクレームを書き換える（不正なトークンのテスト用）
+	claims func(jwt.MapClaims)
+}
+
+type stubAuthorization struct {
+	challenge string
+	nonce     string
+	subject   string
+}
+
+func newStubOIDCServer(t *testing.T) *stubOIDCServer {
+	t.Helper()
+	s := &stubOIDCServer{t: t, codes: make(map[string]stubAuthorization)}
+	s.rotateRSAKey("key-1")
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{
+			"issuer":                 s.URL,
+			"authorization_endpoint": s.URL + "/authorize",
+			"token_endpoint":         s.URL + "/token",
+			"jwks_uri":               s.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		s.mutex.Lock()
+		defer s.mutex.Unlock()
+		s.jwksFetches++
+		json.NewEncoder(w).Encode(map[string]interface{}{"keys": []interface{}{s.publicJWK()}})
+	})
+	mux.HandleFunc("/token", s.handleToken)
+	s.Server = httptest.NewServer(mux)
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *stubOIDCServer) rotateRSAKey(keyID string) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		s.t.Fatalf("GenerateKey() error = %v", err)
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.keyID, s.key, s.method = keyID, key, jwt.SigningMethodRS256
+}
+
+func (s *stubOIDCServer) rotateECKey(keyID string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		s.t.Fatalf("GenerateKey() error = %v", err)
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.keyID, s.key, s.method = keyID, key, jwt.SigningMethodES256
+}
+
+func (s *stubOIDCServer) publicJWK() map[string]string {
+	encode := func(b []byte) string { return base64.RawURLEncoding.EncodeToString(b) }
+	switch key := s.key.(type) {
+	case *rsa.PrivateKey:
+		return map[string]string{
+			"kty": "RSA", "kid": s.keyID, "use": "sig",
+			"n": encode(key.N.Bytes()),
+			"e": encode(big.NewInt(int64(key.E)).Bytes()),
+		}
+	case *ecdsa.PrivateKey:
+		return map[string]string{
+			"kty": "EC", "kid": s.keyID, "crv": "P-256",
+			"x": encode(key.X.FillBytes(make([]byte, 32))),
+			"y": encode(key.Y.FillBytes(make([]byte, 32))),
+		}
+	}
+	return nil
+}
+
+// authorize はユーザーが認可エンドポイントで同意した後のリダイレクトで返るcodeとstate
+func (s *stubOIDCServer) authorize(authorizationURL, subject string) (string, string) {
+	s.t.Helper()
+	u, err := url.Parse(authorizationURL)
+	if err != nil {
+		s.t.Fatalf("url.Parse() error = %v", err)
+	}
+	query := u.Query()
+	if query.Get("code_challenge_method") != "S256" || query.Get("client_id") != "game" {
+		s.t.Fatalf("authorization URL = %s, want S256 PKCE for client game", authorizationURL)
+	}
+
+	code, err := randomToken()
+	if err != nil {
+		s.t.Fatal(err)
+	}
+	s.mutex.Lock()
+	s.codes[code] = stubAuthorization{
+		challenge: query.Get("code_challenge"),
+		nonce:     query.Get("nonce"),
+		subject:   subject,
+	}
+	s.mutex.Unlock()
+	return code, query.Get("state")
+}
+
+func (s *stubOIDCServer) handleToken(w http.ResponseWriter, r *http.Request) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	clientID, secret, ok := r.BasicAuth()
+	if !ok || clientID != "game" || secret != "secret" {
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(map[string]string{"error": "invalid_client"})
+		return
+	}
+	authorization, exists := s.codes[r.PostFormValue("code")]
+	delete(s.codes, r.PostFormValue("code"))
+	verifier := sha256.Sum256([]byte(r.PostFormValue("code_verifier")))
+	if !exists || base64.RawURLEncoding.EncodeToString(verifier[:]) != authorization.challenge {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": "invalid_grant"})
+		return
+	}
+
+	now := time.Now()
+	claims := jwt.MapClaims{
+		"iss":                s.URL,
+		"aud":                "game",
+		"sub":                authorization.subject,
+		"iat":                now.Unix(),
+		"exp":                now.Add(time.Hour).Unix(),
+		"nonce":              authorization.nonce,
+		"preferred_username": "alice",
+	}
+	if s.claims != nil {
+		s.claims(claims)
+	}
+	token := jwt.NewWithClaims(s.method, claims)
+	token.Header["kid"] = s.keyID
+	idToken, err := token.SignedString(s.key)
+	if err != nil {
+		s.t.Errorf("SignedString() error = %v", err)
+	}
+	json.NewEncoder(w).Encode(map[string]string{"access_token": "at", "token_type": "Bearer", "id_token": idToken})
+}
+
+func newTestOIDCService(stub *stubOIDCServer) *OIDCService {
+	return NewOIDCService([]OIDCProviderConfig{{
+		Name:          "stub",
+		Issuer:        stub.URL,
+		ClientID:      "game",
+		ClientSecret:  "secret",
+		RedirectURL:   "http://localhost:5173/auth/callback",
+		Scopes:        []string{"openid", "profile"},
+		UsernameClaim: "preferred_username",
+	}})
+}
+
+// login は認可URLの発行からIDトークンの検証までを行う
+func login(t *testing.T, service *OIDCService, stub *stubOIDCServer, subject string) error {
+	t.Helper()
+	authorizationURL, state, err := service.AuthorizationURL(context.Background(), "stub")
+	if err != nil {
+		t.Fatalf("AuthorizationURL() error = %v", err)
+	}
+	code, returnedState := stub.authorize(authorizationURL, subject)
+	if returnedState != state {
+		t.Fatalf("state = %q, want %q", returnedState, state)
+	}
+	_, err = service.Complete(context.Background(), "stub", code, state)
+	return err
+}
+
+func TestOIDCService_Complete(t *testing.T) {
+	ctx := context.Background()
+	stub := newStubOIDCServer(t)
+	service := newTestOIDCService(stub)
+
+	if got := service.Providers(); len(got) != 1 || got[0] != "stub" {
+		t.Errorf("Providers() = %v, want [stub]", got)
+	}
+	if _, _, err := service.AuthorizationURL(ctx, "unknown"); !errors.Is(err, ErrUnknownOIDCProvider) {
+		t.Errorf("AuthorizationURL(unknown) error = %v, want ErrUnknownOIDCProvider", err)
+	}
+
+	authorizationURL, state, err := service.AuthorizationURL(ctx, "stub")
+	if err != nil {
+		t.Fatalf("AuthorizationURL() error = %v", err)
+	}
+	if !strings.HasPrefix(authorizationURL, stub.URL+"/authorize?") {
+		t.Errorf("AuthorizationURL() = %s, want the stub's authorization endpoint", authorizationURL)
+	}
+	code, _ := stub.authorize(authorizationURL, "user-1")
+
+	identity, err := service.Complete(ctx, "stub", code, state)
+	if err != nil {
+		t.Fatalf("Complete() error = %v", err)
+	}
+	if identity.Provider != "stub" || identity.Subject != "user-1" || identity.Username != "alice" {
+		t.Errorf("Complete() = %+v, want stub/user-1 named alice", identity)
+	}
+
+	// stateは1回だけ使える
+	if _, err := service.Complete(ctx, "stub", code, state); !errors.Is(err, ErrInvalidOIDCState) {
+		t.Errorf("Complete(used state) error = %v, want ErrInvalidOIDCState", err)
+	}
+	if _, err := service.Complete(ctx, "stub", code, "unknown"); !errors.Is(err, ErrInvalidOIDCState) {
+		t.Errorf("Complete(unknown state) error = %v, want ErrInvalidOIDCState", err)
+	}
+
+	// 別のcode_verifierで発行されたコード（横取りされたコード）は交換できない
+	authorizationURL, state, err = service.AuthorizationURL(ctx, "stub")
+	if err != nil {
+		t.Fatalf("AuthorizationURL() error = %v", err)
+	}
+	code, _ = stub.authorize(strings.Replace(authorizationURL, "code_challenge=", "code_challenge=x", 1), "user-1")
+	if _, err := service.Complete(ctx, "stub", code, state); !errors.Is(err, ErrOIDCAuthentication) {
+		t.Errorf("Complete(wrong verifier) error = %v, want ErrOIDCAuthentication", err)
+	}
+}
+
+func TestOIDCService_InvalidIDToken(t *testing.T) {
+	tests := []struct {
+		name   string
+		claims func(jwt.MapClaims)
+	}{
+		{name: "nonce", claims: func(c jwt.MapClaims) { c["nonce"] = "other" }},
+		{name: "audience", claims: func(c jwt.MapClaims) { c["aud"] = "other-client" }},
+		{name: "issuer", claims: func(c jwt.MapClaims) { c["iss"] = "https://evil.example.com" }},
+		{name: "expired", claims: func(c jwt.MapClaims) { c["exp"] = time.Now().Add(-time.Hour).Unix() }},
+		{name: "no subject", claims: func(c jwt.MapClaims) { delete(c, "sub") }},
+		{name: "other azp", claims: func(c jwt.MapClaims) {
+			c["aud"] = []string{"game", "other-client"}
+			c["azp"] = "other-client"
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stub := newStubOIDCServer(t)
+			stub.claims = tt.claims
+			if err := login(t, newTestOIDCService(stub), stub, "user-1"); !errors.Is(err, ErrOIDCAuthentication) {
+				t.Errorf("Complete() error = %v, want ErrOIDCAuthentication", err)
+			}
+		})
+	}
+
+	t.Run("signed with the client secret", func(t *testing.T) {
+		stub := newStubOIDCServer(t)
+		stub.key, stub.method = []byte("secret"), jwt.SigningMethodHS256
+		if err := login(t, newTestOIDCService(stub), stub, "user-1"); !errors.Is(err, ErrOIDCAuthentication) {
+			t.Errorf("Complete() error = %v, want ErrOIDCAuthentication", err)
+		}
+	})
+}
+
+func TestOIDCService_KeyRotation(t *testing.T) {
+	stub := newStubOIDCServer(t)
+	service := newTestOIDCService(stub)
+
+	if err := login(t, service, stub, "user-1"); err != nil {
+		t.Fatalf("login() error = %v", err)
+	}
+	if err := login(t, service, stub, "user-1"); err != nil {
+		t.Fatalf("login() error = %v", err)
+	}
+	if stub.jwksFetches != 1 {
+		t.Errorf("JWKS fetches = %d, want 1 (cached)", stub.jwksFetches)
+	}
+
+	// 直前に取得したばかりの場合は、未知のkidでも取得し直さない
+	stub.rotateECKey("key-2")
+	if err := login(t, service, stub, "user-1"); !errors.Is(err, ErrOIDCAuthentication) {
+		t.Errorf("login(unknown kid within refresh interval) error = %v, want ErrOIDCAuthentication", err)
+	}
+	if stub.jwksFetches != 1 {
+		t.Errorf("JWKS fetches = %d, want 1 (rate limited)", stub.jwksFetches)
+	}
+
+	service.providers["stub"].keys.minRefreshInterval = 0
+	if err := login(t, service, stub, "user-1"); err != nil {
+		t.Fatalf("login(rotated key) error = %v", err)
+	}
+	if stub.jwksFetches != 2 {
+		t.Errorf("JWKS fetches = %d, want 2 (refetched for the new kid)", stub.jwksFetches)
+	}
+}
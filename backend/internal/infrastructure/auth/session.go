@@ -0,0 +1,224 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kaitoyama/kaitoyama-server-template/internal/db"
+	"github.com/rs/zerolog/log"
+)
+
+var (
+	// ErrInvalidRefreshToken is returned for an unknown, expired or revoked refresh token
+	ErrInvalidRefreshToken = errors.New("invalid refresh token")
+	// ErrRefreshTokenReused is returned when an already rotated refresh token is presented again.
+	// 盗まれたトークンが使われた可能性があるため、そのセッションのトークンをすべて失効させる
+	ErrRefreshTokenReused = errors.New("refresh token reused")
+)
+
+// TokenPair is an access token together with the refresh token that renews it
+type TokenPair struct {
+	AccessToken  string
+	RefreshToken string
+	ExpiresIn    time.Duration // アクセストークンの有効期間
+	UserID       int32
+	Username     string
+}
+
+// SessionService issues access tokens with rotating refresh tokens.
+// リフレッシュトークンはログインごとのセッション（family）に属し、使うたびに同じセッションの新しいトークンに置き換わる
+// DBにはトークンのSHA-256だけを保存する
+type SessionService struct {
+	jwtService      *JWTService
+	querier         db.Querier
+	refreshTokenTTL time.Duration
+}
+
+func NewSessionService(jwtService *JWTService, querier db.Querier, refreshTokenTTL time.Duration) *SessionService {
+	return &SessionService{
+		jwtService:      jwtService,
+		querier:         querier,
+		refreshTokenTTL: refreshTokenTTL,
+	}
+}
+
+// StartSession starts a new session for a user who has just logged in
+func (s *SessionService) StartSession(ctx context.Context, userID int32, username string) (*TokenPair, error) {
+	return s.issue(ctx, userID, username, uuid.NewString())
+}
+
+// Refresh exchanges a refresh token for a new token pair in the same session.
+// 使用済みのトークンが再び使われた場合はセッションを失効させてErrRefreshTokenReusedを返す
+func (s *SessionService) Refresh(ctx context.Context, refreshToken string) (*TokenPair, error) {
+	row, err := s.querier.GetRefreshTokenByHash(ctx, hashRefreshToken(refreshToken))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrInvalidRefreshToken
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get refresh token: %w", err)
+	}
+
+	now := time.Now()
+	if row.RevokedAt.Valid {
+		return nil, ErrInvalidRefreshToken
+	}
+	if row.UsedAt.Valid {
+		return nil, s.revokeReused(ctx, row)
+	}
+	if !now.Before(row.ExpiresAt) {
+		return nil, ErrInvalidRefreshToken
+	}
+
+	// 同じトークンで同時にリフレッシュされた場合は、先に使用済みにした方だけが成功する
+	res, err := s.querier.MarkRefreshTokenUsed(ctx, db.MarkRefreshTokenUsedParams{
+		UsedAt: sql.NullTime{Time: now, Valid: true},
+		ID:     row.ID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to mark refresh token as used: %w", err)
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return nil, err
+	}
+	if affected == 0 {
+		return nil, s.revokeReused(ctx, row)
+	}
+
+	user, err := s.querier.GetUser(ctx, row.UserID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrInvalidRefreshToken
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user %d: %w", row.UserID, err)
+	}
+	return s.issue(ctx, user.ID, user.Username, row.FamilyID)
+}
+
+// Logout revokes the session of the refresh token.
+// 未知・失効済みのトークンでもエラーにしない
+func (s *SessionService) Logout(ctx context.Context, refreshToken string) error {
+	row, err := s.querier.GetRefreshTokenByHash(ctx, hashRefreshToken(refreshToken))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get refresh token: %w", err)
+	}
+	return s.revoke(ctx, row.FamilyID)
+}
+
+// EndSession revokes the session of an access token (its sid claim)
+func (s *SessionService) EndSession(ctx context.Context, sessionID string) error {
+	return s.revoke(ctx, sessionID)
+}
+
+// IsRevoked reports whether the session has been logged out or revoked because of token reuse
+func (s *SessionService) IsRevoked(ctx context.Context, sessionID string) (bool, error) {
+	count, err := s.querier.CountRevokedRefreshTokens(ctx, sessionID)
+	if err != nil {
+		return false, fmt.Errorf("failed to check session %s: %w", sessionID, err)
+	}
+	return count > 0, nil
+}
+
+// DeleteExpired deletes the refresh tokens that have expired.
+// 期限切れのトークンはもう使えないため、再利用の検出にも不要
+func (s *SessionService) DeleteExpired(ctx context.Context) error {
+	if err := s.querier.DeleteExpiredRefreshTokens(ctx, time.Now()); err != nil {
+		return fmt.Errorf("failed to delete expired refresh tokens: %w", err)
+	}
+	return nil
+}
+
+// StartCleanup deletes the expired refresh tokens every interval until ctx is done
+func (s *SessionService) StartCleanup(ctx context.Context, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := s.DeleteExpired(ctx); err != nil {
+					log.Error().Err(err).Msg("Failed to clean up refresh tokens")
+				}
+			}
+		}
+	}()
+}
+
+func (s *SessionService) issue(ctx context.Context, userID int32, username string, sessionID string) (*TokenPair, error) {
+	refreshToken, err := newRefreshToken()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	err = s.querier.CreateRefreshToken(ctx, db.CreateRefreshTokenParams{
+		UserID:    userID,
+		FamilyID:  sessionID,
+		TokenHash: hashRefreshToken(refreshToken),
+		ExpiresAt: now.Add(s.refreshTokenTTL),
+		CreatedAt: now,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create refresh token: %w", err)
+	}
+
+	accessToken, err := s.jwtService.GenerateToken(userID, username, sessionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate access token: %w", err)
+	}
+
+	return &TokenPair{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		ExpiresIn:    s.jwtService.TokenTTL(),
+		UserID:       userID,
+		Username:     username,
+	}, nil
+}
+
+func (s *SessionService) revokeReused(ctx context.Context, row db.RefreshToken) error {
+	log.Warn().Int32("user_id", row.UserID).Str("session_id", row.FamilyID).Msg("Refresh token reuse detected, revoking session")
+	if err := s.revoke(ctx, row.FamilyID); err != nil {
+		return err
+	}
+	return ErrRefreshTokenReused
+}
+
+func (s *SessionService) revoke(ctx context.Context, sessionID string) error {
+	err := s.querier.RevokeRefreshTokenFamily(ctx, db.RevokeRefreshTokenFamilyParams{
+		RevokedAt: sql.NullTime{Time: time.Now(), Valid: true},
+		FamilyID:  sessionID,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to revoke session %s: %w", sessionID, err)
+	}
+	return nil
+}
+
+// newRefreshToken は推測できない256ビットのランダムなトークンを返す
+func newRefreshToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate refresh token: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// hashRefreshToken はトークンのSHA-256。トークンは十分なエントロピーを持つため、ソルトやストレッチングは不要
+func hashRefreshToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
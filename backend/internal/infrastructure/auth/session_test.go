@@ -0,0 +1,138 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	dbInfra "github.com/kaitoyama/kaitoyama-server-template/internal/infrastructure/db"
+)
+
+func newTestSessionService(t *testing.T, refreshTokenTTL time.Duration) (*SessionService, int32) {
+	t.Helper()
+	sessions, userID, _ := newTestAuth(t, refreshTokenTTL)
+	return sessions, userID
+}
+
+// newTestAuth はマイグレーションを適用したインメモリのSQLiteとユーザー"alice"を用意する
+func newTestAuth(t *testing.T, refreshTokenTTL time.Duration) (*SessionService, int32, *dbInfra.Database) {
+	t.Helper()
+	ctx := context.Background()
+	database, err := dbInfra.OpenSQLite(":memory:")
+	if err != nil {
+		t.Fatalf("OpenSQLite() error = %v", err)
+	}
+	t.Cleanup(func() { database.Close() })
+
+	migrator, err := dbInfra.NewMigrator(database, os.DirFS("../../../db/sqlite/migrations"))
+	if err != nil {
+		t.Fatalf("NewMigrator() error = %v", err)
+	}
+	if _, err := migrator.Migrate(ctx, false); err != nil {
+		t.Fatalf("Migrate() error = %v", err)
+	}
+
+	queries := database.Queries(database)
+	res, err := queries.CreateUser(ctx, "alice")
+	if err != nil {
+		t.Fatalf("CreateUser() error = %v", err)
+	}
+	userID, _ := res.LastInsertId()
+
+	jwtService := NewJWTService("test-secret", time.Minute)
+	return NewSessionService(jwtService, queries, refreshTokenTTL), int32(userID), database
+}
+
+func TestSessionService_Refresh(t *testing.T) {
+	ctx := context.Background()
+	sessions, userID := newTestSessionService(t, time.Hour)
+
+	first, err := sessions.StartSession(ctx, userID, "alice")
+	if err != nil {
+		t.Fatalf("StartSession() error = %v", err)
+	}
+	claims, err := sessions.jwtService.ValidateToken(first.AccessToken)
+	if err != nil || claims.SessionID == "" || claims.UserID != userID {
+		t.Fatalf("ValidateToken() = %+v, %v, want claims with a session ID", claims, err)
+	}
+
+	second, err := sessions.Refresh(ctx, first.RefreshToken)
+	if err != nil {
+		t.Fatalf("Refresh() error = %v", err)
+	}
+	if second.RefreshToken == first.RefreshToken || second.Username != "alice" {
+		t.Errorf("Refresh() = %+v, want a rotated token for alice", second)
+	}
+	secondClaims, _ := sessions.jwtService.ValidateToken(second.AccessToken)
+	if secondClaims.SessionID != claims.SessionID {
+		t.Errorf("session ID = %q, want %q (same session)", secondClaims.SessionID, claims.SessionID)
+	}
+
+	// 使用済みのトークンの再利用でセッションごと失効する
+	if _, err := sessions.Refresh(ctx, first.RefreshToken); !errors.Is(err, ErrRefreshTokenReused) {
+		t.Fatalf("Refresh(used token) error = %v, want ErrRefreshTokenReused", err)
+	}
+	if _, err := sessions.Refresh(ctx, second.RefreshToken); !errors.Is(err, ErrInvalidRefreshToken) {
+		t.Errorf("Refresh(token of revoked session) error = %v, want ErrInvalidRefreshToken", err)
+	}
+	revoked, err := sessions.IsRevoked(ctx, claims.SessionID)
+	if err != nil || !revoked {
+		t.Errorf("IsRevoked() = %v, %v, want true", revoked, err)
+	}
+
+	if _, err := sessions.Refresh(ctx, "unknown"); !errors.Is(err, ErrInvalidRefreshToken) {
+		t.Errorf("Refresh(unknown) error = %v, want ErrInvalidRefreshToken", err)
+	}
+}
+
+func TestSessionService_Logout(t *testing.T) {
+	ctx := context.Background()
+	sessions, userID := newTestSessionService(t, time.Hour)
+
+	tokens, err := sessions.StartSession(ctx, userID, "alice")
+	if err != nil {
+		t.Fatalf("StartSession() error = %v", err)
+	}
+	other, err := sessions.StartSession(ctx, userID, "alice")
+	if err != nil {
+		t.Fatalf("StartSession() error = %v", err)
+	}
+
+	if err := sessions.Logout(ctx, tokens.RefreshToken); err != nil {
+		t.Fatalf("Logout() error = %v", err)
+	}
+	if err := sessions.Logout(ctx, "unknown"); err != nil {
+		t.Errorf("Logout(unknown) error = %v, want nil", err)
+	}
+	if _, err := sessions.Refresh(ctx, tokens.RefreshToken); !errors.Is(err, ErrInvalidRefreshToken) {
+		t.Errorf("Refresh(after logout) error = %v, want ErrInvalidRefreshToken", err)
+	}
+
+	// 別のセッション（別の端末）には影響しない
+	if _, err := sessions.Refresh(ctx, other.RefreshToken); err != nil {
+		t.Errorf("Refresh(other session) error = %v", err)
+	}
+}
+
+func TestSessionService_Expired(t *testing.T) {
+	ctx := context.Background()
+	sessions, userID := newTestSessionService(t, time.Millisecond)
+
+	tokens, err := sessions.StartSession(ctx, userID, "alice")
+	if err != nil {
+		t.Fatalf("StartSession() error = %v", err)
+	}
+	time.Sleep(5 * time.Millisecond)
+
+	if _, err := sessions.Refresh(ctx, tokens.RefreshToken); !errors.Is(err, ErrInvalidRefreshToken) {
+		t.Errorf("Refresh(expired) error = %v, want ErrInvalidRefreshToken", err)
+	}
+	if err := sessions.DeleteExpired(ctx); err != nil {
+		t.Fatalf("DeleteExpired() error = %v", err)
+	}
+	if _, err := sessions.querier.GetRefreshTokenByHash(ctx, hashRefreshToken(tokens.RefreshToken)); err == nil {
+		t.Error("expired refresh token was not deleted")
+	}
+}
@@ -0,0 +1,70 @@
+package auth
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// TicketStore issues short-lived single-use tickets for the WebSocket upgrade.
+// ブラウザのWebSocket APIはAuthorizationヘッダーを付けられないため、認証済みのAPIで発行したチケットをクエリパラメータで渡す
+// チケットはこのインスタンスのメモリにだけ保存する（WebSocketはroomを持つインスタンスに接続する前提）
+type TicketStore struct {
+	mutex   sync.Mutex
+	ttl     time.Duration
+	tickets map[string]ticket
+}
+
+type ticket struct {
+	claims    Claims
+	expiresAt time.Time
+}
+
+func NewTicketStore(ttl time.Duration) *TicketStore {
+	return &TicketStore{
+		ttl:     ttl,
+		tickets: make(map[string]ticket),
+	}
+}
+
+// Issue returns a new ticket for the user and when it expires
+func (s *TicketStore) Issue(claims *Claims) (string, time.Time, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", time.Time{}, fmt.Errorf("failed to generate ticket: %w", err)
+	}
+	value := base64.RawURLEncoding.EncodeToString(b)
+
+	now := time.Now()
+	expiresAt := now.Add(s.ttl)
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	// 使われずに期限切れになったチケットを発行のついでに削除
+	for key, t := range s.tickets {
+		if !now.Before(t.expiresAt) {
+			delete(s.tickets, key)
+		}
+	}
+	s.tickets[value] = ticket{claims: *claims, expiresAt: expiresAt}
+	return value, expiresAt, nil
+}
+
+// Redeem consumes the ticket and returns the user it was issued for.
+// 未知・使用済み・期限切れのチケットはfalse
+func (s *TicketStore) Redeem(value string) (*Claims, bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	t, exists := s.tickets[value]
+	if !exists {
+		return nil, false
+	}
+	delete(s.tickets, value)
+	if !time.Now().Before(t.expiresAt) {
+		return nil, false
+	}
+	return &t.claims, true
+}
@@ -1,8 +1,15 @@
 package config
 
-import "os"
+import (
+	"os"
+	"strings"
+	"time"
+)
 
 type Config struct {
+	// データベースのドライバ（"mysql"はMariaDB、"sqlite"はSQLitePathのファイルを使うローカル開発・テスト用）
+	DBDriver   string
+	SQLitePath string
 	DBHost     string
 	DBPort     string
 	DBUser     string
@@ -10,10 +17,50 @@ type Config struct {
 	DBName     string
 	Port       string
 	JWTSecret  string
+	// アクセストークンの有効期間（短くし、リフレッシュトークンで更新する）
+	AccessTokenTTL time.Duration
+	// リフレッシュトークンの有効期間（ローテーションのたびに延びる）
+	RefreshTokenTTL time.Duration
+	// ゲストユーザーを本登録しないまま残しておく期間（過ぎると成績ごと削除する）
+	GuestTTL time.Duration
+	// WebSocketの単一セッションポリシー（trueの場合、新しい接続が古いタブの接続を置き換える）
+	WSSingleSession bool
+	// WebSocket接続を許可するOriginのホストのパターン（同一オリジンは常に許可）
+	WSAllowedOrigins []string
+	// WebSocketイベントを複数インスタンスで共有するバックプレーンのブローカーのアドレス（空の場合は単一インスタンス）
+	WSBackplaneAddr string
+	// このインスタンスでバックプレーンのブローカーを起動する場合の待ち受けアドレス
+	WSBackplaneBrokerListen string
+	// roomの状態を保存・復元するときのインスタンスの識別子（複数インスタンス構成ではインスタンスごとに変える）
+	InstanceID string
+	// 起動時に未適用のマイグレーションを適用するか（falseの場合は`migrate`サブコマンドで適用する）
+	DBMigrateOnStart bool
+	// OAuth2/OIDCでログインできる外部プロバイダー（OIDC_PROVIDERSに並べた名前ごとにOIDC_<NAME>_*で設定する）
+	OIDCProviders []OIDCProviderConfig
+}
+
+// OIDCProviderConfig is an external OAuth2/OIDC provider used for login
+type OIDCProviderConfig struct {
+	// Name はURL（/auth/oidc/{provider}/...）とuser_identity.providerに使う名前
+	Name string
+	// Issuer は/.well-known/openid-configurationでエンドポイントを取得するIssuerのURL
+	Issuer       string
+	ClientID     string
+	ClientSecret string // 空の場合はPKCEだけを使う公開クライアント
+	// RedirectURL はプロバイダーに登録したフロントエンドのコールバックのURL
+	RedirectURL string
+	Scopes      []string
+	// UsernameClaim は新しいuserの名前に使うIDトークンのクレーム
+	UsernameClaim string
+	// LinkByUsername はtrueの場合、初回ログイン時に同じ名前のX-Forwarded-Userのユーザーに紐付ける
+	// （認証プロキシが同じプロバイダーでユーザーを認証している場合だけ有効にする）
+	LinkByUsername bool
 }
 
 func LoadConfig() *Config {
 	return &Config{
+		DBDriver:   getEnv("DB_DRIVER", "mysql"),
+		SQLitePath: getEnv("SQLITE_PATH", "local.db"),
 		DBHost:     getEnv("NS_MARIADB_HOSTNAME", "localhost"),
 		DBPort:     getEnv("NS_MARIADB_PORT", "3306"),
 		DBUser:     getEnv("NS_MARIADB_USER", "user"),
@@ -21,7 +68,43 @@ func LoadConfig() *Config {
 		DBName:     getEnv("NS_MARIADB_DATABASE", "template_db"),
 		Port:       getEnv("PORT", "8080"),
 		JWTSecret:  getEnv("JWT_SECRET", "your-secret-key-here"),
+
+		AccessTokenTTL:  getDuration("ACCESS_TOKEN_TTL", 15*time.Minute),
+		RefreshTokenTTL: getDuration("REFRESH_TOKEN_TTL", 30*24*time.Hour),
+		GuestTTL:        getDuration("GUEST_TTL", 7*24*time.Hour),
+
+		WSSingleSession:         getEnv("WS_SINGLE_SESSION", "false") == "true",
+		WSAllowedOrigins:        getList("WS_ALLOWED_ORIGINS", []string{"localhost:5173"}),
+		WSBackplaneAddr:         os.Getenv("WS_BACKPLANE_ADDR"),
+		WSBackplaneBrokerListen: os.Getenv("WS_BACKPLANE_BROKER_LISTEN"),
+		InstanceID:              getEnv("INSTANCE_ID", "default"),
+		DBMigrateOnStart:        getEnv("DB_MIGRATE_ON_START", "true") == "true",
+		OIDCProviders:           loadOIDCProviders(),
+	}
+}
+
+// loadOIDCProviders はOIDC_PROVIDERS（例: "traq"）の名前ごとにOIDC_<NAME>_ISSUERなどを読む
+// Issuer・クライアントID・リダイレクトURLのいずれかがないプロバイダーは無視する
+func loadOIDCProviders() []OIDCProviderConfig {
+	var providers []OIDCProviderConfig
+	for _, name := range getList("OIDC_PROVIDERS", nil) {
+		prefix := "OIDC_" + strings.ToUpper(name) + "_"
+		provider := OIDCProviderConfig{
+			Name:           strings.ToLower(name),
+			Issuer:         os.Getenv(prefix + "ISSUER"),
+			ClientID:       os.Getenv(prefix + "CLIENT_ID"),
+			ClientSecret:   os.Getenv(prefix + "CLIENT_SECRET"),
+			RedirectURL:    os.Getenv(prefix + "REDIRECT_URL"),
+			Scopes:         getList(prefix+"SCOPES", []string{"openid", "profile"}),
+			UsernameClaim:  getEnv(prefix+"USERNAME_CLAIM", "preferred_username"),
+			LinkByUsername: getEnv(prefix+"LINK_BY_USERNAME", "false") == "true",
+		}
+		if provider.Issuer == "" || provider.ClientID == "" || provider.RedirectURL == "" {
+			continue
+		}
+		providers = append(providers, provider)
 	}
+	return providers
 }
 
 func getEnv(key, defaultValue string) string {
@@ -31,3 +114,27 @@ func getEnv(key, defaultValue string) string {
 	}
 	return value
 }
+
+// getDuration はtime.ParseDurationの形式（例: "15m"）の環境変数を読む。不正な値は既定値とする
+func getDuration(key string, defaultValue time.Duration) time.Duration {
+	value, err := time.ParseDuration(os.Getenv(key))
+	if err != nil || value <= 0 {
+		return defaultValue
+	}
+	return value
+}
+
+// getList はカンマ区切りの環境変数を読む
+func getList(key string, defaultValue []string) []string {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+	var list []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
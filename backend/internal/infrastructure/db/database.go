@@ -0,0 +1,63 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"net/url"
+
+	"github.com/kaitoyama/kaitoyama-server-template/internal/db"
+	_ "modernc.org/sqlite"
+)
+
+const (
+	DriverMySQL  = "mysql"
+	DriverSQLite = "sqlite"
+)
+
+// Database is a connection pool together with the SQL dialect of its driver
+type Database struct {
+	*sql.DB
+	Driver string
+}
+
+func NewDatabase(database *sql.DB, driver string) *Database {
+	return &Database{
+		DB:     database,
+		Driver: driver,
+	}
+}
+
+// Queries returns the queries for the dialect, run on dbtx (the pool itself or a transaction)
+func (d *Database) Queries(dbtx db.DBTX) db.Querier {
+	if d.Driver == DriverSQLite {
+		return newSQLiteQueries(dbtx)
+	}
+	return db.New(dbtx)
+}
+
+// OpenSQLite opens the SQLite database file at path (":memory:" for an in-memory database)
+// 書き込みはデータベース全体をロックするため、接続を1本にして書き込みを直列化する
+// （FOR UPDATEがない代わりに、トランザクション同士も直列になる）
+func OpenSQLite(path string) (*Database, error) {
+	params := url.Values{}
+	params.Add("_pragma", "foreign_keys(1)")
+	params.Add("_pragma", "busy_timeout(5000)")
+	params.Add("_pragma", "journal_mode(WAL)")
+	// 時刻を比較可能な文字列で保存する（既定はtime.Time.Stringの形式）
+	params.Set("_time_format", "sqlite")
+
+	database, err := sql.Open(DriverSQLite, "file:"+path+"?"+params.Encode())
+	if err != nil {
+		return nil, err
+	}
+	database.SetMaxOpenConns(1)
+	// :memory:は接続ごとに別のデータベースになるため、接続を閉じない
+	database.SetConnMaxLifetime(0)
+	database.SetMaxIdleConns(1)
+
+	if err := database.Ping(); err != nil {
+		database.Close()
+		return nil, fmt.Errorf("failed to open SQLite database %q: %w", path, err)
+	}
+	return NewDatabase(database, DriverSQLite), nil
+}
@@ -0,0 +1,269 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/kaitoyama/kaitoyama-server-template/internal/db"
+	"github.com/kaitoyama/kaitoyama-server-template/internal/domain"
+)
+
+type gameResultRepository struct {
+	db *Database
+}
+
+func NewGameResultRepository(db *Database) domain.GameResultRepository {
+	return &gameResultRepository{
+		db: db,
+	}
+}
+
+// SaveGameResult はgame・game_player・score・game_moveとuser_stats・user_ratingの更新を1つのトランザクションで書き込む
+func (r *gameResultRepository) SaveGameResult(ctx context.Context, result domain.GameResult) (int64, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	queries := r.db.Queries(tx)
+
+	initialBoard, err := json.Marshal(result.InitialBoard)
+	if err != nil {
+		return 0, fmt.Errorf("failed to encode initial board: %w", err)
+	}
+
+	res, err := queries.CreateGame(ctx, db.CreateGameParams{
+		Uuid:         result.GameUUID,
+		RoomID:       int32(result.RoomID),
+		Mode:         result.Mode,
+		Seed:         result.Seed,
+		StartedAt:    result.StartedAt,
+		EndedAt:      result.EndedAt,
+		InitialBoard: initialBoard,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to create game: %w", err)
+	}
+	gameID, err := res.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+
+	for _, player := range result.Players {
+		err := queries.CreateGamePlayer(ctx, db.CreateGamePlayerParams{
+			GameID:           int32(gameID),
+			UserID:           int32(player.UserID),
+			Score:            int32(player.Score),
+			Ranking:          int32(player.Rank),
+			ClearedRegions:   int32(player.ClearedRegions),
+			AcceptedFormulas: int32(player.AcceptedFormulas),
+			RejectedFormulas: int32(player.RejectedFormulas),
+		})
+		if err != nil {
+			return 0, fmt.Errorf("failed to create game player %d: %w", player.UserID, err)
+		}
+
+		// 従来のscoreテーブルにもゲームと紐づけて記録する
+		_, err = queries.CreateGameScore(ctx, db.CreateGameScoreParams{
+			UserID: int32(player.UserID),
+			Value:  int32(player.Score),
+			GameID: sql.NullInt32{Int32: int32(gameID), Valid: true},
+		})
+		if err != nil {
+			return 0, fmt.Errorf("failed to create score for user %d: %w", player.UserID, err)
+		}
+
+		if err := updateUserStats(ctx, queries, player, len(result.Players)); err != nil {
+			return 0, err
+		}
+	}
+
+	// 1人プレイのゲームはレーティングに影響しない
+	if len(result.Players) >= 2 {
+		if err := updateRatings(ctx, queries, int32(gameID), result.Players); err != nil {
+			return 0, err
+		}
+	}
+
+	for i, move := range result.Moves {
+		changes, err := json.Marshal(matchedRegions(move.Changes))
+		if err != nil {
+			return 0, fmt.Errorf("failed to encode changes of move %d: %w", i, err)
+		}
+		err = queries.CreateGameMove(ctx, db.CreateGameMoveParams{
+			GameID:           int32(gameID),
+			Seq:              int32(i + 1),
+			UserID:           int32(move.UserID),
+			Expression:       truncateExpression(move.Expression),
+			SubmittedVersion: int32(move.SubmittedVersion),
+			Version:          int32(move.Version),
+			Changes:          changes,
+			GainedScore:      int32(move.GainedScore),
+			SubmittedAt:      move.SubmittedAt,
+		})
+		if err != nil {
+			return 0, fmt.Errorf("failed to create game move %d: %w", i+1, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit game result: %w", err)
+	}
+	return gameID, nil
+}
+
+// GetGameReplay はgame・game_player・game_moveからリプレイ用の記録を組み立てる
+func (r *gameResultRepository) GetGameReplay(ctx context.Context, gameID int64) (*domain.GameReplay, error) {
+	queries := r.db.Queries(r.db)
+
+	game, err := queries.GetGame(ctx, int32(gameID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, domain.ErrGameNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get game %d: %w", gameID, err)
+	}
+	if game.Mode == domain.GameModeLegacy {
+		return nil, domain.ErrReplayNotRecorded
+	}
+
+	replay := &domain.GameReplay{
+		GameID:    int64(game.ID),
+		GameUUID:  game.Uuid,
+		RoomID:    int(game.RoomID),
+		Seed:      game.Seed,
+		StartedAt: game.StartedAt,
+		EndedAt:   game.EndedAt,
+	}
+	if err := json.Unmarshal(game.InitialBoard, &replay.InitialBoard); err != nil {
+		return nil, fmt.Errorf("failed to decode initial board of game %d: %w", gameID, err)
+	}
+
+	players, err := queries.ListGamePlayers(ctx, game.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list players of game %d: %w", gameID, err)
+	}
+	for _, p := range players {
+		replay.Players = append(replay.Players, domain.GamePlayerResult{
+			UserID:           int(p.UserID),
+			Username:         p.Username,
+			Score:            int(p.Score),
+			Rank:             int(p.Ranking),
+			ClearedRegions:   int(p.ClearedRegions),
+			AcceptedFormulas: int(p.AcceptedFormulas),
+			RejectedFormulas: int(p.RejectedFormulas),
+		})
+	}
+
+	moves, err := queries.ListGameMoves(ctx, game.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list moves of game %d: %w", gameID, err)
+	}
+	for _, m := range moves {
+		var regions []matchedRegion
+		if err := json.Unmarshal(m.Changes, &regions); err != nil {
+			return nil, fmt.Errorf("failed to decode changes of game %d move %d: %w", gameID, m.Seq, err)
+		}
+		replay.Moves = append(replay.Moves, domain.GameMove{
+			UserID:           int(m.UserID),
+			Expression:       m.Expression,
+			SubmittedVersion: int(m.SubmittedVersion),
+			Version:          int(m.Version),
+			Changes:          toMatches(regions),
+			GainedScore:      int(m.GainedScore),
+			SubmittedAt:      m.SubmittedAt,
+		})
+	}
+
+	return replay, nil
+}
+
+// updateUserStats はuser_statsの行をロックして読み出し、ゲーム結果を加えて書き戻す
+func updateUserStats(ctx context.Context, queries db.Querier, player domain.GamePlayerResult, playerCount int) error {
+	stats := domain.UserStats{UserID: player.UserID}
+	row, err := queries.GetUserStatsForUpdate(ctx, int32(player.UserID))
+	if err == nil {
+		stats = userStatsFromRow(row)
+	} else if !errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("failed to get stats for user %d: %w", player.UserID, err)
+	}
+
+	stats.ApplyGameResult(player, playerCount)
+
+	err = queries.UpsertUserStats(ctx, db.UpsertUserStatsParams{
+		UserID:              int32(stats.UserID),
+		GamesPlayed:         int32(stats.GamesPlayed),
+		Wins:                int32(stats.Wins),
+		BestScore:           int32(stats.BestScore),
+		TotalScore:          int64(stats.TotalScore),
+		CurrentStreak:       int32(stats.CurrentStreak),
+		LongestStreak:       int32(stats.LongestStreak),
+		AcceptedFormulas:    int32(stats.AcceptedFormulas),
+		RejectedFormulas:    int32(stats.RejectedFormulas),
+		LongestAnswerStreak: int32(stats.LongestAnswerStreak),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to update stats for user %d: %w", player.UserID, err)
+	}
+	return nil
+}
+
+// updateRatings は参加者のuser_ratingの行をユーザーID順にロックして読み出し、
+// 最終順位から更新した値とrating_historyを書き込む
+func updateRatings(ctx context.Context, queries db.Querier, gameID int32, players []domain.GamePlayerResult) error {
+	userIDs := make([]int, 0, len(players))
+	for _, p := range players {
+		userIDs = append(userIDs, p.UserID)
+	}
+	// 同時に終わったゲーム同士でデッドロックしないようロックの順序を揃える
+	sort.Ints(userIDs)
+
+	current := make(map[int]domain.Rating, len(players))
+	for _, userID := range userIDs {
+		row, err := queries.GetUserRatingForUpdate(ctx, int32(userID))
+		if errors.Is(err, sql.ErrNoRows) {
+			current[userID] = domain.NewRating(userID)
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("failed to get rating for user %d: %w", userID, err)
+		}
+		current[userID] = domain.Rating{
+			UserID:      int(row.UserID),
+			Rating:      row.Rating,
+			Deviation:   row.Deviation,
+			GamesPlayed: int(row.GamesPlayed),
+		}
+	}
+
+	for _, rating := range domain.UpdateRatings(players, current) {
+		before := current[rating.UserID]
+		err := queries.UpsertUserRating(ctx, db.UpsertUserRatingParams{
+			UserID:      int32(rating.UserID),
+			Rating:      rating.Rating,
+			Deviation:   rating.Deviation,
+			GamesPlayed: int32(rating.GamesPlayed),
+		})
+		if err != nil {
+			return fmt.Errorf("failed to update rating for user %d: %w", rating.UserID, err)
+		}
+
+		err = queries.CreateRatingHistory(ctx, db.CreateRatingHistoryParams{
+			GameID:          gameID,
+			UserID:          int32(rating.UserID),
+			RatingBefore:    before.Rating,
+			DeviationBefore: before.Deviation,
+			RatingAfter:     rating.Rating,
+			DeviationAfter:  rating.Deviation,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to create rating history for user %d: %w", rating.UserID, err)
+		}
+	}
+	return nil
+}
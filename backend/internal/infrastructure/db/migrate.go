@@ -0,0 +1,336 @@
+package db
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kaitoyama/kaitoyama-server-template/internal/db"
+	"github.com/rs/zerolog/log"
+)
+
+// Migration is one versioned schema migration file (NNNN_name.sql)
+type Migration struct {
+	Version  int
+	Name     string
+	SQL      string
+	Checksum string // SQLのSHA-256（適用済みのファイルが書き換えられていないかの検出に使う）
+}
+
+// AppliedMigration is a row of the schema_migrations table
+type AppliedMigration struct {
+	Version   int
+	Name      string
+	Checksum  string
+	Dirty     bool
+	AppliedAt time.Time
+}
+
+const (
+	// migrationLockName は複数インスタンスが同時に起動したときにマイグレーションを直列化するGET_LOCKの名前
+	migrationLockName = "schema_migrations"
+
+	createMigrationTableQuery = `CREATE TABLE IF NOT EXISTS schema_migrations (
+    version INT PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    checksum CHAR(64) NOT NULL,
+    dirty BOOLEAN NOT NULL,
+    applied_at DATETIME NOT NULL
+)`
+	listMigrationsQuery  = `SELECT version, name, checksum, dirty, applied_at FROM schema_migrations ORDER BY version`
+	insertMigrationQuery = `INSERT INTO schema_migrations (version, name, checksum, dirty, applied_at) VALUES (?, ?, ?, TRUE, ?)`
+	cleanMigrationQuery  = `UPDATE schema_migrations SET dirty = FALSE, applied_at = ? WHERE version = ?`
+)
+
+// migrationTableExistsQueries はドライバごとのschema_migrationsの存在確認（dry-runではテーブルを作らない）
+var migrationTableExistsQueries = map[string]string{
+	DriverMySQL:  `SELECT COUNT(*) FROM information_schema.tables WHERE table_schema = DATABASE() AND table_name = 'schema_migrations'`,
+	DriverSQLite: `SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = 'schema_migrations'`,
+}
+
+var migrationFileName = regexp.MustCompile(`^(\d+)_([a-z0-9_]+)\.sql$`)
+
+// Migrator applies the embedded migrations to the database
+type Migrator struct {
+	db          *Database
+	migrations  []Migration
+	lockTimeout time.Duration
+}
+
+// NewMigrator loads the migrations from files (NNNN_name.sql directly under the root).
+// filesはdatabaseのドライバの方言で書かれたマイグレーション
+func NewMigrator(database *Database, files fs.FS) (*Migrator, error) {
+	migrations, err := LoadMigrations(files)
+	if err != nil {
+		return nil, err
+	}
+	return &Migrator{
+		db:          database,
+		migrations:  migrations,
+		lockTimeout: 60 * time.Second,
+	}, nil
+}
+
+// LoadMigrations reads the migration files in version order
+func LoadMigrations(files fs.FS) ([]Migration, error) {
+	entries, err := fs.ReadDir(files, ".")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read migrations: %w", err)
+	}
+
+	migrations := make([]Migration, 0, len(entries))
+	seen := make(map[int]string)
+	for _, entry := range entries {
+		if entry.IsDir() || path.Ext(entry.Name()) != ".sql" {
+			continue
+		}
+		match := migrationFileName.FindStringSubmatch(entry.Name())
+		if match == nil {
+			return nil, fmt.Errorf("invalid migration file name %q (expected NNNN_name.sql)", entry.Name())
+		}
+		version, err := strconv.Atoi(match[1])
+		if err != nil || version <= 0 {
+			return nil, fmt.Errorf("invalid migration version in %q", entry.Name())
+		}
+		if other, exists := seen[version]; exists {
+			return nil, fmt.Errorf("duplicate migration version %d: %q and %q", version, other, entry.Name())
+		}
+		seen[version] = entry.Name()
+
+		content, err := fs.ReadFile(files, entry.Name())
+		if err != nil {
+			return nil, fmt.Errorf("failed to read migration %q: %w", entry.Name(), err)
+		}
+		sum := sha256.Sum256(content)
+		migrations = append(migrations, Migration{
+			Version:  version,
+			Name:     match[2],
+			SQL:      string(content),
+			Checksum: hex.EncodeToString(sum[:]),
+		})
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return migrations, nil
+}
+
+// Migrate applies the pending migrations in version order and returns them.
+// dryRunの場合は何も変更せずに適用予定のマイグレーションを返す
+// MySQLで途中で失敗した場合、そのマイグレーションはdirtyとして記録され、手動で直すまで以降の起動・マイグレーションを拒否する
+// （SQLiteではロールバックされ、記録も残らない）
+func (m *Migrator) Migrate(ctx context.Context, dryRun bool) ([]Migration, error) {
+	// GET_LOCKは接続単位のため、ロックの取得から解放まで同じ接続を使う
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get connection: %w", err)
+	}
+	defer conn.Close()
+
+	if dryRun {
+		var exists int
+		if err := conn.QueryRowContext(ctx, migrationTableExistsQueries[m.db.Driver]).Scan(&exists); err != nil {
+			return nil, fmt.Errorf("failed to check schema_migrations: %w", err)
+		}
+		if exists == 0 {
+			return pendingMigrations(nil, m.migrations)
+		}
+		applied, err := listAppliedMigrations(ctx, conn)
+		if err != nil {
+			return nil, err
+		}
+		return pendingMigrations(applied, m.migrations)
+	}
+
+	// SQLiteは接続を1本に絞っており、DDLもトランザクションでロールバックできるためロックを取らない
+	if m.db.Driver != DriverSQLite {
+		if err := acquireMigrationLock(ctx, conn, m.lockTimeout); err != nil {
+			return nil, err
+		}
+		defer func() {
+			// 接続が閉じられた場合もロックは解放される
+			if _, err := conn.ExecContext(context.Background(), "SELECT RELEASE_LOCK(?)", migrationLockName); err != nil {
+				log.Warn().Err(err).Msg("Failed to release migration lock")
+			}
+		}()
+	}
+
+	if _, err := conn.ExecContext(ctx, createMigrationTableQuery); err != nil {
+		return nil, fmt.Errorf("failed to create schema_migrations: %w", err)
+	}
+	// ロックの取得を待つ間に他のインスタンスが適用した分を含めて読み直す
+	applied, err := listAppliedMigrations(ctx, conn)
+	if err != nil {
+		return nil, err
+	}
+	pending, err := pendingMigrations(applied, m.migrations)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, migration := range pending {
+		if err := applyMigration(ctx, conn, m.db.Driver, migration); err != nil {
+			return pending[:i], err
+		}
+		log.Info().Int("version", migration.Version).Str("name", migration.Name).Msg("Applied migration")
+	}
+	return pending, nil
+}
+
+func acquireMigrationLock(ctx context.Context, conn *sql.Conn, timeout time.Duration) error {
+	var acquired sql.NullInt64
+	err := conn.QueryRowContext(ctx, "SELECT GET_LOCK(?, ?)", migrationLockName, int(timeout.Seconds())).Scan(&acquired)
+	if err != nil {
+		return fmt.Errorf("failed to acquire migration lock: %w", err)
+	}
+	if !acquired.Valid || acquired.Int64 != 1 {
+		return fmt.Errorf("timed out after %s waiting for migration lock held by another instance", timeout)
+	}
+	return nil
+}
+
+func listAppliedMigrations(ctx context.Context, conn *sql.Conn) ([]AppliedMigration, error) {
+	rows, err := conn.QueryContext(ctx, listMigrationsQuery)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list applied migrations: %w", err)
+	}
+	defer rows.Close()
+
+	var applied []AppliedMigration
+	for rows.Next() {
+		var a AppliedMigration
+		if err := rows.Scan(
+			&a.Version,
+			&a.Name,
+			&a.Checksum,
+			&a.Dirty,
+			&a.AppliedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan applied migration: %w", err)
+		}
+		applied = append(applied, a)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list applied migrations: %w", err)
+	}
+	return applied, nil
+}
+
+// pendingMigrations は未適用のマイグレーションをバージョン順に返す
+// 失敗したまま残っている（dirty）マイグレーションや、適用後に内容が変わったマイグレーションがあればエラーにする
+func pendingMigrations(applied []AppliedMigration, migrations []Migration) ([]Migration, error) {
+	appliedByVersion := make(map[int]AppliedMigration, len(applied))
+	for _, a := range applied {
+		if a.Dirty {
+			return nil, fmt.Errorf("migration %d (%s) failed previously and left the schema dirty; fix the schema by hand and delete its row from schema_migrations", a.Version, a.Name)
+		}
+		appliedByVersion[a.Version] = a
+	}
+
+	known := make(map[int]bool, len(migrations))
+	pending := make([]Migration, 0)
+	for _, migration := range migrations {
+		known[migration.Version] = true
+		a, exists := appliedByVersion[migration.Version]
+		if !exists {
+			pending = append(pending, migration)
+			continue
+		}
+		if a.Checksum != migration.Checksum {
+			return nil, fmt.Errorf("migration %d (%s) was modified after it was applied; add a new migration instead", migration.Version, migration.Name)
+		}
+	}
+
+	for _, a := range applied {
+		if !known[a.Version] {
+			// 新しいバージョンのインスタンスが先に適用した（ローリングデプロイ中など）
+			log.Warn().Int("version", a.Version).Str("name", a.Name).Msg("Database has a migration unknown to this binary")
+		}
+	}
+	return pending, nil
+}
+
+// applyMigration は文を順に実行し、schema_migrationsに適用済みとして記録する
+// SQLiteはDDLもロールバックできるため、記録も同じトランザクションで行い、失敗時は何も残さない
+// MariaDBのDDLは暗黙にコミットされるため、先にdirtyとして記録してコミットし、成功したらdirtyを外す
+// （失敗した文より前のDDLは残るため、dirtyのまま残して以降の起動を止める）
+func applyMigration(ctx context.Context, conn *sql.Conn, driver string, migration Migration) error {
+	transactional := driver == DriverSQLite
+	if !transactional {
+		if err := recordMigration(ctx, conn, migration); err != nil {
+			return err
+		}
+	}
+
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin migration %d (%s): %w", migration.Version, migration.Name, err)
+	}
+	if transactional {
+		if err := recordMigration(ctx, tx, migration); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+	for i, statement := range SplitStatements(migration.SQL) {
+		if _, err := tx.ExecContext(ctx, statement); err != nil {
+			tx.Rollback()
+			if transactional {
+				return fmt.Errorf("migration %d (%s) failed at statement %d and was rolled back: %w", migration.Version, migration.Name, i+1, err)
+			}
+			return fmt.Errorf("migration %d (%s) failed at statement %d; statements before it may have been committed: %w", migration.Version, migration.Name, i+1, err)
+		}
+	}
+	if _, err := tx.ExecContext(ctx, cleanMigrationQuery, time.Now().UTC(), migration.Version); err != nil {
+		tx.Rollback()
+		return fmt.Errorf("failed to mark migration %d (%s) as applied: %w", migration.Version, migration.Name, err)
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit migration %d (%s): %w", migration.Version, migration.Name, err)
+	}
+	return nil
+}
+
+// recordMigration はマイグレーションをdirtyとしてschema_migrationsに記録する
+func recordMigration(ctx context.Context, dbtx db.DBTX, migration Migration) error {
+	if _, err := dbtx.ExecContext(ctx, insertMigrationQuery,
+		migration.Version,
+		migration.Name,
+		migration.Checksum,
+		time.Now().UTC(),
+	); err != nil {
+		return fmt.Errorf("failed to record migration %d (%s): %w", migration.Version, migration.Name, err)
+	}
+	return nil
+}
+
+// SplitStatements splits a migration into statements.
+// 行末の;で文を区切り、--で始まるコメント行は除く（文字列リテラル中の行末の;は扱わない）
+func SplitStatements(content string) []string {
+	var statements []string
+	var current strings.Builder
+	for _, line := range strings.Split(content, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "--") {
+			continue
+		}
+		current.WriteString(line)
+		current.WriteString("\n")
+		if strings.HasSuffix(trimmed, ";") {
+			statement := strings.TrimSuffix(strings.TrimSpace(current.String()), ";")
+			statements = append(statements, statement)
+			current.Reset()
+		}
+	}
+	if rest := strings.TrimSpace(current.String()); rest != "" {
+		statements = append(statements, rest)
+	}
+	return statements
+}
@@ -0,0 +1,249 @@
+package db
+
+import (
+	"os"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestLoadMigrations(t *testing.T) {
+	files := fstest.MapFS{
+		"0002_add_index.sql": {Data: []byte("CREATE INDEX idx ON t (a);\n")},
+		"0001_create_t.sql":  {Data: []byte("CREATE TABLE t (a INT);\n")},
+		"README.md":          {Data: []byte("ignored")},
+	}
+
+	migrations, err := LoadMigrations(files)
+	if err != nil {
+		t.Fatalf("LoadMigrations() error = %v", err)
+	}
+	if len(migrations) != 2 {
+		t.Fatalf("len(migrations) = %d, want 2", len(migrations))
+	}
+	if migrations[0].Version != 1 || migrations[0].Name != "create_t" || migrations[1].Version != 2 {
+		t.Errorf("migrations = %+v, want versions 1 and 2 in order", migrations)
+	}
+	if len(migrations[0].Checksum) != 64 || migrations[0].Checksum == migrations[1].Checksum {
+		t.Errorf("unexpected checksums %q, %q", migrations[0].Checksum, migrations[1].Checksum)
+	}
+
+	invalid := []fstest.MapFS{
+		{"create_t.sql": {Data: []byte("SELECT 1;")}},
+		{"0001_a.sql": {Data: []byte("SELECT 1;")}, "001_b.sql": {Data: []byte("SELECT 2;")}},
+		{"0000_zero.sql": {Data: []byte("SELECT 1;")}},
+	}
+	for _, files := range invalid {
+		if _, err := LoadMigrations(files); err == nil {
+			t.Errorf("LoadMigrations(%v) error = nil, want error", reflect.ValueOf(files).MapKeys())
+		}
+	}
+}
+
+// リポジトリに含まれるマイグレーション（バイナリに埋め込まれるもの）を読み込めること
+func TestLoadMigrations_Embedded(t *testing.T) {
+	migrations, err := LoadMigrations(os.DirFS("../../../db/migrations"))
+	if err != nil {
+		t.Fatalf("LoadMigrations() error = %v", err)
+	}
+	if len(migrations) == 0 || migrations[0].Version != 1 {
+		t.Fatalf("migrations = %+v, want to start at version 1", migrations)
+	}
+	for _, migration := range migrations {
+		if len(SplitStatements(migration.SQL)) == 0 {
+			t.Errorf("migration %d (%s) has no statements", migration.Version, migration.Name)
+		}
+	}
+}
+
+func TestSplitStatements(t *testing.T) {
+	content := `-- comment
+CREATE TABLE a (
+    id INT -- inline
+);
+
+-- another comment
+INSERT INTO a VALUES (1);
+SELECT 1`
+
+	got := SplitStatements(content)
+	want := []string{
+		"CREATE TABLE a (\n    id INT -- inline\n)",
+		"INSERT INTO a VALUES (1)",
+		"SELECT 1",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("SplitStatements() = %q, want %q", got, want)
+	}
+}
+
+func TestPendingMigrations(t *testing.T) {
+	migrations := []Migration{
+		{Version: 1, Name: "one", Checksum: "c1"},
+		{Version: 2, Name: "two", Checksum: "c2"},
+		{Version: 3, Name: "three", Checksum: "c3"},
+	}
+
+	tests := []struct {
+		name    string
+		applied []AppliedMigration
+		want    []int
+		wantErr string
+	}{
+		{name: "fresh database", want: []int{1, 2, 3}},
+		{
+			name:    "partially applied",
+			applied: []AppliedMigration{{Version: 1, Checksum: "c1"}},
+			want:    []int{2, 3},
+		},
+		{
+			name:    "applied by a newer binary",
+			applied: []AppliedMigration{{Version: 1, Checksum: "c1"}, {Version: 2, Checksum: "c2"}, {Version: 3, Checksum: "c3"}, {Version: 4, Checksum: "c4"}},
+			want:    []int{},
+		},
+		{
+			name:    "dirty",
+			applied: []AppliedMigration{{Version: 1, Checksum: "c1"}, {Version: 2, Checksum: "c2", Dirty: true}},
+			wantErr: "dirty",
+		},
+		{
+			name:    "modified after applied",
+			applied: []AppliedMigration{{Version: 1, Checksum: "changed"}},
+			wantErr: "modified",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pending, err := pendingMigrations(tt.applied, migrations)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("pendingMigrations() error = %v, want containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("pendingMigrations() error = %v", err)
+			}
+			got := []int{}
+			for _, m := range pending {
+				got = append(got, m.Version)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("pending versions = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// MariaDB版とSQLite版のマイグレーションが同じテーブル・カラムを同じ順で作ること
+// MariaDB版はCREATE TABLEとALTER TABLE ... ADD COLUMNを読み、SQLite版は適用した結果を読む
+func TestMigrations_SameSchema(t *testing.T) {
+	mariadb, err := LoadMigrations(os.DirFS("../../../db/migrations"))
+	if err != nil {
+		t.Fatalf("LoadMigrations() error = %v", err)
+	}
+	sqliteMigrations, err := LoadMigrations(os.DirFS("../../../db/sqlite/migrations"))
+	if err != nil {
+		t.Fatalf("LoadMigrations() error = %v", err)
+	}
+	if len(mariadb) != len(sqliteMigrations) {
+		t.Fatalf("%d MariaDB migrations, %d SQLite migrations", len(mariadb), len(sqliteMigrations))
+	}
+	for i := range mariadb {
+		if mariadb[i].Version != sqliteMigrations[i].Version || mariadb[i].Name != sqliteMigrations[i].Name {
+			t.Errorf("migration %d_%s has SQLite counterpart %d_%s", mariadb[i].Version, mariadb[i].Name, sqliteMigrations[i].Version, sqliteMigrations[i].Name)
+		}
+	}
+
+	want := make(map[string][]string)
+	for _, migration := range mariadb {
+		for _, statement := range SplitStatements(migration.SQL) {
+			applyMySQLSchemaStatement(want, statement)
+		}
+	}
+
+	database := newTestSQLite(t)
+	rows, err := database.Query("SELECT name FROM sqlite_master WHERE type = 'table' AND name NOT IN ('sqlite_sequence', 'schema_migrations')")
+	if err != nil {
+		t.Fatalf("list tables error = %v", err)
+	}
+	var tables []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			t.Fatalf("scan table error = %v", err)
+		}
+		tables = append(tables, name)
+	}
+	rows.Close()
+
+	got := make(map[string][]string)
+	for _, table := range tables {
+		rows, err := database.Query("SELECT name FROM pragma_table_info(?)", table)
+		if err != nil {
+			t.Fatalf("table_info(%s) error = %v", table, err)
+		}
+		for rows.Next() {
+			var column string
+			if err := rows.Scan(&column); err != nil {
+				t.Fatalf("scan column error = %v", err)
+			}
+			got[table] = append(got[table], column)
+		}
+		rows.Close()
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("SQLite schema = %v\nMariaDB schema = %v", got, want)
+	}
+}
+
+var (
+	createTablePattern = regexp.MustCompile(`(?is)^CREATE TABLE (?:IF NOT EXISTS )?(\w+) \((.*)\)$`)
+	addColumnPattern   = regexp.MustCompile(`(?i)^ALTER TABLE (\w+) ADD COLUMN (\w+)`)
+)
+
+// applyMySQLSchemaStatement はMariaDBのDDLのテーブル・カラムをschemaに反映する（インデックス・制約・DMLは無視）
+func applyMySQLSchemaStatement(schema map[string][]string, statement string) {
+	if match := addColumnPattern.FindStringSubmatch(statement); match != nil {
+		schema[match[1]] = append(schema[match[1]], match[2])
+		return
+	}
+	match := createTablePattern.FindStringSubmatch(statement)
+	if match == nil {
+		return
+	}
+	var columns []string
+	for _, definition := range splitTopLevel(match[2]) {
+		fields := strings.Fields(definition)
+		switch strings.ToUpper(fields[0]) {
+		case "INDEX", "KEY", "UNIQUE", "PRIMARY", "CONSTRAINT", "FOREIGN":
+			continue
+		}
+		columns = append(columns, fields[0])
+	}
+	schema[match[1]] = columns
+}
+
+// splitTopLevel は括弧の外のカンマで区切る
+func splitTopLevel(s string) []string {
+	var parts []string
+	depth, start := 0, 0
+	for i, r := range s {
+		switch r {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case ',':
+			if depth == 0 {
+				parts = append(parts, strings.TrimSpace(s[start:i]))
+				start = i + 1
+			}
+		}
+	}
+	return append(parts, strings.TrimSpace(s[start:]))
+}
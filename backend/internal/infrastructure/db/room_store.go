@@ -0,0 +1,291 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/kaitoyama/kaitoyama-server-template/internal/db"
+	"github.com/kaitoyama/kaitoyama-server-template/internal/domain"
+	"github.com/rs/zerolog/log"
+)
+
+// RoomSnapshotStore はroomの状態をroom_snapshotへ書き込み、起動時に読み出す
+// 書き込みは1つのゴルーチンで行い、書き込み待ちの間に同じroomが更新された場合は最新の状態だけを書く
+type RoomSnapshotStore struct {
+	queries      db.Querier
+	instanceID   string
+	writeTimeout time.Duration
+	mutex        sync.Mutex
+	pending      map[int]domain.RoomRecord // roomID -> 書き込み待ちの最新の状態
+	closed       bool
+	wake         chan struct{}
+	done         chan struct{}
+}
+
+// NewRoomSnapshotStore starts the background writer. instanceIDは複数インスタンス構成でroomを区別する識別子
+func NewRoomSnapshotStore(database *Database, instanceID string) *RoomSnapshotStore {
+	return newRoomSnapshotStore(database.Queries(database), instanceID)
+}
+
+func newRoomSnapshotStore(queries db.Querier, instanceID string) *RoomSnapshotStore {
+	s := &RoomSnapshotStore{
+		queries:      queries,
+		instanceID:   instanceID,
+		writeTimeout: 5 * time.Second,
+		pending:      make(map[int]domain.RoomRecord),
+		wake:         make(chan struct{}, 1),
+		done:         make(chan struct{}),
+	}
+	go s.writeLoop()
+	return s
+}
+
+// SaveRoom はブロックせずにroomの状態を書き込み待ちにする
+func (s *RoomSnapshotStore) SaveRoom(record domain.RoomRecord) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.closed {
+		return
+	}
+	s.pending[record.ID] = record
+
+	// 起床の通知が既に積まれていれば何もしない（wakeはCloseがロックを取ってから閉じる）
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+// LoadRooms returns the rooms saved by this instance
+func (s *RoomSnapshotStore) LoadRooms(ctx context.Context) ([]domain.RoomRecord, error) {
+	rows, err := s.queries.ListRoomSnapshots(ctx, s.instanceID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list room snapshots: %w", err)
+	}
+
+	records := make([]domain.RoomRecord, 0, len(rows))
+	for _, row := range rows {
+		var snapshot roomSnapshot
+		if err := json.Unmarshal(row.State, &snapshot); err != nil {
+			return nil, fmt.Errorf("failed to decode snapshot of room %d: %w", row.RoomID, err)
+		}
+		records = append(records, snapshot.record(int(row.RoomID), row.SavedAt))
+	}
+	return records, nil
+}
+
+// Close は書き込み待ちの状態を書き込んでから終了する
+func (s *RoomSnapshotStore) Close() {
+	s.mutex.Lock()
+	if s.closed {
+		s.mutex.Unlock()
+		return
+	}
+	s.closed = true
+	close(s.wake)
+	s.mutex.Unlock()
+
+	<-s.done
+}
+
+func (s *RoomSnapshotStore) writeLoop() {
+	defer close(s.done)
+	for range s.wake {
+		s.flush()
+	}
+	// Closeの前に積まれた分を書き込む
+	s.flush()
+}
+
+func (s *RoomSnapshotStore) flush() {
+	s.mutex.Lock()
+	pending := s.pending
+	s.pending = make(map[int]domain.RoomRecord)
+	s.mutex.Unlock()
+
+	for roomID, record := range pending {
+		if err := s.write(record); err != nil {
+			log.Error().Err(err).Int("room_id", roomID).Msg("Failed to write room snapshot")
+		}
+	}
+}
+
+func (s *RoomSnapshotStore) write(record domain.RoomRecord) error {
+	state, err := json.Marshal(newRoomSnapshot(record))
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), s.writeTimeout)
+	defer cancel()
+
+	return s.queries.UpsertRoomSnapshot(ctx, db.UpsertRoomSnapshotParams{
+		InstanceID: s.instanceID,
+		RoomID:     int32(record.ID),
+		State:      state,
+		SavedAt:    record.SavedAt,
+	})
+}
+
+// roomSnapshot はroom_snapshot.stateカラムに保存するroomの状態
+type roomSnapshot struct {
+	Name                string           `json:"name"`
+	State               string           `json:"state"`
+	IsOpened            bool             `json:"is_opened"`
+	Players             []snapshotPlayer `json:"players"`
+	LastCorrectPlayerID int              `json:"last_correct_player_id"`
+	StreakCount         int              `json:"streak_count"`
+	GameEndsAt          time.Time        `json:"game_ends_at"`
+	PausedRemainingMs   int64            `json:"paused_remaining_ms"`
+	AutoPaused          bool             `json:"auto_paused"`
+	Seed                int64            `json:"seed"`
+	GameStartedAt       time.Time        `json:"game_started_at"`
+	GameUUID            string           `json:"game_uuid"`
+	InitialBoard        [][]int          `json:"initial_board"`
+	Moves               []snapshotMove   `json:"moves"`
+	Leavers             []snapshotPlayer `json:"leavers"`
+}
+
+type snapshotPlayer struct {
+	ID                  int    `json:"id"`
+	UserName            string `json:"username"`
+	IsReady             bool   `json:"is_ready"`
+	HasClosedResult     bool   `json:"has_closed_result"`
+	Score               int    `json:"score"`
+	ClearedRegions      int    `json:"cleared_regions"`
+	AcceptedFormulas    int    `json:"accepted_formulas"`
+	RejectedFormulas    int    `json:"rejected_formulas"`
+	LongestAnswerStreak int    `json:"longest_answer_streak"`
+}
+
+func newSnapshotPlayer(p domain.Player) snapshotPlayer {
+	return snapshotPlayer{
+		ID:                  p.ID,
+		UserName:            p.UserName,
+		IsReady:             p.IsReady,
+		HasClosedResult:     p.HasClosedResult,
+		Score:               p.Score,
+		ClearedRegions:      p.ClearedRegions,
+		AcceptedFormulas:    p.AcceptedFormulas,
+		RejectedFormulas:    p.RejectedFormulas,
+		LongestAnswerStreak: p.LongestAnswerStreak,
+	}
+}
+
+func (p snapshotPlayer) player() domain.Player {
+	return domain.Player{
+		ID:                  p.ID,
+		UserName:            p.UserName,
+		IsReady:             p.IsReady,
+		HasClosedResult:     p.HasClosedResult,
+		Score:               p.Score,
+		ClearedRegions:      p.ClearedRegions,
+		AcceptedFormulas:    p.AcceptedFormulas,
+		RejectedFormulas:    p.RejectedFormulas,
+		LongestAnswerStreak: p.LongestAnswerStreak,
+	}
+}
+
+type snapshotMove struct {
+	UserID           int             `json:"user_id"`
+	Expression       string          `json:"expression"`
+	SubmittedVersion int             `json:"submitted_version"`
+	Version          int             `json:"version"`
+	Changes          []matchedRegion `json:"changes"`
+	GainedScore      int             `json:"gained_score"`
+	SubmittedAt      time.Time       `json:"submitted_at"`
+}
+
+func newRoomSnapshot(record domain.RoomRecord) roomSnapshot {
+	snapshot := roomSnapshot{
+		Name:                record.Name,
+		State:               record.State.String(),
+		IsOpened:            record.IsOpened,
+		Players:             make([]snapshotPlayer, 0, len(record.Players)),
+		LastCorrectPlayerID: record.LastCorrectPlayerID,
+		StreakCount:         record.StreakCount,
+		GameEndsAt:          record.GameEndsAt,
+		PausedRemainingMs:   record.PausedRemaining.Milliseconds(),
+		AutoPaused:          record.AutoPaused,
+		Seed:                record.Seed,
+		GameStartedAt:       record.GameStartedAt,
+		GameUUID:            record.GameUUID,
+		InitialBoard:        record.InitialBoard,
+		Moves:               make([]snapshotMove, 0, len(record.Moves)),
+	}
+	for _, p := range record.Players {
+		snapshot.Players = append(snapshot.Players, newSnapshotPlayer(p))
+	}
+	for _, p := range record.Leavers {
+		snapshot.Leavers = append(snapshot.Leavers, newSnapshotPlayer(p))
+	}
+	for _, m := range record.Moves {
+		snapshot.Moves = append(snapshot.Moves, snapshotMove{
+			UserID:           m.UserID,
+			Expression:       m.Expression,
+			SubmittedVersion: m.SubmittedVersion,
+			Version:          m.Version,
+			Changes:          matchedRegions(m.Changes),
+			GainedScore:      m.GainedScore,
+			SubmittedAt:      m.SubmittedAt,
+		})
+	}
+	return snapshot
+}
+
+func (s roomSnapshot) record(roomID int, savedAt time.Time) domain.RoomRecord {
+	record := domain.RoomRecord{
+		ID:                  roomID,
+		Name:                s.Name,
+		State:               parseRoomState(s.State),
+		IsOpened:            s.IsOpened,
+		LastCorrectPlayerID: s.LastCorrectPlayerID,
+		StreakCount:         s.StreakCount,
+		GameEndsAt:          s.GameEndsAt,
+		PausedRemaining:     time.Duration(s.PausedRemainingMs) * time.Millisecond,
+		AutoPaused:          s.AutoPaused,
+		Seed:                s.Seed,
+		GameStartedAt:       s.GameStartedAt,
+		GameUUID:            s.GameUUID,
+		InitialBoard:        s.InitialBoard,
+		SavedAt:             savedAt,
+	}
+	for _, p := range s.Players {
+		record.Players = append(record.Players, p.player())
+	}
+	for _, p := range s.Leavers {
+		record.Leavers = append(record.Leavers, p.player())
+	}
+	for _, m := range s.Moves {
+		record.Moves = append(record.Moves, domain.GameMove{
+			UserID:           m.UserID,
+			Expression:       m.Expression,
+			SubmittedVersion: m.SubmittedVersion,
+			Version:          m.Version,
+			Changes:          toMatches(m.Changes),
+			GainedScore:      m.GainedScore,
+			SubmittedAt:      m.SubmittedAt,
+		})
+	}
+	return record
+}
+
+// parseRoomState はRoomState.Stringの逆変換。未知の値は待機状態として扱う
+func parseRoomState(s string) domain.RoomState {
+	for _, state := range []domain.RoomState{
+		domain.StateWaitingForPlayers,
+		domain.StateAllReady,
+		domain.StateCountdown,
+		domain.StateGameInProgress,
+		domain.StateGameEnded,
+		domain.StatePaused,
+	} {
+		if state.String() == s {
+			return state
+		}
+	}
+	return domain.StateWaitingForPlayers
+}
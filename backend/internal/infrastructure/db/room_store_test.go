@@ -0,0 +1,100 @@
+package db
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/kaitoyama/kaitoyama-server-template/internal/db"
+	"github.com/kaitoyama/kaitoyama-server-template/internal/domain"
+)
+
+// snapshotQuerier はroom_snapshotをメモリ上に持つdb.Querierのスタブ
+type snapshotQuerier struct {
+	db.Querier
+	mutex  sync.Mutex
+	writes int
+	rows   map[int32]db.RoomSnapshot
+}
+
+func (q *snapshotQuerier) UpsertRoomSnapshot(ctx context.Context, arg db.UpsertRoomSnapshotParams) error {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	q.writes++
+	q.rows[arg.RoomID] = db.RoomSnapshot{
+		InstanceID: arg.InstanceID,
+		RoomID:     arg.RoomID,
+		State:      arg.State,
+		SavedAt:    arg.SavedAt,
+	}
+	return nil
+}
+
+func (q *snapshotQuerier) ListRoomSnapshots(ctx context.Context, instanceID string) ([]db.RoomSnapshot, error) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	var rows []db.RoomSnapshot
+	for _, row := range q.rows {
+		if row.InstanceID == instanceID {
+			rows = append(rows, row)
+		}
+	}
+	sort.Slice(rows, func(i, j int) bool { return rows[i].RoomID < rows[j].RoomID })
+	return rows, nil
+}
+
+func TestRoomSnapshotStore_SavesLatestStateAndLoadsIt(t *testing.T) {
+	querier := &snapshotQuerier{rows: make(map[int32]db.RoomSnapshot)}
+	store := newRoomSnapshotStore(querier, "instance-a")
+
+	now := time.Now().UTC()
+	record := domain.RoomRecord{
+		ID:       3,
+		Name:     "Room 3",
+		State:    domain.StateWaitingForPlayers,
+		IsOpened: true,
+		Players:  []domain.Player{{ID: 7, UserName: "alice"}},
+		SavedAt:  now,
+	}
+	store.SaveRoom(record)
+
+	record.State = domain.StateGameInProgress
+	record.IsOpened = false
+	record.Players[0].Score = 20
+	record.Players[0].AcceptedFormulas = 1
+	record.Seed = 42
+	record.GameUUID = "game-1"
+	record.GameEndsAt = now.Add(time.Minute)
+	record.InitialBoard = [][]int{{1, 2, 3, 4}, {5, 6, 7, 8}, {9, 1, 2, 3}, {4, 5, 6, 7}}
+	record.Moves = []domain.GameMove{{
+		UserID:      7,
+		Expression:  "1+2+3+4",
+		Version:     2,
+		Changes:     []domain.Matches{{Linetype: "row", Index: 0, Positions: []domain.Position{{Row: 0, Col: 0}, {Row: 0, Col: 1}}}},
+		GainedScore: 10,
+		SubmittedAt: now,
+	}}
+	store.SaveRoom(record)
+	store.Close()
+
+	if len(querier.rows) != 1 {
+		t.Fatalf("saved %d rooms, want 1", len(querier.rows))
+	}
+
+	records, err := newRoomSnapshotStore(querier, "instance-a").LoadRooms(context.Background())
+	if err != nil {
+		t.Fatalf("LoadRooms() error = %v", err)
+	}
+	if len(records) != 1 || !reflect.DeepEqual(records[0], record) {
+		t.Errorf("LoadRooms() = %+v, want %+v", records, record)
+	}
+
+	// 別のインスタンスのroomは読み出さない
+	others, err := newRoomSnapshotStore(querier, "instance-b").LoadRooms(context.Background())
+	if err != nil || len(others) != 0 {
+		t.Errorf("LoadRooms() for another instance = %v, %v", others, err)
+	}
+}
@@ -0,0 +1,126 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/kaitoyama/kaitoyama-server-template/internal/db"
+	"github.com/kaitoyama/kaitoyama-server-template/internal/db/sqlite"
+)
+
+// sqliteQueries implements db.Querier for SQLite.
+// MariaDBと書き方が同じクエリはdb.Queriesをそのまま使い、方言が異なるクエリ（db/sqlite/queries.sql）だけを
+// sqlcで生成したsqlite.Queriesで実行する。SQLiteの整数はint64で生成されるため、dbパッケージの型に変換する
+type sqliteQueries struct {
+	*db.Queries
+	sqlite *sqlite.Queries
+}
+
+var _ db.Querier = (*sqliteQueries)(nil)
+
+func newSQLiteQueries(dbtx db.DBTX) *sqliteQueries {
+	conn := utcDBTX{dbtx}
+	return &sqliteQueries{
+		Queries: db.New(conn),
+		sqlite:  sqlite.New(conn),
+	}
+}
+
+func (q *sqliteQueries) GetUserRatingForUpdate(ctx context.Context, userID int32) (db.UserRating, error) {
+	row, err := q.sqlite.GetUserRatingForUpdate(ctx, int64(userID))
+	if err != nil {
+		return db.UserRating{}, err
+	}
+	return db.UserRating{
+		UserID:      int32(row.UserID),
+		Rating:      row.Rating,
+		Deviation:   row.Deviation,
+		GamesPlayed: int32(row.GamesPlayed),
+		UpdatedAt:   row.UpdatedAt,
+	}, nil
+}
+
+func (q *sqliteQueries) GetUserStatsForUpdate(ctx context.Context, userID int32) (db.UserStat, error) {
+	row, err := q.sqlite.GetUserStatsForUpdate(ctx, int64(userID))
+	if err != nil {
+		return db.UserStat{}, err
+	}
+	return db.UserStat{
+		UserID:              int32(row.UserID),
+		GamesPlayed:         int32(row.GamesPlayed),
+		Wins:                int32(row.Wins),
+		BestScore:           int32(row.BestScore),
+		TotalScore:          row.TotalScore,
+		CurrentStreak:       int32(row.CurrentStreak),
+		LongestStreak:       int32(row.LongestStreak),
+		AcceptedFormulas:    int32(row.AcceptedFormulas),
+		RejectedFormulas:    int32(row.RejectedFormulas),
+		UpdatedAt:           row.UpdatedAt,
+		LongestAnswerStreak: int32(row.LongestAnswerStreak),
+	}, nil
+}
+
+func (q *sqliteQueries) UpsertRoomSnapshot(ctx context.Context, arg db.UpsertRoomSnapshotParams) error {
+	return q.sqlite.UpsertRoomSnapshot(ctx, sqlite.UpsertRoomSnapshotParams{
+		InstanceID: arg.InstanceID,
+		RoomID:     int64(arg.RoomID),
+		State:      arg.State,
+		SavedAt:    arg.SavedAt,
+	})
+}
+
+func (q *sqliteQueries) UpsertUserRating(ctx context.Context, arg db.UpsertUserRatingParams) error {
+	return q.sqlite.UpsertUserRating(ctx, sqlite.UpsertUserRatingParams{
+		UserID:      int64(arg.UserID),
+		Rating:      arg.Rating,
+		Deviation:   arg.Deviation,
+		GamesPlayed: int64(arg.GamesPlayed),
+	})
+}
+
+func (q *sqliteQueries) UpsertUserStats(ctx context.Context, arg db.UpsertUserStatsParams) error {
+	return q.sqlite.UpsertUserStats(ctx, sqlite.UpsertUserStatsParams{
+		UserID:              int64(arg.UserID),
+		GamesPlayed:         int64(arg.GamesPlayed),
+		Wins:                int64(arg.Wins),
+		BestScore:           int64(arg.BestScore),
+		TotalScore:          arg.TotalScore,
+		CurrentStreak:       int64(arg.CurrentStreak),
+		LongestStreak:       int64(arg.LongestStreak),
+		AcceptedFormulas:    int64(arg.AcceptedFormulas),
+		RejectedFormulas:    int64(arg.RejectedFormulas),
+		LongestAnswerStreak: int64(arg.LongestAnswerStreak),
+	})
+}
+
+// utcDBTX は時刻の引数をUTCに揃える
+// SQLiteは時刻を文字列として比較するため、タイムゾーンが混在すると期間での絞り込みが正しくならない
+type utcDBTX struct {
+	db db.DBTX
+}
+
+func (u utcDBTX) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	return u.db.ExecContext(ctx, query, toUTC(args)...)
+}
+
+func (u utcDBTX) PrepareContext(ctx context.Context, query string) (*sql.Stmt, error) {
+	return u.db.PrepareContext(ctx, query)
+}
+
+func (u utcDBTX) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	return u.db.QueryContext(ctx, query, toUTC(args)...)
+}
+
+func (u utcDBTX) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	return u.db.QueryRowContext(ctx, query, toUTC(args)...)
+}
+
+func toUTC(args []interface{}) []interface{} {
+	for i, arg := range args {
+		if t, ok := arg.(time.Time); ok {
+			args[i] = t.UTC()
+		}
+	}
+	return args
+}
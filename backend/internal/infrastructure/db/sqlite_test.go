@@ -0,0 +1,505 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/kaitoyama/kaitoyama-server-template/internal/db"
+	"github.com/kaitoyama/kaitoyama-server-template/internal/domain"
+	"github.com/kaitoyama/kaitoyama-server-template/internal/usecase"
+)
+
+// newTestSQLite はマイグレーションを適用したインメモリのSQLiteを返す
+func newTestSQLite(t *testing.T) *Database {
+	t.Helper()
+	database, err := OpenSQLite(":memory:")
+	if err != nil {
+		t.Fatalf("OpenSQLite() error = %v", err)
+	}
+	t.Cleanup(func() { database.Close() })
+
+	migrator, err := NewMigrator(database, os.DirFS("../../../db/sqlite/migrations"))
+	if err != nil {
+		t.Fatalf("NewMigrator() error = %v", err)
+	}
+	if _, err := migrator.Migrate(context.Background(), false); err != nil {
+		t.Fatalf("Migrate() error = %v", err)
+	}
+	return database
+}
+
+func createTestUser(t *testing.T, queries db.Querier, username string) int {
+	t.Helper()
+	res, err := queries.CreateUser(context.Background(), username)
+	if err != nil {
+		t.Fatalf("CreateUser(%q) error = %v", username, err)
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		t.Fatalf("LastInsertId() error = %v", err)
+	}
+	return int(id)
+}
+
+func TestSQLite_Migrate(t *testing.T) {
+	database := newTestSQLite(t)
+	migrator, err := NewMigrator(database, os.DirFS("../../../db/sqlite/migrations"))
+	if err != nil {
+		t.Fatalf("NewMigrator() error = %v", err)
+	}
+
+	// 適用済みのため、2回目は何もしない
+	for _, dryRun := range []bool{true, false} {
+		pending, err := migrator.Migrate(context.Background(), dryRun)
+		if err != nil {
+			t.Fatalf("Migrate(dryRun=%v) error = %v", dryRun, err)
+		}
+		if len(pending) != 0 {
+			t.Errorf("Migrate(dryRun=%v) = %d migrations, want 0", dryRun, len(pending))
+		}
+	}
+}
+
+// mysqldefで作られたベースラインのスキーマ（schema_migrationsなし）から最新まで移行できること
+func TestSQLite_MigrateFromBaseline(t *testing.T) {
+	ctx := context.Background()
+	database, err := OpenSQLite(":memory:")
+	if err != nil {
+		t.Fatalf("OpenSQLite() error = %v", err)
+	}
+	defer database.Close()
+
+	baseline, err := os.ReadFile("../../../db/sqlite/migrations/0001_initial_schema.sql")
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	for _, statement := range SplitStatements(string(baseline)) {
+		if _, err := database.ExecContext(ctx, statement); err != nil {
+			t.Fatalf("ExecContext(%q) error = %v", statement, err)
+		}
+	}
+	if _, err := database.ExecContext(ctx, "INSERT INTO user (id, username) VALUES (1, 'alice')"); err != nil {
+		t.Fatalf("insert user error = %v", err)
+	}
+	if _, err := database.ExecContext(ctx, "INSERT INTO score (id, user_id, _value) VALUES (1, 1, 42)"); err != nil {
+		t.Fatalf("insert score error = %v", err)
+	}
+
+	migrator, err := NewMigrator(database, os.DirFS("../../../db/sqlite/migrations"))
+	if err != nil {
+		t.Fatalf("NewMigrator() error = %v", err)
+	}
+	if _, err := migrator.Migrate(ctx, false); err != nil {
+		t.Fatalf("Migrate() error = %v", err)
+	}
+
+	// 既存のスコアが残り、移行後のクエリで読めること
+	queries := database.Queries(database)
+	if _, err := queries.CreateScore(ctx, db.CreateScoreParams{UserID: 1, Value: 10}); err != nil {
+		t.Fatalf("CreateScore() error = %v", err)
+	}
+	scores, err := queries.ListLeaderboardScores(ctx, db.ListLeaderboardScoresParams{
+		Value:   math.MaxInt32,
+		Value_2: math.MaxInt32,
+		Limit:   10,
+	})
+	if err != nil {
+		t.Fatalf("ListLeaderboardScores() error = %v", err)
+	}
+	if len(scores) != 2 || scores[0].ID != 1 || scores[0].Username != "alice" || scores[0].Value != 42 {
+		t.Errorf("ListLeaderboardScores() = %+v, want the baseline score first", scores)
+	}
+	// マイグレーションの時刻ではなく、期間別のリーダーボードに載らない古い日時
+	if legacyAt := time.Date(1970, 1, 1, 0, 0, 0, 0, time.UTC); len(scores) > 0 && !scores[0].CreatedAt.Equal(legacyAt) {
+		t.Errorf("baseline score created_at = %v, want %v", scores[0].CreatedAt, legacyAt)
+	}
+
+	// ベースラインのスコアはmode=legacyのgameに移される
+	var gameID int32
+	if err := database.QueryRowContext(ctx, "SELECT game_id FROM score WHERE id = 1").Scan(&gameID); err != nil {
+		t.Fatalf("select game_id error = %v", err)
+	}
+	game, err := queries.GetGame(ctx, gameID)
+	if err != nil {
+		t.Fatalf("GetGame() error = %v", err)
+	}
+	if game.Mode != domain.GameModeLegacy {
+		t.Errorf("game.Mode = %q, want %q", game.Mode, domain.GameModeLegacy)
+	}
+	players, err := queries.ListGamePlayers(ctx, gameID)
+	if err != nil {
+		t.Fatalf("ListGamePlayers() error = %v", err)
+	}
+	if len(players) != 1 || players[0].UserID != 1 || players[0].Score != 42 || players[0].Ranking != 1 {
+		t.Errorf("players = %+v, want alice ranked 1 with 42", players)
+	}
+	if _, err := NewGameResultRepository(database).GetGameReplay(ctx, int64(gameID)); !errors.Is(err, domain.ErrReplayNotRecorded) {
+		t.Errorf("GetGameReplay() error = %v, want ErrReplayNotRecorded", err)
+	}
+
+	// legacyのgameは1人プレイとして通算成績に加えられる
+	stats, err := queries.GetUserStats(ctx, 1)
+	if err != nil {
+		t.Fatalf("GetUserStats() error = %v", err)
+	}
+	if stats.GamesPlayed != 1 || stats.BestScore != 42 || stats.TotalScore != 42 || stats.Wins != 0 {
+		t.Errorf("stats = %+v, want 1 game with best and total score 42 and no wins", stats)
+	}
+}
+
+func TestSQLite_GameResult(t *testing.T) {
+	ctx := context.Background()
+	database := newTestSQLite(t)
+	queries := database.Queries(database)
+	alice := createTestUser(t, queries, "alice")
+	bob := createTestUser(t, queries, "bob")
+
+	board := domain.NewSeededBoard(42)
+	startedAt := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	result := domain.GameResult{
+		GameUUID:  "00000000-0000-0000-0000-000000000001",
+		RoomID:    1,
+		Mode:      "standard",
+		Seed:      42,
+		StartedAt: startedAt,
+		EndedAt:   startedAt.Add(2 * time.Minute),
+		Players: []domain.GamePlayerResult{
+			{UserID: alice, Username: "alice", Score: 30, Rank: 1, ClearedRegions: 3, AcceptedFormulas: 3},
+			{UserID: bob, Username: "bob", Score: 10, Rank: 2, ClearedRegions: 1, AcceptedFormulas: 1, RejectedFormulas: 2},
+		},
+		InitialBoard: board.Board,
+	}
+
+	repo := NewGameResultRepository(database)
+	for i := 0; i < 2; i++ {
+		result.GameUUID = result.GameUUID[:len(result.GameUUID)-1] + string(rune('1'+i))
+		if _, err := repo.SaveGameResult(ctx, result); err != nil {
+			t.Fatalf("SaveGameResult() error = %v", err)
+		}
+	}
+
+	// 2回目はuser_stats・user_ratingのON CONFLICTによる更新
+	stats, err := NewUserStatsRepository(database).GetUserStats(ctx, alice)
+	if err != nil {
+		t.Fatalf("GetUserStats() error = %v", err)
+	}
+	if stats.GamesPlayed != 2 || stats.Wins != 2 || stats.TotalScore != 60 {
+		t.Errorf("stats = %+v, want 2 games, 2 wins, total 60", stats)
+	}
+
+	ratings, err := queries.ListRatings(ctx, db.ListRatingsParams{Limit: 10, Offset: 0})
+	if err != nil {
+		t.Fatalf("ListRatings() error = %v", err)
+	}
+	if len(ratings) != 2 || int(ratings[0].UserID) != alice || ratings[0].GamesPlayed != 2 {
+		t.Errorf("ratings = %+v, want alice first with 2 games", ratings)
+	}
+
+	replay, err := repo.GetGameReplay(ctx, 1)
+	if err != nil {
+		t.Fatalf("GetGameReplay() error = %v", err)
+	}
+	if !replay.StartedAt.Equal(startedAt) || len(replay.Players) != 2 || replay.Players[0].Username != "alice" {
+		t.Errorf("replay = %+v, want started at %v with alice first", replay, startedAt)
+	}
+
+	// 期間での絞り込み（ローカル時刻の引数はUTCに揃えて比較される）
+	since := startedAt.Add(-time.Hour).In(time.FixedZone("JST", 9*60*60))
+	count, err := queries.CountScoresAbove(ctx, db.CountScoresAboveParams{CreatedAt: since, Value: 0})
+	if err != nil {
+		t.Fatalf("CountScoresAbove() error = %v", err)
+	}
+	if count != 4 {
+		t.Errorf("CountScoresAbove() = %d, want 4", count)
+	}
+}
+
+func TestSQLite_RoomSnapshotStore(t *testing.T) {
+	database := newTestSQLite(t)
+	store := NewRoomSnapshotStore(database, "test")
+
+	savedAt := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	record := domain.NewRoom(1, "Room 1").Record(savedAt)
+	store.SaveRoom(record)
+	record.Name = "renamed"
+	store.SaveRoom(record)
+	store.Close()
+
+	records, err := store.LoadRooms(context.Background())
+	if err != nil {
+		t.Fatalf("LoadRooms() error = %v", err)
+	}
+	if len(records) != 1 || records[0].Name != "renamed" || !records[0].SavedAt.Equal(savedAt) {
+		t.Errorf("records = %+v, want one renamed room saved at %v", records, savedAt)
+	}
+}
+
+func TestSQLite_GuestUser(t *testing.T) {
+	ctx := context.Background()
+	database := newTestSQLite(t)
+	queries := database.Queries(database)
+	stats := NewUserStatsRepository(database)
+	users := usecase.NewUserUsecase(queries, stats, time.Hour)
+	// 作成した時点で期限切れのゲスト
+	expiredUsers := usecase.NewUserUsecase(queries, stats, -time.Hour)
+
+	guest, err := users.CreateGuest(ctx)
+	if err != nil {
+		t.Fatalf("CreateGuest() error = %v", err)
+	}
+	expired, err := expiredUsers.CreateGuest(ctx)
+	if err != nil {
+		t.Fatalf("CreateGuest() error = %v", err)
+	}
+	upgradedLater, err := expiredUsers.CreateGuest(ctx)
+	if err != nil {
+		t.Fatalf("CreateGuest() error = %v", err)
+	}
+
+	startedAt := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	_, err = NewGameResultRepository(database).SaveGameResult(ctx, domain.GameResult{
+		GameUUID:     "00000000-0000-0000-0000-000000000001",
+		RoomID:       1,
+		Mode:         "standard",
+		Seed:         42,
+		StartedAt:    startedAt,
+		EndedAt:      startedAt.Add(time.Minute),
+		Players:      []domain.GamePlayerResult{{UserID: int(guest.UserID), Username: guest.Username, Score: 30, Rank: 1}},
+		InitialBoard: domain.NewSeededBoard(42).Board,
+	})
+	if err != nil {
+		t.Fatalf("SaveGameResult() error = %v", err)
+	}
+
+	upgraded, err := users.UpgradeGuest(ctx, int32(guest.UserID), usecase.CreateUserRequest{Username: "alice", Password: "correct1horse"})
+	if err != nil {
+		t.Fatalf("UpgradeGuest() error = %v", err)
+	}
+	if upgraded.UserID != guest.UserID {
+		t.Errorf("upgraded user ID = %d, want %d", upgraded.UserID, guest.UserID)
+	}
+	if _, err := users.UpgradeGuest(ctx, int32(guest.UserID), usecase.CreateUserRequest{Username: "alice2", Password: "correct1horse"}); !errors.Is(err, usecase.ErrNotGuest) {
+		t.Errorf("UpgradeGuest(full account) error = %v, want ErrNotGuest", err)
+	}
+	if _, err := users.UpgradeGuest(ctx, int32(upgradedLater.UserID), usecase.CreateUserRequest{Username: "alice", Password: "correct1horse"}); !errors.Is(err, usecase.ErrUsernameTaken) {
+		t.Errorf("UpgradeGuest(taken username) error = %v, want ErrUsernameTaken", err)
+	}
+	if _, err := users.UpgradeGuest(ctx, int32(upgradedLater.UserID), usecase.CreateUserRequest{Username: "bob", Password: "correct1horse"}); err != nil {
+		t.Fatalf("UpgradeGuest() error = %v", err)
+	}
+
+	// 期限切れでも本登録済みのユーザーは削除しない
+	deleted, err := users.DeleteExpiredGuests(ctx)
+	if err != nil {
+		t.Fatalf("DeleteExpiredGuests() error = %v", err)
+	}
+	if deleted != 1 {
+		t.Errorf("DeleteExpiredGuests() = %d, want 1", deleted)
+	}
+	if _, err := users.GetUserProfile(ctx, int(expired.UserID)); !errors.Is(err, usecase.ErrUserNotFound) {
+		t.Errorf("GetUserProfile(expired guest) error = %v, want ErrUserNotFound", err)
+	}
+
+	// ゲストの間の成績を引き継ぐ
+	profile, err := users.GetUserProfile(ctx, int(guest.UserID))
+	if err != nil {
+		t.Fatalf("GetUserProfile() error = %v", err)
+	}
+	if profile.Username != "alice" || profile.IsGuest || profile.Stats.GamesPlayed != 1 || profile.Stats.BestScore != 30 {
+		t.Errorf("profile = %+v, want alice with the guest's game", profile)
+	}
+	if _, err := users.AuthenticateUser(ctx, "alice", "correct1horse"); err != nil {
+		t.Errorf("AuthenticateUser() error = %v", err)
+	}
+}
+
+// 期限切れのゲストを掃除しても、一緒に遊んだプレイヤーのリプレイ・レーティングの履歴は残ること
+func TestSQLite_ExpiredGuestKeepsGameHistory(t *testing.T) {
+	ctx := context.Background()
+	database := newTestSQLite(t)
+	queries := database.Queries(database)
+	users := usecase.NewUserUsecase(queries, NewUserStatsRepository(database), -time.Hour)
+	alice := createTestUser(t, queries, "alice")
+	guest, err := users.CreateGuest(ctx)
+	if err != nil {
+		t.Fatalf("CreateGuest() error = %v", err)
+	}
+	err = queries.CreateRefreshToken(ctx, db.CreateRefreshTokenParams{
+		UserID:    int32(guest.UserID),
+		FamilyID:  "guest-session",
+		TokenHash: "guest-token",
+		ExpiresAt: time.Now().Add(time.Hour),
+		CreatedAt: time.Now(),
+	})
+	if err != nil {
+		t.Fatalf("CreateRefreshToken() error = %v", err)
+	}
+
+	startedAt := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	gameID, err := NewGameResultRepository(database).SaveGameResult(ctx, domain.GameResult{
+		GameUUID:  "00000000-0000-0000-0000-000000000001",
+		RoomID:    1,
+		Mode:      domain.GameModeStandard,
+		Seed:      42,
+		StartedAt: startedAt,
+		EndedAt:   startedAt.Add(time.Minute),
+		Players: []domain.GamePlayerResult{
+			{UserID: alice, Username: "alice", Rank: 1},
+			{UserID: int(guest.UserID), Username: guest.Username, Rank: 1},
+		},
+		InitialBoard: domain.NewSeededBoard(42).Board,
+	})
+	if err != nil {
+		t.Fatalf("SaveGameResult() error = %v", err)
+	}
+
+	cleaned, err := users.DeleteExpiredGuests(ctx)
+	if err != nil {
+		t.Fatalf("DeleteExpiredGuests() error = %v", err)
+	}
+	if cleaned != 1 {
+		t.Errorf("DeleteExpiredGuests() = %d, want 1", cleaned)
+	}
+
+	res, err := usecase.NewGameUsecase(NewGameResultRepository(database)).GetGameReplay(ctx, gameID)
+	if err != nil {
+		t.Fatalf("GetGameReplay() error = %v", err)
+	}
+	anonymized := fmt.Sprintf("guest-deleted-%d", guest.UserID)
+	if len(res.Replay.Players) != 2 || res.Replay.Players[1].Username != anonymized {
+		t.Errorf("replay players = %+v, want alice and %s", res.Replay.Players, anonymized)
+	}
+	var history int
+	if err := database.QueryRowContext(ctx, "SELECT COUNT(*) FROM rating_history WHERE game_id = ?", gameID).Scan(&history); err != nil {
+		t.Fatalf("count rating_history error = %v", err)
+	}
+	if history != 2 {
+		t.Errorf("rating_history rows = %d, want 2", history)
+	}
+
+	// 匿名化したゲストのセッションは失効し、本登録・再度の掃除の対象にならない
+	if revoked, err := queries.CountRevokedRefreshTokens(ctx, "guest-session"); err != nil || revoked != 1 {
+		t.Errorf("CountRevokedRefreshTokens() = %d, %v, want 1", revoked, err)
+	}
+	if _, err := users.UpgradeGuest(ctx, int32(guest.UserID), usecase.CreateUserRequest{Username: "bob", Password: "correct1horse"}); !errors.Is(err, usecase.ErrNotGuest) {
+		t.Errorf("UpgradeGuest(anonymized guest) error = %v, want ErrNotGuest", err)
+	}
+	if cleaned, err := users.DeleteExpiredGuests(ctx); err != nil || cleaned != 0 {
+		t.Errorf("DeleteExpiredGuests() = %d, %v, want 0", cleaned, err)
+	}
+}
+
+func TestSQLite_UserIdentity(t *testing.T) {
+	ctx := context.Background()
+	database := newTestSQLite(t)
+	queries := database.Queries(database)
+	users := usecase.NewUserUsecase(queries, NewUserStatsRepository(database), time.Hour)
+
+	// 初回ログインで外部アカウントの名前のuserを作り、2回目は同じuser
+	identity := usecase.ExternalIdentity{Provider: "traq", Subject: "sub-1", Username: "alice"}
+	first, err := users.LoginWithIdentity(ctx, identity)
+	if err != nil {
+		t.Fatalf("LoginWithIdentity() error = %v", err)
+	}
+	second, err := users.LoginWithIdentity(ctx, identity)
+	if err != nil || second.UserID != first.UserID {
+		t.Errorf("LoginWithIdentity(again) = %+v, %v, want user %d", second, err, first.UserID)
+	}
+
+	// パスワードで登録済みの名前は、LinkByUsernameでも乗っ取れない
+	registered, err := users.RegisterUser(ctx, usecase.CreateUserRequest{Username: "bob", Password: "correct1horse"})
+	if err != nil {
+		t.Fatalf("RegisterUser() error = %v", err)
+	}
+	bob := usecase.ExternalIdentity{Provider: "traq", Subject: "sub-2", Username: "bob", LinkByUsername: true}
+	if _, err := users.LoginWithIdentity(ctx, bob); !errors.Is(err, usecase.ErrUsernameTaken) {
+		t.Errorf("LoginWithIdentity(registered name) error = %v, want ErrUsernameTaken", err)
+	}
+	// ログインしてから明示的に紐付ける
+	if err := users.LinkIdentity(ctx, int32(registered.UserID), bob); err != nil {
+		t.Fatalf("LinkIdentity() error = %v", err)
+	}
+	if linked, err := users.LoginWithIdentity(ctx, bob); err != nil || linked.UserID != registered.UserID {
+		t.Errorf("LoginWithIdentity(linked) = %+v, %v, want user %d", linked, err, registered.UserID)
+	}
+	if err := users.LinkIdentity(ctx, int32(first.UserID), bob); !errors.Is(err, usecase.ErrIdentityLinked) {
+		t.Errorf("LinkIdentity(linked to another user) error = %v, want ErrIdentityLinked", err)
+	}
+
+	// X-Forwarded-Userで作られたuserには、LinkByUsernameの場合だけ名前で紐付ける
+	forwarded, err := users.CreateUserWithoutPassword(ctx, usecase.CreateUserRequest{Username: "carol"})
+	if err != nil {
+		t.Fatalf("CreateUserWithoutPassword() error = %v", err)
+	}
+	carol := usecase.ExternalIdentity{Provider: "traq", Subject: "sub-3", Username: "carol"}
+	if _, err := users.LoginWithIdentity(ctx, carol); !errors.Is(err, usecase.ErrUsernameTaken) {
+		t.Errorf("LoginWithIdentity(forwarded user without LinkByUsername) error = %v, want ErrUsernameTaken", err)
+	}
+	carol.LinkByUsername = true
+	if linked, err := users.LoginWithIdentity(ctx, carol); err != nil || linked.UserID != forwarded.UserID {
+		t.Errorf("LoginWithIdentity(forwarded user) = %+v, %v, want user %d", linked, err, forwarded.UserID)
+	}
+
+	// ゲストは本登録してから紐付ける
+	guest, err := users.CreateGuest(ctx)
+	if err != nil {
+		t.Fatalf("CreateGuest() error = %v", err)
+	}
+	dave := usecase.ExternalIdentity{Provider: "traq", Subject: "sub-4", Username: "dave"}
+	if err := users.LinkIdentity(ctx, int32(guest.UserID), dave); !errors.Is(err, usecase.ErrGuestCannotLink) {
+		t.Errorf("LinkIdentity(guest) error = %v, want ErrGuestCannotLink", err)
+	}
+}
+
+// SQLiteでは失敗したマイグレーションが記録ごとロールバックされ、修正後に再実行できること
+func TestSQLite_FailedMigrationIsRolledBack(t *testing.T) {
+	ctx := context.Background()
+	database, err := OpenSQLite(":memory:")
+	if err != nil {
+		t.Fatalf("OpenSQLite() error = %v", err)
+	}
+	defer database.Close()
+
+	broken := fstest.MapFS{
+		"0001_create_widget.sql": {Data: []byte("CREATE TABLE widget (id INTEGER PRIMARY KEY);\nINSERT INTO missing_table VALUES (1);\n")},
+	}
+	migrator, err := NewMigrator(database, broken)
+	if err != nil {
+		t.Fatalf("NewMigrator() error = %v", err)
+	}
+	if _, err := migrator.Migrate(ctx, false); err == nil {
+		t.Fatal("Migrate() with a failing statement succeeded")
+	}
+
+	var tables, recorded int
+	if err := database.QueryRowContext(ctx, "SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = 'widget'").Scan(&tables); err != nil {
+		t.Fatalf("select sqlite_master error = %v", err)
+	}
+	if err := database.QueryRowContext(ctx, "SELECT COUNT(*) FROM schema_migrations").Scan(&recorded); err != nil {
+		t.Fatalf("select schema_migrations error = %v", err)
+	}
+	if tables != 0 || recorded != 0 {
+		t.Fatalf("widget tables = %d, schema_migrations rows = %d after failure, want both 0", tables, recorded)
+	}
+
+	fixed := fstest.MapFS{
+		"0001_create_widget.sql": {Data: []byte("CREATE TABLE widget (id INTEGER PRIMARY KEY);\n")},
+	}
+	migrator, err = NewMigrator(database, fixed)
+	if err != nil {
+		t.Fatalf("NewMigrator() error = %v", err)
+	}
+	applied, err := migrator.Migrate(ctx, false)
+	if err != nil {
+		t.Fatalf("Migrate() after fixing the migration error = %v", err)
+	}
+	if len(applied) != 1 {
+		t.Errorf("Migrate() applied %d migrations, want 1", len(applied))
+	}
+}
@@ -0,0 +1,159 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"sync/atomic"
+	"time"
+	"unicode/utf8"
+
+	"github.com/kaitoyama/kaitoyama-server-template/internal/db"
+	"github.com/kaitoyama/kaitoyama-server-template/internal/domain"
+	"github.com/rs/zerolog/log"
+)
+
+// maxExpressionLength はformula_submission.expressionの最大文字数
+const maxExpressionLength = 255
+
+// SubmissionLog は数式の提出記録をキューに積み、1つのゴルーチンでformula_submissionへ書き込む
+// キューが溢れた場合は提出の処理を遅らせないよう記録を捨てる
+type SubmissionLog struct {
+	queries      db.Querier
+	queue        chan domain.FormulaSubmission
+	writeTimeout time.Duration
+	mutex        sync.RWMutex
+	closed       bool
+	done         chan struct{}
+	dropped      atomic.Int64
+}
+
+// NewSubmissionLog starts the background writer. queueSizeは書き込み待ちにできる記録の数
+func NewSubmissionLog(database *Database, queueSize int) *SubmissionLog {
+	return newSubmissionLog(database.Queries(database), queueSize)
+}
+
+func newSubmissionLog(queries db.Querier, queueSize int) *SubmissionLog {
+	l := &SubmissionLog{
+		queries:      queries,
+		queue:        make(chan domain.FormulaSubmission, queueSize),
+		writeTimeout: 5 * time.Second,
+		done:         make(chan struct{}),
+	}
+	go l.writeLoop()
+	return l
+}
+
+// LogSubmission はブロックせずに記録をキューへ積む
+func (l *SubmissionLog) LogSubmission(submission domain.FormulaSubmission) {
+	l.mutex.RLock()
+	defer l.mutex.RUnlock()
+	if l.closed {
+		return
+	}
+
+	select {
+	case l.queue <- submission:
+	default:
+		dropped := l.dropped.Add(1)
+		log.Warn().Int64("dropped", dropped).Int("room_id", submission.RoomID).Msg("Submission log queue is full, dropping record")
+	}
+}
+
+// Dropped returns the number of records dropped because the queue was full
+func (l *SubmissionLog) Dropped() int64 {
+	return l.dropped.Load()
+}
+
+// Close はキューに残った記録を書き込んでから終了する
+func (l *SubmissionLog) Close() {
+	l.mutex.Lock()
+	if l.closed {
+		l.mutex.Unlock()
+		return
+	}
+	l.closed = true
+	close(l.queue)
+	l.mutex.Unlock()
+
+	<-l.done
+}
+
+func (l *SubmissionLog) writeLoop() {
+	defer close(l.done)
+	for submission := range l.queue {
+		if err := l.write(submission); err != nil {
+			log.Error().Err(err).Int("room_id", submission.RoomID).Int("user_id", submission.UserID).Msg("Failed to write submission log")
+		}
+	}
+}
+
+func (l *SubmissionLog) write(submission domain.FormulaSubmission) error {
+	regions, err := json.Marshal(matchedRegions(submission.Matches))
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), l.writeTimeout)
+	defer cancel()
+
+	return l.queries.CreateFormulaSubmission(ctx, db.CreateFormulaSubmissionParams{
+		GameUuid:         submission.GameUUID,
+		RoomID:           int32(submission.RoomID),
+		UserID:           int32(submission.UserID),
+		SubmittedVersion: int32(submission.SubmittedVersion),
+		BoardVersion:     int32(submission.BoardVersion),
+		Expression:       truncateExpression(submission.Expression),
+		Outcome:          string(submission.Outcome),
+		MatchedRegions:   regions,
+		GainedScore:      int32(submission.GainedScore),
+		LatencyUs:        int32(submission.Latency.Microseconds()),
+		SubmittedAt:      submission.SubmittedAt,
+	})
+}
+
+// matchedRegion はmatched_regions・game_move.changesカラムに保存する1行・1列分の一致箇所
+type matchedRegion struct {
+	LineType  string   `json:"line_type"`
+	Index     int      `json:"index"`
+	Positions [][2]int `json:"positions"` // [row, col]
+}
+
+func matchedRegions(matches []domain.Matches) []matchedRegion {
+	regions := make([]matchedRegion, 0, len(matches))
+	for _, m := range matches {
+		positions := make([][2]int, 0, len(m.Positions))
+		for _, p := range m.Positions {
+			positions = append(positions, [2]int{p.Row, p.Col})
+		}
+		regions = append(regions, matchedRegion{
+			LineType:  m.Linetype,
+			Index:     m.Index,
+			Positions: positions,
+		})
+	}
+	return regions
+}
+
+func toMatches(regions []matchedRegion) []domain.Matches {
+	matches := make([]domain.Matches, 0, len(regions))
+	for _, region := range regions {
+		positions := make([]domain.Position, 0, len(region.Positions))
+		for _, p := range region.Positions {
+			positions = append(positions, domain.Position{Row: p[0], Col: p[1]})
+		}
+		matches = append(matches, domain.Matches{
+			Linetype:  region.LineType,
+			Index:     region.Index,
+			Positions: positions,
+		})
+	}
+	return matches
+}
+
+func truncateExpression(expression string) string {
+	if utf8.RuneCountInString(expression) <= maxExpressionLength {
+		return expression
+	}
+	return string([]rune(expression)[:maxExpressionLength])
+}
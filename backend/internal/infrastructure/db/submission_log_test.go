@@ -0,0 +1,79 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"testing"
+
+	"github.com/kaitoyama/kaitoyama-server-template/internal/db"
+	"github.com/kaitoyama/kaitoyama-server-template/internal/domain"
+)
+
+// recordingQuerier はCreateFormulaSubmissionの引数を記録するdb.Querierのスタブ
+type recordingQuerier struct {
+	db.Querier
+	mutex   sync.Mutex
+	block   chan struct{}
+	written []db.CreateFormulaSubmissionParams
+}
+
+func (q *recordingQuerier) CreateFormulaSubmission(ctx context.Context, arg db.CreateFormulaSubmissionParams) error {
+	if q.block != nil {
+		<-q.block
+	}
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	q.written = append(q.written, arg)
+	return nil
+}
+
+func TestSubmissionLog_CloseFlushesQueuedRecords(t *testing.T) {
+	querier := &recordingQuerier{}
+	submissionLog := newSubmissionLog(querier, 16)
+
+	submissionLog.LogSubmission(domain.FormulaSubmission{
+		GameUUID:   "game-1",
+		RoomID:     1,
+		UserID:     2,
+		Expression: "1+2+3+4",
+		Outcome:    domain.MoveSuccess,
+		Matches: []domain.Matches{
+			{Linetype: "row", Index: 0, Positions: []domain.Position{{Row: 0, Col: 0}, {Row: 0, Col: 1}}},
+		},
+	})
+	submissionLog.LogSubmission(domain.FormulaSubmission{GameUUID: "game-1", Outcome: domain.MoveNoRegion})
+	submissionLog.Close()
+	// Close後の記録は無視される
+	submissionLog.LogSubmission(domain.FormulaSubmission{GameUUID: "game-1"})
+
+	if len(querier.written) != 2 {
+		t.Fatalf("wrote %d records, want 2", len(querier.written))
+	}
+	var regions []matchedRegion
+	if err := json.Unmarshal(querier.written[0].MatchedRegions, &regions); err != nil {
+		t.Fatalf("matched_regions is not valid JSON: %v", err)
+	}
+	if len(regions) != 1 || regions[0].LineType != "row" || regions[0].Positions[1] != [2]int{0, 1} {
+		t.Errorf("matched_regions = %s", querier.written[0].MatchedRegions)
+	}
+	if string(querier.written[1].MatchedRegions) != "[]" {
+		t.Errorf("matched_regions without matches = %s, want []", querier.written[1].MatchedRegions)
+	}
+}
+
+func TestSubmissionLog_DropsWhenQueueIsFull(t *testing.T) {
+	querier := &recordingQuerier{block: make(chan struct{})}
+	submissionLog := newSubmissionLog(querier, 1)
+
+	// 書き込み中の1件とキューの1件を超えた分は捨てられる（呼び出し側はブロックしない）
+	for i := 0; i < 10; i++ {
+		submissionLog.LogSubmission(domain.FormulaSubmission{RoomID: i})
+	}
+	if submissionLog.Dropped() < 8 {
+		t.Errorf("Dropped() = %d, want at least 8", submissionLog.Dropped())
+	}
+
+	close(querier.block)
+	submissionLog.Close()
+}
@@ -0,0 +1,48 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/kaitoyama/kaitoyama-server-template/internal/db"
+	"github.com/kaitoyama/kaitoyama-server-template/internal/domain"
+)
+
+type userStatsRepository struct {
+	queries db.Querier
+}
+
+func NewUserStatsRepository(database *Database) domain.UserStatsRepository {
+	return &userStatsRepository{
+		queries: database.Queries(database),
+	}
+}
+
+// GetUserStats はuser_statsの行を返す。まだゲームを終えていないユーザーは0件の成績を返す
+func (r *userStatsRepository) GetUserStats(ctx context.Context, userID int) (domain.UserStats, error) {
+	row, err := r.queries.GetUserStats(ctx, int32(userID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return domain.UserStats{UserID: userID}, nil
+	}
+	if err != nil {
+		return domain.UserStats{}, fmt.Errorf("failed to get stats for user %d: %w", userID, err)
+	}
+	return userStatsFromRow(row), nil
+}
+
+func userStatsFromRow(row db.UserStat) domain.UserStats {
+	return domain.UserStats{
+		UserID:              int(row.UserID),
+		GamesPlayed:         int(row.GamesPlayed),
+		Wins:                int(row.Wins),
+		BestScore:           int(row.BestScore),
+		TotalScore:          int(row.TotalScore),
+		CurrentStreak:       int(row.CurrentStreak),
+		LongestStreak:       int(row.LongestStreak),
+		AcceptedFormulas:    int(row.AcceptedFormulas),
+		RejectedFormulas:    int(row.RejectedFormulas),
+		LongestAnswerStreak: int(row.LongestAnswerStreak),
+	}
+}
@@ -0,0 +1,62 @@
+package websocket
+
+import (
+	"encoding/json"
+	"sync"
+)
+
+// バックプレーンで配送するメッセージの種類
+const (
+	BackplaneRoom = "room"
+	BackplaneUser = "user"
+)
+
+// BackplaneMessage is a room or user event relayed between server instances.
+// Eventはシーケンス番号を含まないWebSocketEventのJSON
+type BackplaneMessage struct {
+	Kind   string          `json:"kind"`
+	RoomID int             `json:"room_id,omitempty"`
+	UserID int             `json:"user_id,omitempty"`
+	Seq    uint64          `json:"seq,omitempty"` // 0の場合は受信側でroomごとに採番する
+	Event  json.RawMessage `json:"event"`
+}
+
+// Backplane delivers published messages to every subscribed Manager, including the publisher.
+// 同じroomのメッセージは全購読者に同じ順序で届くこと
+type Backplane interface {
+	Publish(msg BackplaneMessage) error
+	Subscribe(handler func(BackplaneMessage))
+	Close() error
+}
+
+// InProcessBackplane は単一インスタンス用のバックプレーン
+// Publishした時点で同期的にハンドラーを呼び出すため、送信順がそのまま配信順になる
+type InProcessBackplane struct {
+	mutex   sync.RWMutex
+	handler func(BackplaneMessage)
+}
+
+func NewInProcessBackplane() *InProcessBackplane {
+	return &InProcessBackplane{}
+}
+
+func (b *InProcessBackplane) Publish(msg BackplaneMessage) error {
+	b.mutex.RLock()
+	handler := b.handler
+	b.mutex.RUnlock()
+
+	if handler != nil {
+		handler(msg)
+	}
+	return nil
+}
+
+func (b *InProcessBackplane) Subscribe(handler func(BackplaneMessage)) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	b.handler = handler
+}
+
+func (b *InProcessBackplane) Close() error {
+	return nil
+}
@@ -0,0 +1,327 @@
+package websocket
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// NetworkBackplane はTCPでBrokerに接続し、複数インスタンス間でイベントを中継する
+// roomイベントのシーケンス番号はBrokerが採番するため、全インスタンスで同じ番号・同じ順序になる
+// 切断中にPublishされたメッセージは保持しておき、再接続時に送信順のまま送り直す
+type NetworkBackplane struct {
+	addr           string
+	mutex          sync.Mutex
+	conn           net.Conn
+	encoder        *json.Encoder
+	handler        func(BackplaneMessage)
+	pending        []BackplaneMessage // 切断中に送信できなかったメッセージ
+	maxPending     int
+	writeTimeout   time.Duration
+	reconnectDelay time.Duration
+	done           chan struct{}
+	closeOnce      sync.Once
+}
+
+// DialBackplane connects to the broker at addr.
+// 接続が切れた場合はCloseされるまで再接続を試みる
+func DialBackplane(addr string) (*NetworkBackplane, error) {
+	conn, err := net.Dial("tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to backplane broker: %w", err)
+	}
+
+	b := &NetworkBackplane{
+		addr:           addr,
+		conn:           conn,
+		encoder:        json.NewEncoder(conn),
+		maxPending:     1024,
+		writeTimeout:   5 * time.Second,
+		reconnectDelay: time.Second,
+		done:           make(chan struct{}),
+	}
+	go b.readLoop(conn)
+
+	return b, nil
+}
+
+// Publish sends msg to the broker.
+// 切断中は再接続まで保持し、保持できる件数を超えた場合はエラーを返す
+func (b *NetworkBackplane) Publish(msg BackplaneMessage) error {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if b.conn == nil {
+		return b.enqueuePending(msg)
+	}
+	if err := b.write(msg); err != nil {
+		// 書き込みに失敗した接続は破棄し、readLoopの再接続後に送り直す
+		log.Warn().Err(err).Str("addr", b.addr).Msg("Failed to publish to backplane broker, will retry after reconnect")
+		b.conn.Close()
+		b.conn = nil
+		return b.enqueuePending(msg)
+	}
+	return nil
+}
+
+// enqueuePending は再接続後に送るメッセージを積む（b.mutexを保持して呼ぶこと）
+func (b *NetworkBackplane) enqueuePending(msg BackplaneMessage) error {
+	if len(b.pending) >= b.maxPending {
+		return fmt.Errorf("backplane broker %s is not connected and %d messages are already pending", b.addr, len(b.pending))
+	}
+	b.pending = append(b.pending, msg)
+	return nil
+}
+
+// write は現在の接続にメッセージを書き込む（b.mutexを保持して呼ぶこと）
+func (b *NetworkBackplane) write(msg BackplaneMessage) error {
+	if err := b.conn.SetWriteDeadline(time.Now().Add(b.writeTimeout)); err != nil {
+		return err
+	}
+	return b.encoder.Encode(msg)
+}
+
+func (b *NetworkBackplane) Subscribe(handler func(BackplaneMessage)) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	b.handler = handler
+}
+
+func (b *NetworkBackplane) Close() error {
+	var err error
+	b.closeOnce.Do(func() {
+		close(b.done)
+		b.mutex.Lock()
+		defer b.mutex.Unlock()
+		if b.conn != nil {
+			err = b.conn.Close()
+			b.conn = nil
+		}
+	})
+	return err
+}
+
+// readLoop はBrokerからのメッセージを受信順にハンドラーへ渡す（単一ゴルーチンで順序を保証）
+func (b *NetworkBackplane) readLoop(conn net.Conn) {
+	for {
+		decoder := json.NewDecoder(conn)
+		for {
+			var msg BackplaneMessage
+			if err := decoder.Decode(&msg); err != nil {
+				break
+			}
+
+			b.mutex.Lock()
+			handler := b.handler
+			b.mutex.Unlock()
+			if handler != nil {
+				handler(msg)
+			}
+		}
+
+		conn = b.reconnect()
+		if conn == nil {
+			return
+		}
+	}
+}
+
+// reconnect はBrokerへの再接続を試みる。Closeされた場合はnilを返す
+func (b *NetworkBackplane) reconnect() net.Conn {
+	b.mutex.Lock()
+	if b.conn != nil {
+		b.conn.Close()
+		b.conn = nil
+	}
+	b.mutex.Unlock()
+
+	for {
+		select {
+		case <-b.done:
+			return nil
+		case <-time.After(b.reconnectDelay):
+		}
+
+		conn, err := net.Dial("tcp", b.addr)
+		if err != nil {
+			log.Warn().Err(err).Str("addr", b.addr).Msg("Failed to reconnect to backplane broker")
+			continue
+		}
+
+		b.mutex.Lock()
+		select {
+		case <-b.done:
+			b.mutex.Unlock()
+			conn.Close()
+			return nil
+		default:
+		}
+		b.conn = conn
+		b.encoder = json.NewEncoder(conn)
+		// 切断中に積んだメッセージを、新しいPublishより先に送信順のまま送る
+		flushed := 0
+		for _, msg := range b.pending {
+			if err := b.write(msg); err != nil {
+				break
+			}
+			flushed++
+		}
+		b.pending = b.pending[flushed:]
+		if len(b.pending) > 0 {
+			b.conn = nil
+			b.mutex.Unlock()
+			conn.Close()
+			log.Warn().Str("addr", b.addr).Int("pending", len(b.pending)).Msg("Failed to flush pending messages to backplane broker")
+			continue
+		}
+		b.mutex.Unlock()
+
+		log.Info().Str("addr", b.addr).Int("flushed", flushed).Msg("Reconnected to backplane broker")
+		return conn
+	}
+}
+
+// Broker はNetworkBackplane同士を中継する軽量なメッセージブローカー
+// 受信したメッセージを1つのロック内で採番・配送するため、全購読者が同じ順序で受信する
+type Broker struct {
+	listener    net.Listener
+	mutex       sync.Mutex
+	subscribers map[*brokerConn]struct{}
+	roomSeqs    map[int]uint64
+	queueSize   int
+	closed      bool
+}
+
+type brokerConn struct {
+	conn      net.Conn
+	send      chan BackplaneMessage
+	closeOnce sync.Once
+}
+
+func (c *brokerConn) close() {
+	c.closeOnce.Do(func() {
+		close(c.send)
+		c.conn.Close()
+	})
+}
+
+// StartBroker listens on addr and relays messages between connected backplanes
+func StartBroker(addr string) (*Broker, error) {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to start backplane broker: %w", err)
+	}
+
+	b := &Broker{
+		listener:    listener,
+		subscribers: make(map[*brokerConn]struct{}),
+		roomSeqs:    make(map[int]uint64),
+		queueSize:   1024,
+	}
+	go b.acceptLoop()
+
+	log.Info().Str("addr", listener.Addr().String()).Msg("Backplane broker started")
+	return b, nil
+}
+
+// Addr returns the address the broker is listening on
+func (b *Broker) Addr() string {
+	return b.listener.Addr().String()
+}
+
+func (b *Broker) Close() error {
+	b.mutex.Lock()
+	b.closed = true
+	for sub := range b.subscribers {
+		sub.close()
+	}
+	b.subscribers = make(map[*brokerConn]struct{})
+	b.mutex.Unlock()
+
+	return b.listener.Close()
+}
+
+func (b *Broker) acceptLoop() {
+	for {
+		conn, err := b.listener.Accept()
+		if err != nil {
+			if !errors.Is(err, net.ErrClosed) {
+				log.Error().Err(err).Msg("Backplane broker failed to accept connection")
+			}
+			return
+		}
+
+		sub := &brokerConn{
+			conn: conn,
+			send: make(chan BackplaneMessage, b.queueSize),
+		}
+
+		b.mutex.Lock()
+		if b.closed {
+			b.mutex.Unlock()
+			conn.Close()
+			return
+		}
+		b.subscribers[sub] = struct{}{}
+		b.mutex.Unlock()
+
+		go b.writeLoop(sub)
+		go b.readLoop(sub)
+	}
+}
+
+func (b *Broker) readLoop(sub *brokerConn) {
+	decoder := json.NewDecoder(sub.conn)
+	for {
+		var msg BackplaneMessage
+		if err := decoder.Decode(&msg); err != nil {
+			break
+		}
+		b.publish(msg)
+	}
+	b.removeSubscriber(sub)
+}
+
+func (b *Broker) writeLoop(sub *brokerConn) {
+	encoder := json.NewEncoder(sub.conn)
+	for msg := range sub.send {
+		if err := encoder.Encode(msg); err != nil {
+			b.removeSubscriber(sub)
+			return
+		}
+	}
+}
+
+// publish はroomメッセージにシーケンス番号を付け、全購読者の送信キューに積む
+func (b *Broker) publish(msg BackplaneMessage) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if msg.Kind == BackplaneRoom {
+		b.roomSeqs[msg.RoomID]++
+		msg.Seq = b.roomSeqs[msg.RoomID]
+	}
+
+	for sub := range b.subscribers {
+		select {
+		case sub.send <- msg:
+		default:
+			// 追いつけない購読者は切断する（再接続後にクライアント側で再同期される）
+			log.Warn().Str("remote_addr", sub.conn.RemoteAddr().String()).Msg("Backplane subscriber too slow, disconnecting")
+			delete(b.subscribers, sub)
+			sub.close()
+		}
+	}
+}
+
+func (b *Broker) removeSubscriber(sub *brokerConn) {
+	b.mutex.Lock()
+	delete(b.subscribers, sub)
+	b.mutex.Unlock()
+	sub.close()
+}
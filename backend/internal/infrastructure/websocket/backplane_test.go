@@ -0,0 +1,249 @@
+package websocket
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+// waitFor はcondが満たされるまで待つ
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("timed out waiting for condition")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestBroker_DeliversRoomMessagesInSameOrderToAllSubscribers(t *testing.T) {
+	broker, err := StartBroker("127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("StartBroker() error = %v", err)
+	}
+	defer broker.Close()
+
+	const instances = 3
+	const perInstance = 50
+
+	var mutex sync.Mutex
+	received := make([][]BackplaneMessage, instances)
+	backplanes := make([]*NetworkBackplane, instances)
+	for i := range backplanes {
+		bp, err := DialBackplane(broker.Addr())
+		if err != nil {
+			t.Fatalf("DialBackplane() error = %v", err)
+		}
+		defer bp.Close()
+		index := i
+		bp.Subscribe(func(msg BackplaneMessage) {
+			mutex.Lock()
+			received[index] = append(received[index], msg)
+			mutex.Unlock()
+		})
+		backplanes[i] = bp
+	}
+
+	// 全インスタンスから2つのroomへ並行して送信
+	var wg sync.WaitGroup
+	for i, bp := range backplanes {
+		wg.Add(1)
+		go func(i int, bp *NetworkBackplane) {
+			defer wg.Done()
+			for n := 0; n < perInstance; n++ {
+				event, _ := json.Marshal(fmt.Sprintf("%d-%d", i, n))
+				if err := bp.Publish(BackplaneMessage{Kind: BackplaneRoom, RoomID: 1 + n%2, Event: event}); err != nil {
+					t.Errorf("Publish() error = %v", err)
+				}
+			}
+		}(i, bp)
+	}
+	wg.Wait()
+
+	total := instances * perInstance
+	waitFor(t, func() bool {
+		mutex.Lock()
+		defer mutex.Unlock()
+		for _, msgs := range received {
+			if len(msgs) < total {
+				return false
+			}
+		}
+		return true
+	})
+
+	mutex.Lock()
+	defer mutex.Unlock()
+	for i := 1; i < instances; i++ {
+		if !reflect.DeepEqual(received[0], received[i]) {
+			t.Fatalf("subscriber %d received a different order than subscriber 0", i)
+		}
+	}
+
+	// roomごとに1から連番で採番されている
+	lastSeq := make(map[int]uint64)
+	for _, msg := range received[0] {
+		if msg.Seq != lastSeq[msg.RoomID]+1 {
+			t.Fatalf("room %d: got seq %d after %d", msg.RoomID, msg.Seq, lastSeq[msg.RoomID])
+		}
+		lastSeq[msg.RoomID] = msg.Seq
+	}
+}
+
+func TestManager_RoomAndUserEventsReachOtherInstance(t *testing.T) {
+	broker, err := StartBroker("127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("StartBroker() error = %v", err)
+	}
+	defer broker.Close()
+
+	managers := make([]*Manager, 2)
+	for i := range managers {
+		bp, err := DialBackplane(broker.Addr())
+		if err != nil {
+			t.Fatalf("DialBackplane() error = %v", err)
+		}
+		managers[i] = NewManager()
+		managers[i].SetBackplane(bp)
+		defer bp.Close()
+	}
+
+	// インスタンスBにだけroom 1の参加者が接続している
+	roomID := 1
+	client := &Client{
+		ID:     "client-b",
+		UserID: 42,
+		RoomID: &roomID,
+		send:   make(chan []byte, 8),
+		done:   make(chan struct{}),
+	}
+	managers[1].mutex.Lock()
+	managers[1].clients[client.ID] = client
+	managers[1].userClients[client.UserID] = map[string]*Client{client.ID: client}
+	managers[1].roomClients[roomID] = []*Client{client}
+	managers[1].mutex.Unlock()
+
+	managers[0].SendEventToRoom(roomID, NewCountdownEvent(roomID, 3))
+	managers[0].SendEventToRoom(roomID, NewCountdownEvent(roomID, 2))
+	if err := managers[0].SendEventToUser(client.UserID, NewSessionReplacedEvent(client.UserID, "other")); err != nil {
+		t.Fatalf("SendEventToUser() error = %v", err)
+	}
+
+	for _, want := range []struct {
+		event string
+		seq   uint64
+	}{
+		{EventCountdown, 1},
+		{EventCountdown, 2},
+		{EventSessionReplaced, 0},
+	} {
+		select {
+		case data := <-client.send:
+			var got sequencedEvent
+			if err := json.Unmarshal(data, &got); err != nil {
+				t.Fatalf("failed to unmarshal delivered event: %v", err)
+			}
+			if got.Event != want.event || got.Seq != want.seq {
+				t.Errorf("got event %q seq %d, want %q seq %d", got.Event, got.Seq, want.event, want.seq)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("event %q was not delivered to the other instance", want.event)
+		}
+	}
+}
+
+func TestNetworkBackplane_FlushesPendingMessagesAfterReconnect(t *testing.T) {
+	broker, err := StartBroker("127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("StartBroker() error = %v", err)
+	}
+	defer broker.Close()
+
+	receiver, err := DialBackplane(broker.Addr())
+	if err != nil {
+		t.Fatalf("DialBackplane() error = %v", err)
+	}
+	defer receiver.Close()
+	received := make(chan BackplaneMessage, 8)
+	receiver.Subscribe(func(msg BackplaneMessage) { received <- msg })
+
+	// Brokerに未接続の状態から始める
+	sender := &NetworkBackplane{
+		addr:           broker.Addr(),
+		maxPending:     2,
+		writeTimeout:   time.Second,
+		reconnectDelay: 10 * time.Millisecond,
+		done:           make(chan struct{}),
+	}
+	defer sender.Close()
+
+	for i := 1; i <= 2; i++ {
+		if err := sender.Publish(BackplaneMessage{Kind: BackplaneRoom, RoomID: 1, Event: json.RawMessage(fmt.Sprintf(`{"n":%d}`, i))}); err != nil {
+			t.Fatalf("Publish() while disconnected error = %v", err)
+		}
+	}
+	if err := sender.Publish(BackplaneMessage{Kind: BackplaneRoom, RoomID: 1, Event: json.RawMessage(`{"n":3}`)}); err == nil {
+		t.Fatal("Publish() beyond the pending limit should fail")
+	}
+
+	go sender.readLoop(sender.reconnect())
+
+	for i := 1; i <= 2; i++ {
+		select {
+		case msg := <-received:
+			if want := fmt.Sprintf(`{"n":%d}`, i); string(msg.Event) != want || msg.Seq != uint64(i) {
+				t.Errorf("got event %s seq %d, want %s seq %d", msg.Event, msg.Seq, want, i)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("pending message %d was not delivered after reconnect", i)
+		}
+	}
+}
+
+func TestManager_SequenceGapRequiresResync(t *testing.T) {
+	m := NewManager()
+
+	roomID := 1
+	client := &Client{
+		ID:     "client-a",
+		UserID: 42,
+		RoomID: &roomID,
+		send:   make(chan []byte, 8),
+		done:   make(chan struct{}),
+	}
+	m.mutex.Lock()
+	m.clients[client.ID] = client
+	m.userClients[client.UserID] = map[string]*Client{client.ID: client}
+	m.roomClients[roomID] = []*Client{client}
+	m.mutex.Unlock()
+
+	event, err := json.Marshal(NewCountdownEvent(roomID, 3))
+	if err != nil {
+		t.Fatalf("failed to marshal event: %v", err)
+	}
+	// seq 3〜4はBrokerとの切断中に配送され、このインスタンスには届かなかった
+	for _, seq := range []uint64{1, 2, 5} {
+		m.handleBackplaneMessage(BackplaneMessage{Kind: BackplaneRoom, RoomID: roomID, Seq: seq, Event: event})
+	}
+
+	for _, want := range []string{EventCountdown, EventCountdown, EventResyncRequired} {
+		var got struct {
+			Event   string                     `json:"event"`
+			Content ResyncRequiredEventContent `json:"content"`
+		}
+		if err := json.Unmarshal(<-client.send, &got); err != nil {
+			t.Fatalf("failed to unmarshal delivered event: %v", err)
+		}
+		if got.Event != want {
+			t.Fatalf("got event %q, want %q", got.Event, want)
+		}
+		if want == EventResyncRequired && (got.Content.LastSeq != 2 || got.Content.LatestSeq != 5) {
+			t.Errorf("resync_required last_seq = %d latest_seq = %d, want 2 and 5", got.Content.LastSeq, got.Content.LatestSeq)
+		}
+	}
+}
@@ -0,0 +1,35 @@
+package websocket
+
+import "testing"
+
+func TestClient_EnqueueKeepsOrderAndRejectsWhenFull(t *testing.T) {
+	client := &Client{
+		ID:   "client-1",
+		send: make(chan []byte, 2),
+		done: make(chan struct{}),
+	}
+
+	if !client.enqueue([]byte("1")) || !client.enqueue([]byte("2")) {
+		t.Fatalf("Expected enqueue to succeed while queue has capacity")
+	}
+	if client.enqueue([]byte("3")) {
+		t.Errorf("Expected enqueue to fail when queue is full")
+	}
+
+	// キューに積んだ順に取り出される
+	for _, want := range []string{"1", "2"} {
+		if got := string(<-client.send); got != want {
+			t.Errorf("Expected message %s, got %s", want, got)
+		}
+	}
+
+	// 切断後のメッセージは破棄される
+	client.close()
+	client.close()
+	if !client.enqueue([]byte("4")) {
+		t.Errorf("Expected enqueue to drop messages silently after close")
+	}
+	if len(client.send) != 0 {
+		t.Errorf("Expected no queued messages after close, got %d", len(client.send))
+	}
+}
@@ -0,0 +1,73 @@
+package websocket
+
+// bufferedEvent はシーケンス番号付きで送信済みのイベント
+type bufferedEvent struct {
+	seq  uint64
+	data []byte
+}
+
+// eventBuffer はroomごとの直近イベントを保持するリングバッファ
+// 再接続したクライアントに取りこぼしたイベントを再送するために使用する
+type eventBuffer struct {
+	lastSeq uint64
+	events  []bufferedEvent
+	head    int // 最も古いイベントの位置
+	count   int
+}
+
+func newEventBuffer(capacity int) *eventBuffer {
+	return &eventBuffer{
+		events: make([]bufferedEvent, capacity),
+	}
+}
+
+// nextSeq returns the sequence number for the next event
+func (b *eventBuffer) nextSeq() uint64 {
+	return b.lastSeq + 1
+}
+
+// push stores an event and advances the latest sequence number
+func (b *eventBuffer) push(seq uint64, data []byte) {
+	b.lastSeq = seq
+	if len(b.events) == 0 {
+		return
+	}
+
+	tail := (b.head + b.count) % len(b.events)
+	b.events[tail] = bufferedEvent{seq: seq, data: data}
+	if b.count < len(b.events) {
+		b.count++
+	} else {
+		// 満杯の場合は最も古いイベントを上書き
+		b.head = (b.head + 1) % len(b.events)
+	}
+}
+
+// oldestSeq returns the sequence number of the oldest buffered event
+func (b *eventBuffer) oldestSeq() uint64 {
+	if b.count == 0 {
+		return b.lastSeq + 1
+	}
+	return b.events[b.head].seq
+}
+
+// since returns the events after lastSeq in order.
+// バッファが取りこぼし範囲をカバーしていない場合はfalseを返す
+func (b *eventBuffer) since(lastSeq uint64) ([][]byte, bool) {
+	if lastSeq > b.lastSeq {
+		// クライアントがサーバーより先のシーケンスを持っている（サーバー再起動など）
+		return nil, false
+	}
+	if lastSeq+1 < b.oldestSeq() {
+		return nil, false
+	}
+
+	missed := make([][]byte, 0, b.lastSeq-lastSeq)
+	for i := 0; i < b.count; i++ {
+		event := b.events[(b.head+i)%len(b.events)]
+		if event.seq > lastSeq {
+			missed = append(missed, event.data)
+		}
+	}
+	return missed, true
+}
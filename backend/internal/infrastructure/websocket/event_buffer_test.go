@@ -0,0 +1,41 @@
+package websocket
+
+import "testing"
+
+func TestEventBuffer_Since(t *testing.T) {
+	buffer := newEventBuffer(3)
+	for i := 0; i < 5; i++ {
+		seq := buffer.nextSeq()
+		buffer.push(seq, []byte{byte('0' + seq)})
+	}
+	// バッファには3,4,5が残っている
+
+	tests := []struct {
+		name     string
+		lastSeq  uint64
+		expected string
+		ok       bool
+	}{
+		{"Up to date", 5, "", true},
+		{"Missed last event", 4, "5", true},
+		{"Missed all buffered events", 2, "345", true},
+		{"Gap not covered by buffer", 1, "", false},
+		{"Client ahead of server", 6, "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			missed, ok := buffer.since(tt.lastSeq)
+			if ok != tt.ok {
+				t.Fatalf("Expected ok=%v, got %v", tt.ok, ok)
+			}
+			got := ""
+			for _, data := range missed {
+				got += string(data)
+			}
+			if got != tt.expected {
+				t.Errorf("Expected events %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
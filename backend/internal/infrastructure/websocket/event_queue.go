@@ -0,0 +1,49 @@
+package websocket
+
+import "sync"
+
+// eventQueue はroom・ユーザー宛てのイベントを受け付けた順に別のゴルーチンからバックプレーンへ送信する
+// roomやManagerのロックを保持したまま呼ばれる通知からも、ロックを取り直さずに他のイベントと同じ順序で送るために使う
+type eventQueue struct {
+	mutex   sync.Mutex
+	pending []queuedEvent
+	running bool
+	send    func(msg BackplaneMessage, event WebSocketEvent)
+}
+
+type queuedEvent struct {
+	msg   BackplaneMessage
+	event WebSocketEvent
+}
+
+func newEventQueue(send func(msg BackplaneMessage, event WebSocketEvent)) *eventQueue {
+	return &eventQueue{send: send}
+}
+
+// push はイベントを積み、送信中のゴルーチンがなければ起動する（ブロックしない）
+func (q *eventQueue) push(msg BackplaneMessage, event WebSocketEvent) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	q.pending = append(q.pending, queuedEvent{msg: msg, event: event})
+	if !q.running {
+		q.running = true
+		go q.drain()
+	}
+}
+
+func (q *eventQueue) drain() {
+	for {
+		q.mutex.Lock()
+		if len(q.pending) == 0 {
+			q.running = false
+			q.mutex.Unlock()
+			return
+		}
+		next := q.pending[0]
+		q.pending = q.pending[1:]
+		q.mutex.Unlock()
+
+		q.send(next.msg, next.event)
+	}
+}
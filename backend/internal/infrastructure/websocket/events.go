@@ -1,5 +1,11 @@
 package websocket
 
+import (
+	"time"
+
+	"github.com/kaitoyama/kaitoyama-server-template/internal/domain"
+)
+
 // WebSocketイベント名の定数定義
 const (
 	// 接続関連
@@ -13,7 +19,8 @@ const (
 	EventPlayerAllReady = "player_all_ready"
 
 	// ルーム関連
-	EventRoomClosed = "room_closed"
+	EventRoomClosed      = "room_closed"
+	EventRoomListUpdated = "room_list_updated"
 
 	// ゲーム関連
 	EventGameStarted    = "game_started"
@@ -23,11 +30,38 @@ const (
 	EventBoardUpdated   = "board_updated"
 	EventResultClosed   = "result_closed"
 	EventGameEnded      = "game_ended"
+	EventGamePaused     = "game_paused"
+	EventGameResumed    = "game_resumed"
+
+	// クライアントからのメッセージへの応答
+	EventAck   = "ack"
+	EventError = "error"
+
+	// 再接続関連
+	EventResyncRequired  = "resync_required"
+	EventRoomSnapshot    = "room_snapshot"
+	EventSessionReplaced = "session_replaced"
+)
+
+// クライアントから送信できるアクション名（ルームアクションはREST APIと同じ名前を使用）
+const (
+	ActionSubmitFormula   = "SUBMIT_FORMULA"
+	ActionGetRoomSnapshot = "GET_ROOM_SNAPSHOT"
 )
 
+// クライアントからサーバーへ送信されるメッセージ
+type ClientMessage struct {
+	RequestID string `json:"request_id"`
+	Action    string `json:"action"`
+	RoomID    int    `json:"room_id"`
+	Formula   string `json:"formula,omitempty"`
+	Version   int    `json:"version,omitempty"`
+}
+
 // 統一されたWebSocketイベントの基本構造
 type WebSocketEvent struct {
 	Event   string       `json:"event"`
+	Seq     uint64       `json:"seq,omitempty"` // roomイベントのシーケンス番号（room単位で単調増加）
 	Content EventContent `json:"content"`
 }
 
@@ -104,6 +138,7 @@ type PlayerInfo struct {
 	IsReady         bool   `json:"is_ready"`
 	HasClosedResult bool   `json:"has_closed_result"`
 	Score           int    `json:"score"`
+	LatencyMs       int64  `json:"latency_ms,omitempty"` // ハートビートで計測した往復時間（未計測の場合は省略）
 }
 
 // ゲーム開始用
@@ -153,6 +188,115 @@ func (g GameStartBoardEventContent) GetEventType() string {
 	return "game_start_board"
 }
 
+// ゲーム一時停止用
+type GamePauseEventContent struct {
+	BaseEventContent
+	RemainingMs int64 `json:"remaining_ms"`
+	AutoPaused  bool  `json:"auto_paused"`
+}
+
+func (g GamePauseEventContent) GetEventType() string {
+	return "game_paused"
+}
+
+// ゲーム再開用
+type GameResumeEventContent struct {
+	BaseEventContent
+	RemainingMs int64 `json:"remaining_ms"`
+}
+
+func (g GameResumeEventContent) GetEventType() string {
+	return "game_resumed"
+}
+
+// クライアントメッセージへの成功応答用
+type AckEventContent struct {
+	RequestID string      `json:"request_id"`
+	Action    string      `json:"action"`
+	Data      interface{} `json:"data,omitempty"`
+}
+
+func (a AckEventContent) GetEventType() string {
+	return "ack"
+}
+
+// クライアントメッセージへのエラー応答用
+type ErrorEventContent struct {
+	RequestID string `json:"request_id"`
+	Action    string `json:"action"`
+	Code      int    `json:"code"`
+	Error     string `json:"error"`
+}
+
+func (e ErrorEventContent) GetEventType() string {
+	return "error"
+}
+
+// 取りこぼしイベントを再送できない場合の再同期要求用
+type ResyncRequiredEventContent struct {
+	BaseEventContent
+	LastSeq   uint64 `json:"last_seq"`
+	OldestSeq uint64 `json:"oldest_seq"`
+	LatestSeq uint64 `json:"latest_seq"`
+}
+
+func (r ResyncRequiredEventContent) GetEventType() string {
+	return "resync_required"
+}
+
+// roomの全状態のスナップショット用（再接続時・要求時に送信）
+type RoomSnapshotEventContent struct {
+	BaseEventContent
+	Room                RoomInfo           `json:"room"`
+	Connections         []PlayerConnection `json:"connections"`
+	Board               *BoardData         `json:"board,omitempty"`
+	LastCorrectPlayerID int                `json:"last_correct_player_id"`
+	StreakCount         int                `json:"streak_count"`
+	EndsAt              int64              `json:"ends_at,omitempty"` // ゲーム終了予定時刻（Unixミリ秒）
+	RemainingMs         int64              `json:"remaining_ms"`
+	Seq                 uint64             `json:"seq"` // スナップショット時点の最新シーケンス番号
+}
+
+// プレイヤーの接続状態
+type PlayerConnection struct {
+	ID          int  `json:"id"`
+	IsConnected bool `json:"is_connected"`
+}
+
+func (r RoomSnapshotEventContent) GetEventType() string {
+	return "room_snapshot"
+}
+
+// 単一セッションポリシーで古い接続が置き換えられたことの通知用
+type SessionReplacedEventContent struct {
+	BaseEventContent
+	NewClientID string `json:"new_client_id"`
+}
+
+func (s SessionReplacedEventContent) GetEventType() string {
+	return "session_replaced"
+}
+
+// ロビー（room未参加のクライアント）向けのroom一覧差分用
+type RoomListUpdatedEventContent struct {
+	BaseEventContent
+	Rooms []RoomSummary `json:"rooms"` // 変化のあったroomのみ
+}
+
+// ロビー表示用のroom概要
+type RoomSummary struct {
+	ID          int    `json:"id"`
+	Name        string `json:"name"`
+	State       string `json:"state"`
+	IsOpened    bool   `json:"is_opened"`
+	PlayerCount int    `json:"player_count"`
+	ReadyCount  int    `json:"ready_count"`
+}
+
+func (r RoomListUpdatedEventContent) GetEventType() string {
+	return "room_list_updated"
+}
+
 // イベント作成のヘルパー関数群
 
 func NewConnectionEvent(clientID string, userID int, message string, timestamp int64) WebSocketEvent {
@@ -169,6 +313,108 @@ func NewConnectionEvent(clientID string, userID int, message string, timestamp i
 	}
 }
 
+func NewAckEvent(requestID string, action string, data interface{}) WebSocketEvent {
+	return WebSocketEvent{
+		Event: EventAck,
+		Content: AckEventContent{
+			RequestID: requestID,
+			Action:    action,
+			Data:      data,
+		},
+	}
+}
+
+func NewErrorEvent(requestID string, action string, code int, message string) WebSocketEvent {
+	return WebSocketEvent{
+		Event: EventError,
+		Content: ErrorEventContent{
+			RequestID: requestID,
+			Action:    action,
+			Code:      code,
+			Error:     message,
+		},
+	}
+}
+
+func NewResyncRequiredEvent(roomID int, lastSeq, oldestSeq, latestSeq uint64) WebSocketEvent {
+	return WebSocketEvent{
+		Event: EventResyncRequired,
+		Content: ResyncRequiredEventContent{
+			BaseEventContent: BaseEventContent{
+				RoomID:  roomID,
+				Message: "Missed events are no longer available, resync required",
+			},
+			LastSeq:   lastSeq,
+			OldestSeq: oldestSeq,
+			LatestSeq: latestSeq,
+		},
+	}
+}
+
+func NewRoomSnapshotEvent(snapshot *domain.RoomSnapshot, seq uint64, latencies map[int]time.Duration) WebSocketEvent {
+	players := make([]PlayerInfo, 0, len(snapshot.Players))
+	connections := make([]PlayerConnection, 0, len(snapshot.Players))
+	for _, p := range snapshot.Players {
+		info := ConvertToPlayerInfo(p.ID, p.UserName, p.IsReady, p.HasClosedResult, p.Score)
+		info.LatencyMs = latencies[p.ID].Milliseconds()
+		players = append(players, info)
+		connections = append(connections, PlayerConnection{ID: p.ID, IsConnected: p.IsConnected})
+	}
+
+	content := RoomSnapshotEventContent{
+		BaseEventContent: BaseEventContent{
+			RoomID:    snapshot.ID,
+			Timestamp: time.Now().Unix(),
+		},
+		Room:                ConvertToRoomInfo(snapshot.ID, snapshot.Name, snapshot.State.String(), snapshot.IsOpened, players),
+		Connections:         connections,
+		LastCorrectPlayerID: snapshot.LastCorrectPlayerID,
+		StreakCount:         snapshot.StreakCount,
+		RemainingMs:         snapshot.Remaining.Milliseconds(),
+		Seq:                 seq,
+	}
+	if snapshot.Board != nil {
+		content.Board = &BoardData{
+			Content: snapshot.Board.Flatten(),
+			Version: snapshot.Board.Version,
+			Size:    snapshot.Board.Size,
+		}
+	}
+	if !snapshot.GameEndsAt.IsZero() {
+		content.EndsAt = snapshot.GameEndsAt.UnixMilli()
+	}
+
+	return WebSocketEvent{
+		Event:   EventRoomSnapshot,
+		Content: content,
+	}
+}
+
+func NewSessionReplacedEvent(userID int, newClientID string) WebSocketEvent {
+	return WebSocketEvent{
+		Event: EventSessionReplaced,
+		Content: SessionReplacedEventContent{
+			BaseEventContent: BaseEventContent{
+				UserID:  userID,
+				Message: "This session was replaced by a newer connection",
+			},
+			NewClientID: newClientID,
+		},
+	}
+}
+
+func NewRoomListUpdatedEvent(rooms []RoomSummary) WebSocketEvent {
+	return WebSocketEvent{
+		Event: EventRoomListUpdated,
+		Content: RoomListUpdatedEventContent{
+			BaseEventContent: BaseEventContent{
+				Timestamp: time.Now().Unix(),
+			},
+			Rooms: rooms,
+		},
+	}
+}
+
 func NewPlayerEvent(eventType string, userID int, userName string, roomID int) WebSocketEvent {
 	return WebSocketEvent{
 		Event: eventType,
@@ -275,6 +521,33 @@ func NewGameEndEvent(roomID int, message string) WebSocketEvent {
 	}
 }
 
+func NewGamePausedEvent(roomID int, message string, remainingMs int64, autoPaused bool) WebSocketEvent {
+	return WebSocketEvent{
+		Event: EventGamePaused,
+		Content: GamePauseEventContent{
+			BaseEventContent: BaseEventContent{
+				RoomID:  roomID,
+				Message: message,
+			},
+			RemainingMs: remainingMs,
+			AutoPaused:  autoPaused,
+		},
+	}
+}
+
+func NewGameResumedEvent(roomID int, message string, remainingMs int64) WebSocketEvent {
+	return WebSocketEvent{
+		Event: EventGameResumed,
+		Content: GameResumeEventContent{
+			BaseEventContent: BaseEventContent{
+				RoomID:  roomID,
+				Message: message,
+			},
+			RemainingMs: remainingMs,
+		},
+	}
+}
+
 func NewPlayerAllReadyEvent(roomID int, message string) WebSocketEvent {
 	return WebSocketEvent{
 		Event: EventPlayerAllReady,
@@ -340,6 +613,23 @@ func ConvertToRoomInfo(id int, name string, state string, isOpened bool, players
 	}
 }
 
+func ConvertToRoomSummary(snapshot *domain.RoomSnapshot) RoomSummary {
+	readyCount := 0
+	for _, p := range snapshot.Players {
+		if p.IsReady {
+			readyCount++
+		}
+	}
+	return RoomSummary{
+		ID:          snapshot.ID,
+		Name:        snapshot.Name,
+		State:       snapshot.State.String(),
+		IsOpened:    snapshot.IsOpened,
+		PlayerCount: len(snapshot.Players),
+		ReadyCount:  readyCount,
+	}
+}
+
 func ConvertToPlayerInfo(id int, userName string, isReady bool, hasClosedResult bool, score int) PlayerInfo {
 	return PlayerInfo{
 		ID:              id,
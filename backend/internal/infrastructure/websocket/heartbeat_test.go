@@ -0,0 +1,75 @@
+package websocket
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/coder/websocket"
+)
+
+// newHeartbeatTestManager は短い間隔でハートビートするManagerとWebSocketサーバーを起動する
+func newHeartbeatTestManager(t *testing.T) (*Manager, string) {
+	t.Helper()
+	manager := NewManager()
+	manager.heartbeatInterval = 20 * time.Millisecond
+	manager.heartbeatTimeout = 20 * time.Millisecond
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := websocket.Accept(w, r, nil)
+		if err != nil {
+			return
+		}
+		ctx, cancel := context.WithCancel(context.Background())
+		manager.AddClient("client-1", 1, nil, nil, conn, cancel)
+		// Pongはサーバー側のReadの中で処理される
+		go func() {
+			for {
+				if _, _, err := conn.Read(ctx); err != nil {
+					return
+				}
+			}
+		}()
+	}))
+	t.Cleanup(server.Close)
+
+	return manager, "ws" + strings.TrimPrefix(server.URL, "http")
+}
+
+func TestManager_HeartbeatMeasuresLatency(t *testing.T) {
+	manager, url := newHeartbeatTestManager(t)
+
+	conn, _, err := websocket.Dial(context.Background(), url, nil)
+	if err != nil {
+		t.Fatalf("Dial() error = %v", err)
+	}
+	defer conn.Close(websocket.StatusNormalClosure, "")
+	// クライアント側も読み取りを続けてPingに応答する
+	conn.CloseRead(context.Background())
+
+	waitFor(t, func() bool {
+		_, ok := manager.UserLatency(1)
+		return ok
+	})
+}
+
+func TestManager_HeartbeatClosesUnresponsivePeer(t *testing.T) {
+	manager, url := newHeartbeatTestManager(t)
+
+	// 読み取りを行わないクライアントはPingに応答しない
+	conn, _, err := websocket.Dial(context.Background(), url, nil)
+	if err != nil {
+		t.Fatalf("Dial() error = %v", err)
+	}
+	defer conn.CloseNow()
+
+	waitFor(t, func() bool {
+		return manager.GetClientCount() == 1
+	})
+	waitFor(t, func() bool {
+		return manager.GetClientCount() == 0
+	})
+}
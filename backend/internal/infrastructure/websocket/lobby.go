@@ -0,0 +1,53 @@
+package websocket
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// lobbyNotifier はroomの変化を一定時間まとめてからロビーへ通知する
+// 短時間に同じroomが何度変化しても、flush時には1件の差分として扱う
+type lobbyNotifier struct {
+	mutex    sync.Mutex
+	dirty    map[int]struct{}
+	timer    *time.Timer
+	interval time.Duration
+	flush    func(roomIDs []int)
+}
+
+func newLobbyNotifier(interval time.Duration, flush func(roomIDs []int)) *lobbyNotifier {
+	return &lobbyNotifier{
+		dirty:    make(map[int]struct{}),
+		interval: interval,
+		flush:    flush,
+	}
+}
+
+// mark はroomを変更済みとして記録し、未予約であればflushを予約する（ブロックしない）
+func (l *lobbyNotifier) mark(roomID int) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	l.dirty[roomID] = struct{}{}
+	if l.timer == nil {
+		l.timer = time.AfterFunc(l.interval, l.fire)
+	}
+}
+
+func (l *lobbyNotifier) fire() {
+	l.mutex.Lock()
+	roomIDs := make([]int, 0, len(l.dirty))
+	for roomID := range l.dirty {
+		roomIDs = append(roomIDs, roomID)
+	}
+	l.dirty = make(map[int]struct{})
+	l.timer = nil
+	l.mutex.Unlock()
+
+	if len(roomIDs) == 0 {
+		return
+	}
+	sort.Ints(roomIDs)
+	l.flush(roomIDs)
+}
@@ -0,0 +1,45 @@
+package websocket
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestLobbyNotifier_CoalescesBursts(t *testing.T) {
+	flushed := make(chan []int, 4)
+	notifier := newLobbyNotifier(20*time.Millisecond, func(roomIDs []int) {
+		flushed <- roomIDs
+	})
+
+	notifier.mark(3)
+	notifier.mark(1)
+	notifier.mark(3)
+	notifier.mark(1)
+
+	select {
+	case roomIDs := <-flushed:
+		if !reflect.DeepEqual(roomIDs, []int{1, 3}) {
+			t.Errorf("flushed room IDs = %v, want [1 3]", roomIDs)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("notifier did not flush")
+	}
+
+	select {
+	case roomIDs := <-flushed:
+		t.Errorf("unexpected extra flush: %v", roomIDs)
+	case <-time.After(60 * time.Millisecond):
+	}
+
+	// flush後の変更は次のflushとして通知される
+	notifier.mark(2)
+	select {
+	case roomIDs := <-flushed:
+		if !reflect.DeepEqual(roomIDs, []int{2}) {
+			t.Errorf("flushed room IDs = %v, want [2]", roomIDs)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("notifier did not flush after second burst")
+	}
+}
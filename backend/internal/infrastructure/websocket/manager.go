@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/coder/websocket"
@@ -26,6 +27,55 @@ type Client struct {
 	RoomID *int // 参加しているroomのID（未参加の場合はnil）
 	Conn   *websocket.Conn
 	Cancel context.CancelFunc
+
+	send      chan []byte   // 送信キュー（writerゴルーチンが順番に書き込む）
+	done      chan struct{} // 切断時にclose
+	closeOnce sync.Once
+
+	replayPending bool // 取りこぼしイベントの再送待ち（再送完了までroomイベントの直接配信を保留）
+
+	closeCode   websocket.StatusCode // closeAfterFlushで使用する切断コード
+	closeReason string
+
+	rtt              atomic.Int64 // 直近のハートビートの往復時間（ナノ秒、未計測の場合は0）
+	missedHeartbeats int          // 連続で応答のなかったハートビート数（heartbeatLoopのみが使用）
+}
+
+// RTT returns the round-trip time measured by the latest heartbeat
+func (c *Client) RTT() time.Duration {
+	return time.Duration(c.rtt.Load())
+}
+
+// enqueue は送信キューにメッセージを積む。キューが満杯の場合はfalseを返す
+func (c *Client) enqueue(data []byte) bool {
+	select {
+	case <-c.done:
+		// 切断済みのクライアントへのメッセージは破棄
+		return true
+	default:
+	}
+
+	select {
+	case c.send <- data:
+		return true
+	default:
+		return false
+	}
+}
+
+// closeAfterFlush はキューに積まれたメッセージを送信し終えてから接続を閉じる
+func (c *Client) closeAfterFlush(code websocket.StatusCode, reason string) bool {
+	c.closeCode = code
+	c.closeReason = reason
+	// nilは切断の合図
+	return c.enqueue(nil)
+}
+
+// close はwriterゴルーチンを停止する
+func (c *Client) close() {
+	c.closeOnce.Do(func() {
+		close(c.done)
+	})
 }
 
 // RoomUsecaseのインターフェース定義（循環importを避けるため）
@@ -35,16 +85,28 @@ type RoomUsecaseInterface interface {
 	RemoveDisconnectedPlayer(roomID int, playerID int) (*domain.Room, error)
 	GetRoomByID(roomID int) (*domain.Room, error)
 	RemovePlayerFromRoom(roomID int, playerID int) (*domain.Room, error)
+	GetRoomSnapshot(roomID int) (*domain.RoomSnapshot, error)
 }
 
 type Manager struct {
 	clients           map[string]*Client
-	userClients       map[int]*Client    // UserID -> Client のマッピング
-	roomClients       map[int][]*Client  // RoomID -> []*Client のマッピング
-	disconnectedUsers map[int]*UserState // 切断されたユーザーの状態を一時保存
+	userClients       map[int]map[string]*Client // UserID -> ClientID -> Client のマッピング（複数タブ対応）
+	roomClients       map[int][]*Client          // RoomID -> []*Client のマッピング
+	roomEvents        map[int]*eventBuffer       // RoomID -> 直近イベントのリングバッファ
+	disconnectedUsers map[int]*UserState         // 切断されたユーザーの状態を一時保存
 	mutex             sync.RWMutex
 	deleteTimeout     time.Duration        // ユーザー削除までのタイムアウト時間
 	roomUsecase       RoomUsecaseInterface // RoomUsecaseとの連携用
+	sendQueueSize     int                  // クライアントごとの送信キューの上限
+	eventBufferSize   int                  // roomごとに保持する直近イベント数
+	writeTimeout      time.Duration        // 1メッセージあたりの書き込みタイムアウト
+	singleSession     bool                 // trueの場合、新しい接続が同じユーザーの古い接続を置き換える
+	lobby             *lobbyNotifier       // ロビー向けroom一覧差分の集約
+	outbound          *eventQueue          // room・ユーザー宛てイベントの送信待ち（呼び出し順に配送）
+	backplane         Backplane            // room・ユーザー宛てイベントのインスタンス間配送
+	heartbeatInterval time.Duration        // ハートビート（Ping）の送信間隔
+	heartbeatTimeout  time.Duration        // Pongを待つ時間
+	maxMissedBeats    int                  // この回数連続で応答がなければ切断する
 }
 
 // 後方互換性のため残す（非推奨）
@@ -70,13 +132,26 @@ type BoardUpdateContent struct {
 }
 
 func NewManager() *Manager {
-	return &Manager{
+	manager := &Manager{
 		clients:           make(map[string]*Client),
-		userClients:       make(map[int]*Client),
+		userClients:       make(map[int]map[string]*Client),
 		roomClients:       make(map[int][]*Client),
+		roomEvents:        make(map[int]*eventBuffer),
 		disconnectedUsers: make(map[int]*UserState),
 		deleteTimeout:     10 * time.Second, // ゲーム時間と同じ120秒後に削除
+		sendQueueSize:     128,
+		eventBufferSize:   100,
+		writeTimeout:      10 * time.Second,
+		heartbeatInterval: 15 * time.Second,
+		heartbeatTimeout:  10 * time.Second,
+		maxMissedBeats:    3,
 	}
+	// 短時間の連続した変化は1回のroom_list_updatedにまとめる
+	manager.lobby = newLobbyNotifier(250*time.Millisecond, manager.sendRoomListUpdates)
+	manager.outbound = newEventQueue(manager.publishQueued)
+	// 既定は単一インスタンス用のバックプレーン
+	manager.SetBackplane(NewInProcessBackplane())
+	return manager
 }
 
 // NewManagerWithTimeout creates a new Manager with custom delete timeout
@@ -93,7 +168,32 @@ func (m *Manager) SetRoomUsecase(roomUsecase RoomUsecaseInterface) {
 	m.roomUsecase = roomUsecase
 }
 
-func (m *Manager) AddClient(clientID string, userID int, initialRoomID *int, conn *websocket.Conn, cancel context.CancelFunc) {
+// SetBackplane replaces the backplane used to deliver room and user events.
+// 複数インスタンス構成ではNetworkBackplaneを設定し、他インスタンスの接続にもイベントを届ける
+func (m *Manager) SetBackplane(backplane Backplane) {
+	backplane.Subscribe(m.handleBackplaneMessage)
+
+	m.mutex.Lock()
+	previous := m.backplane
+	m.backplane = backplane
+	m.mutex.Unlock()
+
+	if previous != nil {
+		previous.Close()
+	}
+}
+
+// SetSingleSessionPolicy enables or disables the single session policy.
+// 有効な場合、同じユーザーの新しい接続が確立すると古い接続にsession_replacedを送って切断する
+func (m *Manager) SetSingleSessionPolicy(enabled bool) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.singleSession = enabled
+}
+
+// AddClient registers a new connection.
+// lastSeqが指定されroomが復元された場合、ReplayRoomEventsが呼ばれるまでroomイベントの配信を保留する
+func (m *Manager) AddClient(clientID string, userID int, initialRoomID *int, lastSeq *uint64, conn *websocket.Conn, cancel context.CancelFunc) {
 	m.mutex.Lock()
 	defer m.mutex.Unlock()
 
@@ -103,10 +203,39 @@ func (m *Manager) AddClient(clientID string, userID int, initialRoomID *int, con
 		RoomID: initialRoomID,
 		Conn:   conn,
 		Cancel: cancel,
+		send:   make(chan []byte, m.sendQueueSize),
+		done:   make(chan struct{}),
 	}
 
-	// 切断されたユーザーとして登録されているかチェック
-	if disconnectedUser, exists := m.disconnectedUsers[userID]; exists {
+	// 接続ごとのwriterゴルーチンを起動（送信順序を保証）
+	go m.writeLoop(client)
+	go m.heartbeatLoop(client)
+
+	if sessions := m.userClients[userID]; len(sessions) > 0 {
+		// 同じユーザーの接続が既にある場合（別タブなど）は、既存の接続と同じroomに所属させる
+		client.RoomID = nil
+		for _, other := range sessions {
+			if other.RoomID != nil {
+				roomID := *other.RoomID
+				client.RoomID = &roomID
+				client.replayPending = lastSeq != nil
+				m.roomClients[roomID] = append(m.roomClients[roomID], client)
+				break
+			}
+		}
+
+		if m.singleSession {
+			m.replaceSessions(client, sessions)
+		}
+
+		log.Info().
+			Str("client_id", clientID).
+			Int("user_id", userID).
+			Int("existing_sessions", len(sessions)).
+			Interface("room_id", client.RoomID).
+			Msg("WebSocket client connected as an additional session")
+	} else if disconnectedUser, exists := m.disconnectedUsers[userID]; exists {
+		// 切断されたユーザーとして登録されている場合
 		// 削除タイマーをキャンセル
 		if disconnectedUser.DeleteTimer != nil {
 			disconnectedUser.DeleteTimer.Stop()
@@ -125,6 +254,7 @@ func (m *Manager) AddClient(clientID string, userID int, initialRoomID *int, con
 
 			// roomClientsにも再登録
 			if client.RoomID != nil {
+				client.replayPending = lastSeq != nil
 				m.roomClients[*client.RoomID] = append(m.roomClients[*client.RoomID], client)
 
 				// RoomUsecaseに再接続を通知
@@ -177,7 +307,66 @@ func (m *Manager) AddClient(clientID string, userID int, initialRoomID *int, con
 	}
 
 	m.clients[clientID] = client
-	m.userClients[userID] = client
+	if m.userClients[userID] == nil {
+		m.userClients[userID] = make(map[string]*Client)
+	}
+	m.userClients[userID][clientID] = client
+}
+
+// RestoreDisconnectedPlayers registers the players of rooms restored at startup as disconnected users.
+// 再接続したクライアントはAddClientの復元処理で元のroomに戻り、タイムアウトまでに戻らなければroomから削除される
+func (m *Manager) RestoreDisconnectedPlayers(players map[int][]domain.Player) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	now := time.Now()
+	for roomID, roomPlayers := range players {
+		for _, player := range roomPlayers {
+			userID := player.ID
+			restoredRoomID := roomID
+			userState := &UserState{
+				UserID:     userID,
+				RoomID:     &restoredRoomID,
+				LastSeenAt: now,
+			}
+			userState.DeleteTimer = time.AfterFunc(m.deleteTimeout, func() {
+				go m.handleUserDeletion(userID)
+			})
+			m.disconnectedUsers[userID] = userState
+		}
+	}
+
+	log.Info().
+		Int("room_count", len(players)).
+		Dur("delete_timeout", m.deleteTimeout).
+		Msg("Players of restored rooms scheduled for delayed deletion")
+}
+
+// replaceSessions は単一セッションポリシーで古い接続にsession_replacedを送って切断する（m.mutexを保持した状態で呼ぶこと）
+func (m *Manager) replaceSessions(newClient *Client, sessions map[string]*Client) {
+	data, err := json.Marshal(NewSessionReplacedEvent(newClient.UserID, newClient.ID))
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to marshal WebSocketEvent")
+		return
+	}
+
+	for _, old := range sessions {
+		m.sendToClient(old.ID, old, data)
+		if !old.closeAfterFlush(websocket.StatusPolicyViolation, "session replaced") {
+			// キューが満杯の場合は通知を諦めて即座に切断
+			old.close()
+			go func(client *Client) {
+				client.Conn.Close(websocket.StatusPolicyViolation, "session replaced")
+				m.RemoveClient(client.ID)
+			}(old)
+		}
+
+		log.Info().
+			Str("client_id", old.ID).
+			Str("new_client_id", newClient.ID).
+			Int("user_id", newClient.UserID).
+			Msg("WebSocket session replaced by a newer connection")
+	}
 }
 
 func (m *Manager) RemoveClient(clientID string) {
@@ -190,7 +379,11 @@ func (m *Manager) RemoveClient(clientID string) {
 	}
 
 	// userClientsから削除（即座に）
-	delete(m.userClients, client.UserID)
+	delete(m.userClients[client.UserID], clientID)
+	remainingSessions := len(m.userClients[client.UserID])
+	if remainingSessions == 0 {
+		delete(m.userClients, client.UserID)
+	}
 
 	// roomClientsから削除（即座に）
 	if client.RoomID != nil {
@@ -210,6 +403,21 @@ func (m *Manager) RemoveClient(clientID string) {
 		}
 	}
 
+	// クライアント接続を削除
+	client.Cancel()
+	client.close()
+	delete(m.clients, clientID)
+
+	// 他のセッションが残っている場合は切断扱いにしない
+	if remainingSessions > 0 {
+		log.Info().
+			Str("client_id", clientID).
+			Int("user_id", client.UserID).
+			Int("remaining_sessions", remainingSessions).
+			Msg("WebSocket session closed, user still connected via other sessions")
+		return
+	}
+
 	// RoomUsecaseに切断を通知
 	if m.roomUsecase != nil && client.RoomID != nil {
 		if _, err := m.roomUsecase.SetPlayerDisconnected(*client.RoomID, client.UserID); err != nil {
@@ -235,10 +443,6 @@ func (m *Manager) RemoveClient(clientID string) {
 
 	m.disconnectedUsers[client.UserID] = userState
 
-	// クライアント接続を削除
-	client.Cancel()
-	delete(m.clients, clientID)
-
 	log.Info().
 		Str("client_id", clientID).
 		Int("user_id", client.UserID).
@@ -250,38 +454,46 @@ func (m *Manager) JoinRoom(userID, roomID int) error {
 	m.mutex.Lock()
 	defer m.mutex.Unlock()
 
-	client, exists := m.userClients[userID]
-	if !exists {
+	sessions, exists := m.userClients[userID]
+	if !exists || len(sessions) == 0 {
 		log.Warn().Int("user_id", userID).Msg("User not connected via WebSocket")
 		return nil // WebSocket接続がない場合はエラーにしない
 	}
 
-	// 既に別のroomに参加している場合の処理
-	if client.RoomID != nil {
-		oldRoomID := *client.RoomID
-
+	// 既に別のroomに参加している場合の処理（全セッションは同じroomに所属している）
+	var oldRoomID *int
+	for _, client := range sessions {
+		if client.RoomID != nil {
+			oldRoomID = client.RoomID
+			break
+		}
+	}
+	if oldRoomID != nil {
 		// 古い部屋でのゲーム進行状況を確認し、適切な処理を行う
 		if m.roomUsecase != nil {
-			if err := m.handlePreviousRoomExit(userID, oldRoomID); err != nil {
+			if err := m.handlePreviousRoomExit(userID, *oldRoomID); err != nil {
 				log.Warn().Err(err).
 					Int("user_id", userID).
-					Int("old_room_id", oldRoomID).
+					Int("old_room_id", *oldRoomID).
 					Int("new_room_id", roomID).
 					Msg("Failed to handle previous room exit properly")
 			}
 		}
+	}
 
+	// ユーザーの全セッションを新しいroomに参加させる
+	for _, client := range sessions {
 		// WebSocketレベルでの退室処理
 		m.leaveRoomInternal(client)
-	}
 
-	// 新しいroomに参加
-	client.RoomID = &roomID
-	m.roomClients[roomID] = append(m.roomClients[roomID], client)
+		client.RoomID = &roomID
+		m.roomClients[roomID] = append(m.roomClients[roomID], client)
+	}
 
 	log.Info().
 		Int("user_id", userID).
 		Int("room_id", roomID).
+		Int("session_count", len(sessions)).
 		Msg("User joined room via WebSocket")
 
 	return nil
@@ -314,7 +526,7 @@ func (m *Manager) handlePreviousRoomExit(userID, roomID int) error {
 			Str("room_state", room.State.String()).
 			Msg("Player immediately removed from non-progressing room")
 
-	case domain.StateCountdown, domain.StateGameInProgress:
+	case domain.StateCountdown, domain.StateGameInProgress, domain.StatePaused:
 		// ゲーム進行中の場合は通常の切断処理
 		if _, err := m.roomUsecase.SetPlayerDisconnected(roomID, userID); err != nil {
 			log.Warn().Err(err).
@@ -339,17 +551,20 @@ func (m *Manager) LeaveRoom(userID int) error {
 	m.mutex.Lock()
 	defer m.mutex.Unlock()
 
-	client, exists := m.userClients[userID]
-	if !exists || client.RoomID == nil {
+	var roomID *int
+	for _, client := range m.userClients[userID] {
+		if client.RoomID != nil {
+			roomID = client.RoomID
+			m.leaveRoomInternal(client)
+		}
+	}
+	if roomID == nil {
 		return nil
 	}
 
-	roomID := *client.RoomID
-	m.leaveRoomInternal(client)
-
 	log.Info().
 		Int("user_id", userID).
-		Int("room_id", roomID).
+		Int("room_id", *roomID).
 		Msg("User left room via WebSocket (connection maintained)")
 
 	return nil
@@ -402,7 +617,7 @@ func (m *Manager) NotifyAll(event string, content interface{}) {
 	m.mutex.RUnlock()
 
 	for _, client := range clients {
-		go m.sendToClient(client.ID, client, data)
+		m.sendToClient(client.ID, client, data)
 	}
 
 	log.Info().
@@ -437,7 +652,7 @@ func (m *Manager) NotifyRoom(roomID int, event string, content interface{}) {
 	m.mutex.RUnlock()
 
 	for _, client := range clientsCopy {
-		go m.sendToClient(client.ID, client, data)
+		m.sendToClient(client.ID, client, data)
 	}
 
 	log.Info().
@@ -470,7 +685,7 @@ func (m *Manager) NotifyNonRoomMembers(event string, content interface{}) {
 	m.mutex.RUnlock()
 
 	for _, client := range clients {
-		go m.sendToClient(client.ID, client, data)
+		m.sendToClient(client.ID, client, data)
 	}
 
 	log.Info().
@@ -479,6 +694,67 @@ func (m *Manager) NotifyNonRoomMembers(event string, content interface{}) {
 		Msg("Broadcasted message to non-room clients")
 }
 
+// RoomChanged records that the room summary may have changed.
+// RoomUsecaseから状態変更のたびに呼ばれ、まとめてロビーへroom_list_updatedを送信する
+func (m *Manager) RoomChanged(roomID int) {
+	m.lobby.mark(roomID)
+}
+
+// GamePaused implements usecase.GamePauseListener.
+// 切断・再接続の処理中はManagerのロックを保持したまま呼ばれるが、SendEventToRoomはブロックしない
+func (m *Manager) GamePaused(roomID int, remaining time.Duration, autoPaused bool) {
+	message := "Game paused"
+	if autoPaused {
+		message = "Game paused because all players disconnected"
+	}
+	m.SendEventToRoom(roomID, NewGamePausedEvent(roomID, message, remaining.Milliseconds(), autoPaused))
+}
+
+// GameResumed implements usecase.GamePauseListener
+func (m *Manager) GameResumed(roomID int, remaining time.Duration) {
+	m.SendEventToRoom(roomID, NewGameResumedEvent(roomID, "Game resumed", remaining.Milliseconds()))
+}
+
+// sendRoomListUpdates は変化のあったroomの概要をroom未参加のクライアントへ送信する
+func (m *Manager) sendRoomListUpdates(roomIDs []int) {
+	clients := m.GetClientsNotInRoom()
+	if len(clients) == 0 {
+		return
+	}
+
+	m.mutex.RLock()
+	roomUsecase := m.roomUsecase
+	m.mutex.RUnlock()
+	if roomUsecase == nil {
+		return
+	}
+
+	rooms := make([]RoomSummary, 0, len(roomIDs))
+	for _, roomID := range roomIDs {
+		snapshot, err := roomUsecase.GetRoomSnapshot(roomID)
+		if err != nil {
+			log.Warn().Err(err).Int("room_id", roomID).Msg("Failed to get room for lobby update")
+			continue
+		}
+		rooms = append(rooms, ConvertToRoomSummary(snapshot))
+	}
+	if len(rooms) == 0 {
+		return
+	}
+
+	data, err := json.Marshal(NewRoomListUpdatedEvent(rooms))
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to marshal WebSocketEvent")
+		return
+	}
+	m.SendToClients(clients, data)
+
+	log.Info().
+		Ints("room_ids", roomIDs).
+		Int("client_count", len(clients)).
+		Msg("Sent room list update to lobby clients")
+}
+
 // 特定ユーザーに通知
 func (m *Manager) NotifyUser(userID int, event string, content interface{}) error {
 	message := NotificationMessage{
@@ -491,20 +767,20 @@ func (m *Manager) NotifyUser(userID int, event string, content interface{}) erro
 		return err
 	}
 
-	m.mutex.RLock()
-	client, exists := m.userClients[userID]
-	m.mutex.RUnlock()
-
-	if !exists {
+	clients := m.GetClientsByUser(userID)
+	if len(clients) == 0 {
 		log.Warn().Int("user_id", userID).Msg("User not connected via WebSocket")
 		return nil
 	}
 
-	go m.sendToClient(client.ID, client, data)
+	for _, client := range clients {
+		m.sendToClient(client.ID, client, data)
+	}
 
 	log.Info().
 		Int("user_id", userID).
 		Str("event", event).
+		Int("session_count", len(clients)).
 		Msg("Sent message to user")
 
 	return nil
@@ -513,7 +789,7 @@ func (m *Manager) NotifyUser(userID int, event string, content interface{}) erro
 // 低レベルAPI: 複数クライアントに直接メッセージ送信
 func (m *Manager) SendToClients(clients []*Client, data []byte) {
 	for _, client := range clients {
-		go m.sendToClient(client.ID, client, data)
+		m.sendToClient(client.ID, client, data)
 	}
 }
 
@@ -557,21 +833,134 @@ func (m *Manager) GetClientsNotInRoom() []*Client {
 	return clients
 }
 
-func (m *Manager) GetClientByUser(userID int) *Client {
+// GetClientsByUser returns all active sessions of the user
+func (m *Manager) GetClientsByUser(userID int) []*Client {
 	m.mutex.RLock()
 	defer m.mutex.RUnlock()
 
-	return m.userClients[userID]
+	clients := make([]*Client, 0, len(m.userClients[userID]))
+	for _, client := range m.userClients[userID] {
+		clients = append(clients, client)
+	}
+	return clients
 }
 
+// sendToClient はクライアントの送信キューにメッセージを積む（ブロックしない）
+// キューが満杯の遅いクライアントは切断する
 func (m *Manager) sendToClient(clientID string, client *Client, data []byte) {
-	err := client.Conn.Write(context.Background(), websocket.MessageText, data)
-	if err != nil {
-		log.Error().Err(err).Str("client_id", clientID).Msg("Failed to send message to client")
+	if client.enqueue(data) {
+		return
+	}
+
+	log.Warn().
+		Str("client_id", clientID).
+		Int("user_id", client.UserID).
+		Int("queue_size", cap(client.send)).
+		Msg("Send queue is full, disconnecting slow client")
+
+	// 以降のメッセージは破棄し、writerゴルーチンを停止
+	client.close()
+
+	// Closeはハンドシェイクで待たされる可能性があるため別ゴルーチンで実行
+	go func() {
+		client.Conn.Close(websocket.StatusPolicyViolation, "slow consumer")
 		m.RemoveClient(clientID)
+	}()
+}
+
+// writeLoop はクライアントの送信キューから順番にメッセージを書き込む
+func (m *Manager) writeLoop(client *Client) {
+	for {
+		select {
+		case <-client.done:
+			return
+		case data := <-client.send:
+			if data == nil {
+				// closeAfterFlushによる切断要求
+				client.Conn.Close(client.closeCode, client.closeReason)
+				m.RemoveClient(client.ID)
+				return
+			}
+			ctx, cancel := context.WithTimeout(context.Background(), m.writeTimeout)
+			err := client.Conn.Write(ctx, websocket.MessageText, data)
+			cancel()
+			if err != nil {
+				log.Error().Err(err).Str("client_id", client.ID).Msg("Failed to send message to client")
+				m.RemoveClient(client.ID)
+				return
+			}
+		}
 	}
 }
 
+// heartbeatLoop は定期的にPingを送信して往復時間を計測し、応答のない接続を切断する
+func (m *Manager) heartbeatLoop(client *Client) {
+	ticker := time.NewTicker(m.heartbeatInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-client.done:
+			return
+		case <-ticker.C:
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), m.heartbeatTimeout)
+		start := time.Now()
+		err := client.Conn.Ping(ctx)
+		cancel()
+
+		if err == nil {
+			client.missedHeartbeats = 0
+			client.rtt.Store(int64(time.Since(start)))
+			continue
+		}
+
+		select {
+		case <-client.done:
+			return
+		default:
+		}
+
+		client.missedHeartbeats++
+		log.Warn().
+			Err(err).
+			Str("client_id", client.ID).
+			Int("missed", client.missedHeartbeats).
+			Msg("WebSocket heartbeat missed")
+
+		if client.missedHeartbeats >= m.maxMissedBeats {
+			log.Warn().
+				Str("client_id", client.ID).
+				Int("user_id", client.UserID).
+				Msg("Closing WebSocket connection after missed heartbeats")
+			client.close()
+			// 応答のない相手とはクローズハンドシェイクを行わずに切断する
+			client.Conn.CloseNow()
+			m.RemoveClient(client.ID)
+			return
+		}
+	}
+}
+
+// UserLatency returns the lowest round-trip time among the user's sessions on this instance
+func (m *Manager) UserLatency(userID int) (time.Duration, bool) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+	return m.userLatency(userID)
+}
+
+// userLatency はm.mutexを保持した状態で呼ぶこと
+func (m *Manager) userLatency(userID int) (time.Duration, bool) {
+	var best time.Duration
+	for _, client := range m.userClients[userID] {
+		if rtt := client.RTT(); rtt > 0 && (best == 0 || rtt < best) {
+			best = rtt
+		}
+	}
+	return best, best > 0
+}
+
 func (m *Manager) GetClientCount() int {
 	m.mutex.RLock()
 	defer m.mutex.RUnlock()
@@ -622,7 +1011,7 @@ func (m *Manager) SendEvent(event WebSocketEvent) {
 	m.mutex.RUnlock()
 
 	for _, client := range clients {
-		go m.sendToClient(client.ID, client, data)
+		m.sendToClient(client.ID, client, data)
 	}
 
 	log.Info().
@@ -631,58 +1020,284 @@ func (m *Manager) SendEvent(event WebSocketEvent) {
 		Msg("Sent structured event to all clients")
 }
 
-// SendEventToRoom sends a structured WebSocketEvent to room participants
+// SendEventToRoom sends a structured WebSocketEvent to room participants.
+// バックプレーン経由で全インスタンスに配送され、各インスタンスが自分の接続へ送信する
+// 送信キューを経由するため、ロックを保持したまま呼ばれてもブロックせず、呼び出し順に配送される
 func (m *Manager) SendEventToRoom(roomID int, event WebSocketEvent) {
+	m.outbound.push(BackplaneMessage{Kind: BackplaneRoom, RoomID: roomID}, event)
+}
+
+// publishQueued は送信キューから取り出したイベントをバックプレーンへ送信する
+func (m *Manager) publishQueued(msg BackplaneMessage, event WebSocketEvent) {
+	if err := m.publish(msg, event); err != nil {
+		log.Error().
+			Err(err).
+			Str("event", event.Event).
+			Str("kind", msg.Kind).
+			Int("room_id", msg.RoomID).
+			Int("user_id", msg.UserID).
+			Msg("Failed to publish event")
+	}
+}
+
+// publish はイベントをバックプレーンへ送信する（シーケンス番号は配送時に付与される）
+func (m *Manager) publish(msg BackplaneMessage, event WebSocketEvent) error {
+	event.Seq = 0
 	data, err := json.Marshal(event)
 	if err != nil {
-		log.Error().Err(err).Msg("Failed to marshal WebSocketEvent")
-		return
+		return err
 	}
+	msg.Event = data
 
 	m.mutex.RLock()
-	clients, exists := m.roomClients[roomID]
-	if !exists {
-		m.mutex.RUnlock()
-		log.Warn().Int("room_id", roomID).Msg("No clients in room")
+	backplane := m.backplane
+	m.mutex.RUnlock()
+
+	return backplane.Publish(msg)
+}
+
+// sequencedEvent はシーケンス番号を付け直すためのWebSocketEventのJSON表現
+type sequencedEvent struct {
+	Event   string          `json:"event"`
+	Seq     uint64          `json:"seq,omitempty"`
+	Content json.RawMessage `json:"content"`
+}
+
+// handleBackplaneMessage はバックプレーンから届いたイベントをこのインスタンスの接続へ配信する
+func (m *Manager) handleBackplaneMessage(msg BackplaneMessage) {
+	switch msg.Kind {
+	case BackplaneRoom:
+		m.deliverRoomEvent(msg)
+	case BackplaneUser:
+		m.deliverUserEvent(msg)
+	default:
+		log.Warn().Str("kind", msg.Kind).Msg("Unknown backplane message kind")
+	}
+}
+
+// deliverRoomEvent はシーケンス番号を付与し、再送用のリングバッファに保存してroom参加者へ送信する
+func (m *Manager) deliverRoomEvent(msg BackplaneMessage) {
+	var event sequencedEvent
+	if err := json.Unmarshal(msg.Event, &event); err != nil {
+		log.Error().Err(err).Msg("Failed to unmarshal backplane event")
 		return
 	}
-	clientsCopy := make([]*Client, len(clients))
-	copy(clientsCopy, clients)
-	m.mutex.RUnlock()
 
-	for _, client := range clientsCopy {
-		go m.sendToClient(client.ID, client, data)
+	// シーケンス番号の採番と送信キューへの投入を同じロック内で行い、番号順の配信を保証
+	m.mutex.Lock()
+	buffer := m.roomEventBuffer(msg.RoomID)
+	previousSeq := buffer.lastSeq
+	// Brokerとの切断中に届かなかったイベントがある場合や、Brokerの再起動で採番がやり直された場合
+	discontinuous := msg.Seq != 0 && previousSeq != 0 && msg.Seq != buffer.nextSeq()
+	if discontinuous {
+		// 途切れたバッファは再送に使えない
+		buffer = newEventBuffer(m.eventBufferSize)
+		m.roomEvents[msg.RoomID] = buffer
+	}
+	event.Seq = msg.Seq
+	if event.Seq == 0 {
+		// バックプレーンが採番しない場合（単一インスタンス）はここで採番する
+		event.Seq = buffer.nextSeq()
+	}
+	data, err := json.Marshal(event)
+	if err != nil {
+		m.mutex.Unlock()
+		log.Error().Err(err).Msg("Failed to marshal WebSocketEvent")
+		return
 	}
+	buffer.push(event.Seq, data)
+
+	clientCount := 0
+	for _, client := range m.roomClients[msg.RoomID] {
+		// 再送待ちのクライアントにはReplayRoomEventsでバッファから配信する
+		if client.replayPending {
+			continue
+		}
+		if discontinuous {
+			// 取りこぼした範囲を届けられないため、スナップショットで再同期させる
+			m.sendResyncRequired(client, msg.RoomID, previousSeq, buffer)
+			continue
+		}
+		m.sendToClient(client.ID, client, data)
+		clientCount++
+	}
+	m.mutex.Unlock()
 
 	log.Info().
 		Str("event", event.Event).
-		Int("room_id", roomID).
-		Int("client_count", len(clientsCopy)).
+		Int("room_id", msg.RoomID).
+		Uint64("seq", event.Seq).
+		Int("client_count", clientCount).
 		Msg("Sent structured event to room clients")
 }
 
-// SendEventToUser sends a structured WebSocketEvent to a specific user
+// deliverUserEvent はこのインスタンスに接続しているユーザーの全セッションへ送信する
+func (m *Manager) deliverUserEvent(msg BackplaneMessage) {
+	clients := m.GetClientsByUser(msg.UserID)
+	if len(clients) == 0 {
+		// 他のインスタンスに接続している可能性がある
+		log.Debug().Int("user_id", msg.UserID).Msg("User not connected to this instance")
+		return
+	}
+
+	for _, client := range clients {
+		m.sendToClient(client.ID, client, msg.Event)
+	}
+
+	log.Info().
+		Int("user_id", msg.UserID).
+		Int("session_count", len(clients)).
+		Msg("Sent structured event to user")
+}
+
+// ReplayRoomEvents sends the room events after lastSeq to a reconnected client.
+// バッファが取りこぼし範囲をカバーしていない場合や、再送待ちでない場合はresync_requiredを送信する
+func (m *Manager) ReplayRoomEvents(clientID string, lastSeq uint64) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	client, exists := m.clients[clientID]
+	if !exists || client.RoomID == nil {
+		return
+	}
+
+	roomID := *client.RoomID
+	buffer := m.roomEventBuffer(roomID)
+	if !client.replayPending {
+		// 直接配信中のクライアントには再送の起点を保証できないため再同期させる
+		m.sendResyncRequired(client, roomID, lastSeq, buffer)
+		return
+	}
+	client.replayPending = false
+
+	missed, ok := buffer.since(lastSeq)
+	// 送信キューに収まらない量の再送も再同期扱いにする
+	if ok && len(missed) <= cap(client.send)-len(client.send) {
+		for _, data := range missed {
+			m.sendToClient(client.ID, client, data)
+		}
+		log.Info().
+			Str("client_id", clientID).
+			Int("room_id", roomID).
+			Uint64("last_seq", lastSeq).
+			Int("replayed_count", len(missed)).
+			Msg("Replayed missed room events to reconnected client")
+		return
+	}
+
+	m.sendResyncRequired(client, roomID, lastSeq, buffer)
+}
+
+// sendResyncRequired はクライアントにresync_requiredを送信する（m.mutexを保持して呼ぶこと）
+func (m *Manager) sendResyncRequired(client *Client, roomID int, lastSeq uint64, buffer *eventBuffer) {
+	data, err := json.Marshal(NewResyncRequiredEvent(roomID, lastSeq, buffer.oldestSeq(), buffer.lastSeq))
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to marshal WebSocketEvent")
+		return
+	}
+	m.sendToClient(client.ID, client, data)
+
+	log.Info().
+		Str("client_id", client.ID).
+		Int("room_id", roomID).
+		Uint64("last_seq", lastSeq).
+		Uint64("oldest_seq", buffer.oldestSeq()).
+		Msg("Missed room events cannot be replayed, resync required")
+}
+
+// SendRoomSnapshot sends the full state of the client's room to the client.
+// スナップショットと最新シーケンス番号を同じロック内で取得し、以降のroomイベントと矛盾しないようにする
+func (m *Manager) SendRoomSnapshot(clientID string) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	client, exists := m.clients[clientID]
+	if !exists {
+		return fmt.Errorf("client %s not found", clientID)
+	}
+	if client.RoomID == nil {
+		return fmt.Errorf("client %s is not in a room", clientID)
+	}
+	if m.roomUsecase == nil {
+		return fmt.Errorf("room usecase is not configured")
+	}
+
+	roomID := *client.RoomID
+	snapshot, err := m.roomUsecase.GetRoomSnapshot(roomID)
+	if err != nil {
+		return err
+	}
+
+	latencies := make(map[int]time.Duration, len(snapshot.Players))
+	for _, p := range snapshot.Players {
+		if latency, ok := m.userLatency(p.ID); ok {
+			latencies[p.ID] = latency
+		}
+	}
+
+	data, err := json.Marshal(NewRoomSnapshotEvent(snapshot, m.roomEventBuffer(roomID).lastSeq, latencies))
+	if err != nil {
+		return err
+	}
+	m.sendToClient(client.ID, client, data)
+
+	log.Info().
+		Str("client_id", clientID).
+		Int("room_id", roomID).
+		Str("room_state", snapshot.State.String()).
+		Msg("Sent room snapshot to client")
+
+	return nil
+}
+
+// IsClientInRoom checks if the client currently belongs to a room
+func (m *Manager) IsClientInRoom(clientID string) bool {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	client, exists := m.clients[clientID]
+	return exists && client.RoomID != nil
+}
+
+// roomEventBuffer returns the event buffer of the room (m.mutexを保持した状態で呼ぶこと)
+func (m *Manager) roomEventBuffer(roomID int) *eventBuffer {
+	buffer, exists := m.roomEvents[roomID]
+	if !exists {
+		buffer = newEventBuffer(m.eventBufferSize)
+		m.roomEvents[roomID] = buffer
+	}
+	return buffer
+}
+
+// SendEventToUser sends a structured WebSocketEvent to a specific user.
+// ユーザーがどのインスタンスに接続していても届くよう、バックプレーン経由で配送する（roomイベントと同じ送信キューを使う）
 func (m *Manager) SendEventToUser(userID int, event WebSocketEvent) error {
+	m.outbound.push(BackplaneMessage{Kind: BackplaneUser, UserID: userID}, event)
+	return nil
+}
+
+// SendEventToClient sends a structured WebSocketEvent to a specific connection
+func (m *Manager) SendEventToClient(clientID string, event WebSocketEvent) error {
 	data, err := json.Marshal(event)
 	if err != nil {
 		return err
 	}
 
 	m.mutex.RLock()
-	client, exists := m.userClients[userID]
+	client, exists := m.clients[clientID]
+	m.mutex.RUnlock()
+
 	if !exists {
-		m.mutex.RUnlock()
-		log.Warn().Int("user_id", userID).Msg("User not connected via WebSocket")
+		log.Warn().Str("client_id", clientID).Msg("Client not connected via WebSocket")
 		return nil
 	}
-	m.mutex.RUnlock()
 
-	go m.sendToClient(client.ID, client, data)
+	m.sendToClient(client.ID, client, data)
 
 	log.Info().
 		Str("event", event.Event).
-		Int("user_id", userID).
-		Msg("Sent structured event to user")
+		Str("client_id", clientID).
+		Msg("Sent structured event to client")
 
 	return nil
 }
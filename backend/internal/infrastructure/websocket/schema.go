@@ -0,0 +1,186 @@
+package websocket
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+)
+
+// EventDefinition はイベント名とcontentの型の対応
+type EventDefinition struct {
+	Name        string
+	Description string
+	Content     EventContent
+}
+
+// EventCatalog lists every server-to-client event and its content type.
+// イベントを追加した場合はここにも登録し、スキーマを再生成すること（make ws-schema）
+var EventCatalog = []EventDefinition{
+	{EventConnection, "WebSocket接続の確立を通知する（接続したクライアントのみに送信）", ConnectionEventContent{}},
+	{EventPlayerJoined, "プレイヤーがroomに参加した", PlayerJoinedEventContent{}},
+	{EventPlayerReady, "プレイヤーがREADYになった", PlayerEventContent{}},
+	{EventPlayerCanceled, "プレイヤーがREADYを取り消した", PlayerEventContent{}},
+	{EventPlayerLeft, "プレイヤーがroomから退出した", PlayerLeftEventContent{}},
+	{EventPlayerAllReady, "room内の全員がREADYになった", PlayerEventContent{}},
+	{EventRoomClosed, "roomが締め切られた", PlayerEventContent{}},
+	{EventRoomListUpdated, "ロビー向けのroom一覧の差分（room未参加のクライアントのみに送信）", RoomListUpdatedEventContent{}},
+	{EventGameStarted, "ゲーム開始処理が始まった", GameStartEventContent{}},
+	{EventGameStart, "カウントダウンが終わり、初期盤面でゲームが始まった", GameStartBoardEventContent{}},
+	{EventCountdownStart, "カウントダウンが始まった", CountdownEventContent{}},
+	{EventCountdown, "カウントダウンの残り秒数", CountdownEventContent{}},
+	{EventBoardUpdated, "数式の適用により盤面が更新された", BoardUpdateEventContent{}},
+	{EventResultClosed, "プレイヤーが結果画面を閉じた", PlayerEventContent{}},
+	{EventGameEnded, "ゲームが終了した", GameStartEventContent{}},
+	{EventGamePaused, "ゲームが一時停止された", GamePauseEventContent{}},
+	{EventGameResumed, "一時停止中のゲームが再開された", GameResumeEventContent{}},
+	{EventAck, "クライアントメッセージの処理に成功した", AckEventContent{}},
+	{EventError, "クライアントメッセージの処理に失敗した", ErrorEventContent{}},
+	{EventResyncRequired, "取りこぼしたroomイベントを再送できないため再同期が必要", ResyncRequiredEventContent{}},
+	{EventRoomSnapshot, "roomの全状態のスナップショット", RoomSnapshotEventContent{}},
+	{EventSessionReplaced, "新しい接続によりこのセッションが置き換えられた", SessionReplacedEventContent{}},
+}
+
+// GenerateEventSchema builds a JSON Schema document describing the WebSocket messages.
+// Goのイベント構造体からリフレクションで生成するため、構造体を変更すると出力も変わる
+func GenerateEventSchema() ([]byte, error) {
+	b := &schemaBuilder{defs: make(map[string]interface{})}
+
+	events := make([]interface{}, 0, len(EventCatalog))
+	for _, def := range EventCatalog {
+		envelopeName := "Event_" + def.Name
+		b.defs[envelopeName] = map[string]interface{}{
+			"type":        "object",
+			"description": def.Description,
+			"properties": map[string]interface{}{
+				"event": map[string]interface{}{"const": def.Name},
+				"seq": map[string]interface{}{
+					"type":        "integer",
+					"minimum":     0,
+					"description": "roomイベントのシーケンス番号（room単位で単調増加、roomイベント以外では省略）",
+				},
+				"content": b.typeSchema(reflect.TypeOf(def.Content)),
+			},
+			"required":             []string{"event", "content"},
+			"additionalProperties": false,
+		}
+		events = append(events, map[string]interface{}{"$ref": "#/$defs/" + envelopeName})
+	}
+
+	// クライアントからサーバーへのメッセージ
+	b.typeSchema(reflect.TypeOf(ClientMessage{}))
+
+	// 旧形式（非推奨）の通知メッセージ
+	b.typeSchema(reflect.TypeOf(NotificationMessage{}))
+	b.typeSchema(reflect.TypeOf(StandardEventContent{}))
+	b.typeSchema(reflect.TypeOf(BoardUpdateContent{}))
+	for _, name := range []string{"NotificationMessage", "StandardEventContent", "BoardUpdateContent"} {
+		b.defs[name].(map[string]interface{})["deprecated"] = true
+	}
+
+	schema := map[string]interface{}{
+		"$schema":     "https://json-schema.org/draft/2020-12/schema",
+		"title":       "WebSocket events",
+		"description": "Server-to-client events sent over /api/ws. Client messages are described by $defs/ClientMessage. Generated from backend/internal/infrastructure/websocket; do not edit by hand.",
+		"oneOf":       events,
+		"$defs":       b.defs,
+	}
+
+	data, err := json.MarshalIndent(schema, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
+
+// schemaBuilder はGoの型からJSON Schemaを組み立てる
+type schemaBuilder struct {
+	defs map[string]interface{}
+}
+
+func (b *schemaBuilder) typeSchema(t reflect.Type) map[string]interface{} {
+	switch t.Kind() {
+	case reflect.Ptr:
+		return b.typeSchema(t.Elem())
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"type": "integer", "minimum": 0}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Slice, reflect.Array:
+		return map[string]interface{}{"type": "array", "items": b.typeSchema(t.Elem())}
+	case reflect.Map:
+		return map[string]interface{}{"type": "object", "additionalProperties": b.typeSchema(t.Elem())}
+	case reflect.Struct:
+		name := t.Name()
+		if _, exists := b.defs[name]; !exists {
+			// 再帰的な型に備えて先に登録しておく
+			b.defs[name] = map[string]interface{}{}
+			b.defs[name] = b.structSchema(t)
+		}
+		return map[string]interface{}{"$ref": "#/$defs/" + name}
+	default:
+		// interface{}などは任意のJSON値
+		return map[string]interface{}{}
+	}
+}
+
+func (b *schemaBuilder) structSchema(t reflect.Type) map[string]interface{} {
+	properties := make(map[string]interface{})
+	required := make([]string, 0)
+	b.collectFields(t, properties, &required)
+
+	schema := map[string]interface{}{
+		"type":                 "object",
+		"properties":           properties,
+		"additionalProperties": false,
+	}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+	return schema
+}
+
+// collectFields はencoding/jsonと同じ規則でフィールドを展開する（埋め込み構造体はフラット化）
+func (b *schemaBuilder) collectFields(t reflect.Type, properties map[string]interface{}, required *[]string) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, options, _ := strings.Cut(tag, ",")
+
+		if field.Anonymous && name == "" && field.Type.Kind() == reflect.Struct {
+			b.collectFields(field.Type, properties, required)
+			continue
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		omitEmpty := strings.Contains(options, "omitempty")
+		fieldSchema := b.typeSchema(field.Type)
+		switch field.Type.Kind() {
+		case reflect.Ptr, reflect.Slice, reflect.Map:
+			// omitemptyのないnil値はnullとして出力される
+			if !omitEmpty {
+				fieldSchema = map[string]interface{}{
+					"anyOf": []interface{}{fieldSchema, map[string]interface{}{"type": "null"}},
+				}
+			}
+		}
+		properties[name] = fieldSchema
+
+		if !omitEmpty {
+			*required = append(*required, name)
+		}
+	}
+}
@@ -0,0 +1,85 @@
+package websocket
+
+import (
+	"bytes"
+	"flag"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+var updateSchema = flag.Bool("update", false, "regenerate the WebSocket event schema file")
+
+const eventSchemaPath = "../../../openapi/websocket-events.schema.json"
+
+func TestEventSchema_UpToDate(t *testing.T) {
+	generated, err := GenerateEventSchema()
+	if err != nil {
+		t.Fatalf("GenerateEventSchema() error = %v", err)
+	}
+
+	if *updateSchema {
+		if err := os.WriteFile(eventSchemaPath, generated, 0o644); err != nil {
+			t.Fatalf("failed to write schema: %v", err)
+		}
+	}
+
+	committed, err := os.ReadFile(eventSchemaPath)
+	if err != nil {
+		t.Fatalf("failed to read schema: %v", err)
+	}
+	if !bytes.Equal(generated, committed) {
+		t.Errorf("%s is out of date with the event structs; run `make ws-schema` to regenerate", eventSchemaPath)
+	}
+}
+
+func TestEventCatalog_CoversAllEventConstants(t *testing.T) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "events.go", nil, 0)
+	if err != nil {
+		t.Fatalf("failed to parse events.go: %v", err)
+	}
+
+	constants := make(map[string]string)
+	for _, decl := range file.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.CONST {
+			continue
+		}
+		for _, spec := range gen.Specs {
+			value := spec.(*ast.ValueSpec)
+			for i, name := range value.Names {
+				if !strings.HasPrefix(name.Name, "Event") || i >= len(value.Values) {
+					continue
+				}
+				lit, ok := value.Values[i].(*ast.BasicLit)
+				if !ok || lit.Kind != token.STRING {
+					continue
+				}
+				eventName, _ := strconv.Unquote(lit.Value)
+				constants[name.Name] = eventName
+			}
+		}
+	}
+	if len(constants) == 0 {
+		t.Fatal("no event constants found in events.go")
+	}
+
+	cataloged := make(map[string]bool)
+	for _, def := range EventCatalog {
+		if cataloged[def.Name] {
+			t.Errorf("event %q is registered more than once in EventCatalog", def.Name)
+		}
+		cataloged[def.Name] = true
+	}
+
+	for constName, eventName := range constants {
+		if !cataloged[eventName] {
+			t.Errorf("%s (%q) is missing from EventCatalog", constName, eventName)
+		}
+	}
+}
@@ -0,0 +1,236 @@
+package websocket
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/coder/websocket"
+	"github.com/kaitoyama/kaitoyama-server-template/internal/domain"
+)
+
+// fakeRoomUsecase はManagerからの通知を記録し、固定のスナップショットを返す
+type fakeRoomUsecase struct {
+	mutex        sync.Mutex
+	snapshot     *domain.RoomSnapshot
+	disconnected []int
+}
+
+func (f *fakeRoomUsecase) SetPlayerDisconnected(roomID int, playerID int) (*domain.Room, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	f.disconnected = append(f.disconnected, playerID)
+	return nil, nil
+}
+
+func (f *fakeRoomUsecase) SetPlayerReconnected(roomID int, playerID int) (*domain.Room, error) {
+	return nil, nil
+}
+
+func (f *fakeRoomUsecase) RemoveDisconnectedPlayer(roomID int, playerID int) (*domain.Room, error) {
+	return nil, nil
+}
+
+func (f *fakeRoomUsecase) GetRoomByID(roomID int) (*domain.Room, error) {
+	return nil, nil
+}
+
+func (f *fakeRoomUsecase) RemovePlayerFromRoom(roomID int, playerID int) (*domain.Room, error) {
+	return nil, nil
+}
+
+func (f *fakeRoomUsecase) GetRoomSnapshot(roomID int) (*domain.RoomSnapshot, error) {
+	return f.snapshot, nil
+}
+
+func (f *fakeRoomUsecase) disconnectedCount() int {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	return len(f.disconnected)
+}
+
+// newSessionTestManager はユーザー1の接続を受け付けるWebSocketサーバーを起動する
+// 接続ごとのクライアントIDはクエリパラメータclient_idで指定する
+func newSessionTestManager(t *testing.T) (*Manager, string) {
+	t.Helper()
+	manager := NewManager()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := websocket.Accept(w, r, nil)
+		if err != nil {
+			return
+		}
+		clientID := r.URL.Query().Get("client_id")
+		ctx, cancel := context.WithCancel(context.Background())
+		manager.AddClient(clientID, 1, nil, nil, conn, cancel)
+		// WebSocketHandlerと同様に、読み取りが終わったら接続を削除する
+		go func() {
+			defer manager.RemoveClient(clientID)
+			for {
+				if _, _, err := conn.Read(ctx); err != nil {
+					return
+				}
+			}
+		}()
+	}))
+	t.Cleanup(server.Close)
+
+	return manager, "ws" + strings.TrimPrefix(server.URL, "http")
+}
+
+// receivedEvent はテストで受信したイベントのうち検証に使うフィールド
+type receivedEvent struct {
+	Event   string `json:"event"`
+	Seq     uint64 `json:"seq"`
+	Content struct {
+		Seq         uint64 `json:"seq"`
+		NewClientID string `json:"new_client_id"`
+	} `json:"content"`
+}
+
+func dialSession(t *testing.T, url, clientID string) *websocket.Conn {
+	t.Helper()
+	conn, _, err := websocket.Dial(context.Background(), url+"?client_id="+clientID, nil)
+	if err != nil {
+		t.Fatalf("Dial() error = %v", err)
+	}
+	t.Cleanup(func() { conn.CloseNow() })
+	return conn
+}
+
+func readEvent(t *testing.T, conn *websocket.Conn) receivedEvent {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	_, data, err := conn.Read(ctx)
+	if err != nil {
+		t.Fatalf("Read() error = %v", err)
+	}
+	var event receivedEvent
+	if err := json.Unmarshal(data, &event); err != nil {
+		t.Fatalf("failed to unmarshal event %s: %v", data, err)
+	}
+	return event
+}
+
+func TestManager_ClosingOneSessionKeepsUserInRoom(t *testing.T) {
+	manager, url := newSessionTestManager(t)
+	roomUsecase := &fakeRoomUsecase{}
+	manager.SetRoomUsecase(roomUsecase)
+
+	first := dialSession(t, url, "first")
+	waitFor(t, func() bool { return manager.GetClientCount() == 1 })
+	if err := manager.JoinRoom(1, 7); err != nil {
+		t.Fatalf("JoinRoom() error = %v", err)
+	}
+
+	// 追加のセッションは既存のセッションと同じroomに所属する
+	second := dialSession(t, url, "second")
+	waitFor(t, func() bool { return manager.GetClientCount() == 2 })
+	if !manager.IsClientInRoom("second") {
+		t.Fatal("additional session should join the room of the existing session")
+	}
+
+	first.Close(websocket.StatusNormalClosure, "")
+	waitFor(t, func() bool { return manager.GetClientCount() == 1 })
+
+	manager.mutex.RLock()
+	roomClients := len(manager.roomClients[7])
+	_, disconnected := manager.disconnectedUsers[1]
+	manager.mutex.RUnlock()
+	if roomClients != 1 {
+		t.Errorf("room clients = %d, want 1", roomClients)
+	}
+	if disconnected || roomUsecase.disconnectedCount() != 0 {
+		t.Error("user should not be treated as disconnected while another session remains")
+	}
+
+	// 残ったセッションには引き続きroomイベントが届く
+	manager.SendEventToRoom(7, NewCountdownEvent(7, 3))
+	if got := readEvent(t, second); got.Event != EventCountdown {
+		t.Errorf("got event %q, want %q", got.Event, EventCountdown)
+	}
+}
+
+func TestManager_SingleSessionPolicyReplacesOlderSession(t *testing.T) {
+	manager, url := newSessionTestManager(t)
+	manager.SetSingleSessionPolicy(true)
+
+	older := dialSession(t, url, "older")
+	waitFor(t, func() bool { return manager.GetClientCount() == 1 })
+	dialSession(t, url, "newer")
+
+	got := readEvent(t, older)
+	if got.Event != EventSessionReplaced || got.Content.NewClientID != "newer" {
+		t.Errorf("got event %q new_client_id %q, want %q new_client_id %q", got.Event, got.Content.NewClientID, EventSessionReplaced, "newer")
+	}
+
+	// 通知の後に古い接続は切断される
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if _, _, err := older.Read(ctx); websocket.CloseStatus(err) != websocket.StatusPolicyViolation {
+		t.Errorf("older session close status = %v, want %v (err = %v)", websocket.CloseStatus(err), websocket.StatusPolicyViolation, err)
+	}
+
+	waitFor(t, func() bool { return manager.GetClientCount() == 1 })
+	manager.mutex.RLock()
+	_, newerRemains := manager.clients["newer"]
+	manager.mutex.RUnlock()
+	if !newerRemains {
+		t.Error("newer session should remain connected")
+	}
+}
+
+func TestManager_SnapshotSeqMatchesNextRoomEvent(t *testing.T) {
+	manager := NewManager()
+	manager.SetRoomUsecase(&fakeRoomUsecase{
+		snapshot: &domain.RoomSnapshot{ID: 7, Name: "room", State: domain.StateWaitingForPlayers},
+	})
+
+	roomID := 7
+	client := &Client{
+		ID:     "client-1",
+		UserID: 1,
+		RoomID: &roomID,
+		Cancel: func() {},
+		send:   make(chan []byte, 8),
+		done:   make(chan struct{}),
+	}
+	manager.mutex.Lock()
+	manager.clients[client.ID] = client
+	manager.userClients[client.UserID] = map[string]*Client{client.ID: client}
+	manager.roomClients[roomID] = []*Client{client}
+	manager.mutex.Unlock()
+
+	manager.SendEventToRoom(roomID, NewCountdownEvent(roomID, 3))
+	manager.SendEventToRoom(roomID, NewCountdownEvent(roomID, 2))
+	<-client.send
+	<-client.send
+
+	if err := manager.SendRoomSnapshot(client.ID); err != nil {
+		t.Fatalf("SendRoomSnapshot() error = %v", err)
+	}
+	manager.SendEventToRoom(roomID, NewCountdownEvent(roomID, 1))
+
+	var snapshot, next receivedEvent
+	if err := json.Unmarshal(<-client.send, &snapshot); err != nil {
+		t.Fatalf("failed to unmarshal snapshot: %v", err)
+	}
+	if err := json.Unmarshal(<-client.send, &next); err != nil {
+		t.Fatalf("failed to unmarshal event: %v", err)
+	}
+
+	if snapshot.Event != EventRoomSnapshot || snapshot.Content.Seq != 2 {
+		t.Errorf("got event %q with seq %d, want %q with seq 2", snapshot.Event, snapshot.Content.Seq, EventRoomSnapshot)
+	}
+	// スナップショット以降のイベントは、スナップショットのseqの次の番号から始まる
+	if next.Seq != snapshot.Content.Seq+1 {
+		t.Errorf("next event seq = %d, want %d", next.Seq, snapshot.Content.Seq+1)
+	}
+}
@@ -0,0 +1,41 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+// ActionError はルームアクション実行時のエラーと対応するHTTPステータスを保持する
+type ActionError struct {
+	Status  int
+	Message string
+}
+
+func (e *ActionError) Error() string {
+	return e.Message
+}
+
+func newActionError(status int, message string) *ActionError {
+	return &ActionError{
+		Status:  status,
+		Message: message,
+	}
+}
+
+// actionErrorStatus returns the HTTP status code corresponding to the error
+func actionErrorStatus(err error) int {
+	var actionErr *ActionError
+	if errors.As(err, &actionErr) {
+		return actionErr.Status
+	}
+	return http.StatusInternalServerError
+}
+
+// respondActionError writes the action error as a JSON response
+func respondActionError(c echo.Context, err error) error {
+	return c.JSON(actionErrorStatus(err), map[string]string{
+		"error": err.Error(),
+	})
+}
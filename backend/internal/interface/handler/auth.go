@@ -0,0 +1,181 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/kaitoyama/kaitoyama-server-template/internal/infrastructure/auth"
+	"github.com/kaitoyama/kaitoyama-server-template/internal/usecase"
+	"github.com/kaitoyama/kaitoyama-server-template/openapi/models"
+	"github.com/labstack/echo/v4"
+	"github.com/rs/zerolog/log"
+)
+
+// PostAuthRegister creates a new account and starts a session for it
+func (h *Handler) PostAuthRegister(c echo.Context) error {
+	var req models.UserCreate
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"message": "invalid request body"})
+	}
+	return h.register(c, req)
+}
+
+// PostAuthLogin checks the password of an existing account and starts a session for it
+func (h *Handler) PostAuthLogin(c echo.Context) error {
+	var req models.UserCreate
+	if err := c.Bind(&req); err != nil || req.Username == "" || req.Password == "" {
+		return c.JSON(http.StatusBadRequest, map[string]string{"message": "invalid request body"})
+	}
+	return h.login(c, req)
+}
+
+// PostAuthGuest creates a guest user with a generated name and starts a session for it
+func (h *Handler) PostAuthGuest(c echo.Context) error {
+	guest, err := h.userUsecase.CreateGuest(c.Request().Context())
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to create guest user")
+		return c.JSON(http.StatusInternalServerError, map[string]string{"message": "failed to create guest user"})
+	}
+	return h.startSession(c, http.StatusCreated, int32(guest.UserID), guest.Username)
+}
+
+// PostAuthUpgrade converts the authenticated guest into a full account, keeping its stats and scores
+func (h *Handler) PostAuthUpgrade(c echo.Context) error {
+	user, ok := auth.GetUserFromContext(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"message": "not authenticated"})
+	}
+	var req models.UserCreate
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"message": "invalid request body"})
+	}
+
+	upgraded, err := h.userUsecase.UpgradeGuest(c.Request().Context(), user.UserID, usecase.CreateUserRequest{
+		Username: req.Username,
+		Password: req.Password,
+	})
+	if errors.Is(err, usecase.ErrInvalidUsername) || errors.Is(err, usecase.ErrWeakPassword) {
+		return c.JSON(http.StatusBadRequest, map[string]string{"message": err.Error()})
+	}
+	if errors.Is(err, usecase.ErrUsernameTaken) {
+		return c.JSON(http.StatusConflict, map[string]string{"message": "username already taken"})
+	}
+	if errors.Is(err, usecase.ErrNotGuest) {
+		return c.JSON(http.StatusConflict, map[string]string{"message": "user is not a guest"})
+	}
+	if err != nil {
+		log.Error().Err(err).Int32("user_id", user.UserID).Msg("Failed to upgrade guest user")
+		return c.JSON(http.StatusInternalServerError, map[string]string{"message": "failed to upgrade guest user"})
+	}
+
+	// ゲストの名前を持つトークンを使えないよう、ゲストのセッションを終えて新しい名前で発行し直す
+	if user.SessionID != "" {
+		if err := h.sessions.EndSession(c.Request().Context(), user.SessionID); err != nil {
+			log.Error().Err(err).Msg("Failed to end guest session")
+			return c.JSON(http.StatusInternalServerError, map[string]string{"message": "failed to upgrade guest user"})
+		}
+	}
+	return h.startSession(c, http.StatusOK, int32(upgraded.UserID), upgraded.Username)
+}
+
+func (h *Handler) register(c echo.Context, req models.UserCreate) error {
+	created, err := h.userUsecase.RegisterUser(c.Request().Context(), usecase.CreateUserRequest{
+		Username: req.Username,
+		Password: req.Password,
+	})
+	if errors.Is(err, usecase.ErrInvalidUsername) || errors.Is(err, usecase.ErrWeakPassword) {
+		return c.JSON(http.StatusBadRequest, map[string]string{"message": err.Error()})
+	}
+	if errors.Is(err, usecase.ErrUsernameTaken) {
+		return c.JSON(http.StatusConflict, map[string]string{"message": "username already taken"})
+	}
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to create user")
+		return c.JSON(http.StatusInternalServerError, map[string]string{"message": "failed to create user"})
+	}
+	return h.startSession(c, http.StatusCreated, int32(created.UserID), created.Username)
+}
+
+func (h *Handler) login(c echo.Context, req models.UserCreate) error {
+	user, err := h.userUsecase.AuthenticateUser(c.Request().Context(), req.Username, req.Password)
+	if errors.Is(err, usecase.ErrInvalidCredentials) {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"message": "invalid credentials"})
+	}
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to authenticate user")
+		return c.JSON(http.StatusInternalServerError, map[string]string{"message": "failed to log in"})
+	}
+	return h.startSession(c, http.StatusOK, user.ID, user.Username)
+}
+
+// startSession はアクセストークンとリフレッシュトークンを発行してstatusで返す
+func (h *Handler) startSession(c echo.Context, status int, userID int32, username string) error {
+	tokens, err := h.sessions.StartSession(c.Request().Context(), userID, username)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to start session")
+		return c.JSON(http.StatusInternalServerError, map[string]string{"message": "failed to generate token"})
+	}
+	return c.JSON(status, authResponse(tokens))
+}
+
+// PostAuthRefresh exchanges a refresh token for a new token pair
+func (h *Handler) PostAuthRefresh(c echo.Context) error {
+	var req models.RefreshTokenRequest
+	if err := c.Bind(&req); err != nil || req.RefreshToken == "" {
+		return c.JSON(http.StatusBadRequest, map[string]string{"message": "invalid request body"})
+	}
+
+	tokens, err := h.sessions.Refresh(c.Request().Context(), req.RefreshToken)
+	if errors.Is(err, auth.ErrInvalidRefreshToken) || errors.Is(err, auth.ErrRefreshTokenReused) {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"message": "invalid refresh token"})
+	}
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to refresh token")
+		return c.JSON(http.StatusInternalServerError, map[string]string{"message": "failed to refresh token"})
+	}
+	return c.JSON(http.StatusOK, authResponse(tokens))
+}
+
+// PostAuthLogout revokes the session of the refresh token
+func (h *Handler) PostAuthLogout(c echo.Context) error {
+	var req models.RefreshTokenRequest
+	if err := c.Bind(&req); err != nil || req.RefreshToken == "" {
+		return c.JSON(http.StatusBadRequest, map[string]string{"message": "invalid request body"})
+	}
+
+	if err := h.sessions.Logout(c.Request().Context(), req.RefreshToken); err != nil {
+		log.Error().Err(err).Msg("Failed to log out")
+		return c.JSON(http.StatusInternalServerError, map[string]string{"message": "failed to log out"})
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+func authResponse(tokens *auth.TokenPair) models.AuthResponse {
+	response := models.AuthResponse{
+		Token:        tokens.AccessToken,
+		RefreshToken: tokens.RefreshToken,
+		ExpiresIn:    int(tokens.ExpiresIn.Seconds()),
+	}
+	response.User.Id = int(tokens.UserID)
+	response.User.Username = tokens.Username
+	return response
+}
+
+// PostWsTicket issues a single-use ticket for the authenticated user's WebSocket upgrade
+func (h *Handler) PostWsTicket(c echo.Context) error {
+	user, ok := auth.GetUserFromContext(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"message": "not authenticated"})
+	}
+
+	ticket, expiresAt, err := h.authService.IssueWebSocketTicket(user)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to issue WebSocket ticket")
+		return c.JSON(http.StatusInternalServerError, map[string]string{"message": "failed to issue ticket"})
+	}
+	return c.JSON(http.StatusOK, models.WebSocketTicket{
+		Ticket:    ticket,
+		ExpiresIn: int(time.Until(expiresAt).Round(time.Second).Seconds()),
+	})
+}
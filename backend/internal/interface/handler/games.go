@@ -0,0 +1,80 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/kaitoyama/kaitoyama-server-template/internal/domain"
+	"github.com/kaitoyama/kaitoyama-server-template/openapi/models"
+	"github.com/labstack/echo/v4"
+	"github.com/rs/zerolog/log"
+)
+
+// GetGamesGameIdReplay returns the replay of a finished game
+func (h *Handler) GetGamesGameIdReplay(c echo.Context, gameId int) error {
+	res, err := h.gameUsecase.GetGameReplay(c.Request().Context(), int64(gameId))
+	if errors.Is(err, domain.ErrGameNotFound) {
+		return c.JSON(http.StatusNotFound, map[string]string{
+			"error": "Game not found",
+		})
+	}
+	if errors.Is(err, domain.ErrReplayNotRecorded) {
+		return c.JSON(http.StatusNotFound, map[string]string{
+			"error": "Replay not recorded for this game",
+		})
+	}
+	if err != nil {
+		log.Error().Err(err).Int("game_id", gameId).Msg("Failed to get game replay")
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Failed to get game replay",
+		})
+	}
+
+	replay := res.Replay
+	response := models.GameReplay{
+		GameId:       int(replay.GameID),
+		RoomId:       replay.RoomID,
+		Seed:         replay.Seed,
+		StartedAt:    replay.StartedAt,
+		EndedAt:      replay.EndedAt,
+		InitialBoard: res.Result.Boards[0].Flatten(),
+		Players:      make([]models.GameReplayPlayer, 0, len(replay.Players)),
+		Moves:        make([]models.GameReplayMove, 0, len(replay.Moves)),
+	}
+	for _, p := range replay.Players {
+		response.Players = append(response.Players, models.GameReplayPlayer{
+			UserId:   p.UserID,
+			Username: p.Username,
+			Score:    p.Score,
+			Rank:     p.Rank,
+		})
+	}
+	for i, move := range replay.Moves {
+		changes := make([]models.MatchedRegion, 0, len(move.Changes))
+		for _, m := range move.Changes {
+			positions := make([][]int, 0, len(m.Positions))
+			for _, p := range m.Positions {
+				positions = append(positions, []int{p.Row, p.Col})
+			}
+			changes = append(changes, models.MatchedRegion{
+				LineType:  m.Linetype,
+				Index:     m.Index,
+				Positions: positions,
+			})
+		}
+		response.Moves = append(response.Moves, models.GameReplayMove{
+			Seq:              i + 1,
+			UserId:           move.UserID,
+			Expression:       move.Expression,
+			SubmittedVersion: move.SubmittedVersion,
+			Version:          move.Version,
+			Changes:          changes,
+			GainedScore:      move.GainedScore,
+			SubmittedAt:      move.SubmittedAt,
+			// Boards[0]は初期盤面なので、i手目の適用後はBoards[i+1]
+			Board: res.Result.Boards[i+1].Flatten(),
+		})
+	}
+
+	return c.JSON(http.StatusOK, response)
+}
@@ -9,26 +9,39 @@ import (
 )
 
 type Handler struct {
-	healthUsecase    usecase.HealthUsecase
-	roomUsecase      *usecase.RoomUsecase
-	userUsecase      *usecase.UserUsecase
-	jwtService       *auth.JWTService
-	wsManager        *websocket.Manager
-	WebSocketHandler *WebSocketHandler
+	healthUsecase      usecase.HealthUsecase
+	roomUsecase        *usecase.RoomUsecase
+	userUsecase        *usecase.UserUsecase
+	leaderboardUsecase *usecase.LeaderboardUsecase
+	gameUsecase        *usecase.GameUsecase
+	ratingUsecase      *usecase.RatingUsecase
+	sessions           *auth.SessionService
+	authService        *auth.AuthService
+	oidc               *auth.OIDCService
+	wsManager          *websocket.Manager
+	WebSocketHandler   *WebSocketHandler
 }
 
 func (h *Handler) GetHealth(c echo.Context) error {
 	return h.HealthCheck(c)
 }
 
-func NewHandler(dbChecker domain.DatabaseHealthChecker, wsManager *websocket.Manager, roomUsecase *usecase.RoomUsecase, userUsecase *usecase.UserUsecase, jwtService *auth.JWTService) *Handler {
-	wsHandler := NewWebSocketHandler(wsManager, roomUsecase, userUsecase)
-	return &Handler{
-		healthUsecase:    *usecase.NewHealthUsecase(dbChecker),
-		roomUsecase:      roomUsecase,
-		userUsecase:      userUsecase,
-		jwtService:       jwtService,
-		wsManager:        wsManager,
-		WebSocketHandler: wsHandler,
+func NewHandler(dbChecker domain.DatabaseHealthChecker, wsManager *websocket.Manager, roomUsecase *usecase.RoomUsecase, userUsecase *usecase.UserUsecase, leaderboardUsecase *usecase.LeaderboardUsecase, gameUsecase *usecase.GameUsecase, ratingUsecase *usecase.RatingUsecase, sessions *auth.SessionService, authService *auth.AuthService, oidc *auth.OIDCService) *Handler {
+	wsHandler := NewWebSocketHandler(wsManager, roomUsecase, authService)
+	h := &Handler{
+		healthUsecase:      *usecase.NewHealthUsecase(dbChecker),
+		roomUsecase:        roomUsecase,
+		userUsecase:        userUsecase,
+		leaderboardUsecase: leaderboardUsecase,
+		gameUsecase:        gameUsecase,
+		ratingUsecase:      ratingUsecase,
+		sessions:           sessions,
+		authService:        authService,
+		oidc:               oidc,
+		wsManager:          wsManager,
+		WebSocketHandler:   wsHandler,
 	}
+	// WebSocket経由のアクションもREST APIと同じ処理を通す
+	wsHandler.actions = h
+	return h
 }
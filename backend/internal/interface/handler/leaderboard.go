@@ -0,0 +1,90 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/kaitoyama/kaitoyama-server-template/internal/infrastructure/auth"
+	"github.com/kaitoyama/kaitoyama-server-template/internal/usecase"
+	"github.com/kaitoyama/kaitoyama-server-template/openapi/models"
+	"github.com/labstack/echo/v4"
+	"github.com/rs/zerolog/log"
+)
+
+// GetLeaderboard returns one page of the leaderboard
+func (h *Handler) GetLeaderboard(c echo.Context) error {
+	user, ok := auth.GetUserFromContext(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{
+			"error": "User not authenticated",
+		})
+	}
+
+	req := usecase.LeaderboardRequest{
+		Window: usecase.LeaderboardAllTime,
+		Mode:   usecase.LeaderboardBest,
+		Cursor: c.QueryParam("cursor"),
+		UserID: int(user.UserID),
+	}
+	if window := c.QueryParam("window"); window != "" {
+		req.Window = usecase.LeaderboardWindow(window)
+	}
+	if mode := c.QueryParam("mode"); mode != "" {
+		req.Mode = usecase.LeaderboardMode(mode)
+	}
+	if limit := c.QueryParam("limit"); limit != "" {
+		n, err := strconv.Atoi(limit)
+		if err != nil || n < 1 || n > usecase.MaxLeaderboardLimit {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": "Invalid limit",
+			})
+		}
+		req.Limit = n
+	}
+
+	leaderboard, err := h.leaderboardUsecase.GetLeaderboard(c.Request().Context(), req)
+	if err != nil {
+		if errors.Is(err, usecase.ErrInvalidLeaderboardWindow) ||
+			errors.Is(err, usecase.ErrInvalidLeaderboardMode) ||
+			errors.Is(err, usecase.ErrInvalidLeaderboardCursor) {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": err.Error(),
+			})
+		}
+		log.Error().Err(err).Msg("Failed to get leaderboard")
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Failed to get leaderboard",
+		})
+	}
+
+	response := models.Leaderboard{
+		Window:  string(leaderboard.Window),
+		Mode:    string(leaderboard.Mode),
+		Entries: make([]models.LeaderboardEntry, 0, len(leaderboard.Entries)),
+	}
+	for _, entry := range leaderboard.Entries {
+		item := models.LeaderboardEntry{
+			Rank:     entry.Rank,
+			UserId:   entry.UserID,
+			Username: entry.Username,
+			Score:    entry.Score,
+		}
+		if !entry.AchievedAt.IsZero() {
+			achievedAt := entry.AchievedAt
+			item.AchievedAt = &achievedAt
+		}
+		response.Entries = append(response.Entries, item)
+	}
+	if leaderboard.NextCursor != "" {
+		response.NextCursor = &leaderboard.NextCursor
+	}
+	if leaderboard.Me != nil {
+		response.Me = &models.LeaderboardRank{
+			Rank:  leaderboard.Me.Rank,
+			Score: leaderboard.Me.Score,
+		}
+	}
+
+	return c.JSON(http.StatusOK, response)
+}
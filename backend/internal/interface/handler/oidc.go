@@ -0,0 +1,103 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/kaitoyama/kaitoyama-server-template/internal/infrastructure/auth"
+	"github.com/kaitoyama/kaitoyama-server-template/internal/usecase"
+	"github.com/kaitoyama/kaitoyama-server-template/openapi/models"
+	"github.com/labstack/echo/v4"
+	"github.com/rs/zerolog/log"
+)
+
+// GetAuthOidcProviders lists the configured external OIDC providers
+func (h *Handler) GetAuthOidcProviders(c echo.Context) error {
+	return c.JSON(http.StatusOK, models.OIDCProviders{Providers: h.oidc.Providers()})
+}
+
+// GetAuthOidcProviderAuthorize starts a login with the provider and returns its authorization URL
+func (h *Handler) GetAuthOidcProviderAuthorize(c echo.Context, provider string) error {
+	authorizationURL, state, err := h.oidc.AuthorizationURL(c.Request().Context(), provider)
+	if errors.Is(err, auth.ErrUnknownOIDCProvider) {
+		return c.JSON(http.StatusNotFound, map[string]string{"message": "unknown provider"})
+	}
+	if err != nil {
+		log.Error().Err(err).Str("provider", provider).Msg("Failed to start OIDC login")
+		return c.JSON(http.StatusBadGateway, map[string]string{"message": "failed to reach the provider"})
+	}
+	return c.JSON(http.StatusOK, models.OIDCAuthorization{
+		AuthorizationUrl: authorizationURL,
+		State:            state,
+	})
+}
+
+// PostAuthOidcProviderCallback logs in with the account linked to the provider's account
+func (h *Handler) PostAuthOidcProviderCallback(c echo.Context, provider string) error {
+	identity, err := h.completeOIDC(c, provider)
+	if err != nil {
+		return err
+	}
+
+	user, err := h.userUsecase.LoginWithIdentity(c.Request().Context(), *identity)
+	if errors.Is(err, usecase.ErrUsernameTaken) || errors.Is(err, usecase.ErrInvalidUsername) {
+		return c.JSON(http.StatusConflict, map[string]string{
+			"message": "cannot create an account with the provider's username; log in and link the account instead",
+		})
+	}
+	if err != nil {
+		log.Error().Err(err).Str("provider", provider).Msg("Failed to log in with OIDC")
+		return c.JSON(http.StatusInternalServerError, map[string]string{"message": "failed to log in"})
+	}
+	return h.startSession(c, http.StatusOK, int32(user.UserID), user.Username)
+}
+
+// PostAuthOidcProviderLink links the provider's account to the authenticated user
+func (h *Handler) PostAuthOidcProviderLink(c echo.Context, provider string) error {
+	user, ok := auth.GetUserFromContext(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"message": "not authenticated"})
+	}
+	identity, err := h.completeOIDC(c, provider)
+	if err != nil {
+		return err
+	}
+
+	err = h.userUsecase.LinkIdentity(c.Request().Context(), user.UserID, *identity)
+	if errors.Is(err, usecase.ErrIdentityLinked) {
+		return c.JSON(http.StatusConflict, map[string]string{"message": "the account is linked to another user"})
+	}
+	if errors.Is(err, usecase.ErrGuestCannotLink) {
+		return c.JSON(http.StatusConflict, map[string]string{"message": "upgrade the guest account before linking"})
+	}
+	if err != nil {
+		log.Error().Err(err).Str("provider", provider).Int32("user_id", user.UserID).Msg("Failed to link OIDC account")
+		return c.JSON(http.StatusInternalServerError, map[string]string{"message": "failed to link account"})
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+// completeOIDC は認可コードをプロバイダーの検証済みのアカウントに交換する
+// エラーはステータスコード付きの*echo.HTTPError
+func (h *Handler) completeOIDC(c echo.Context, provider string) (*usecase.ExternalIdentity, error) {
+	var req models.OIDCCallbackRequest
+	if err := c.Bind(&req); err != nil || req.Code == "" || req.State == "" {
+		return nil, echo.NewHTTPError(http.StatusBadRequest, "invalid request body")
+	}
+
+	identity, err := h.oidc.Complete(c.Request().Context(), provider, req.Code, req.State)
+	switch {
+	case err == nil:
+		return identity, nil
+	case errors.Is(err, auth.ErrUnknownOIDCProvider):
+		return nil, echo.NewHTTPError(http.StatusNotFound, "unknown provider")
+	case errors.Is(err, auth.ErrInvalidOIDCState):
+		return nil, echo.NewHTTPError(http.StatusBadRequest, "invalid or expired state")
+	case errors.Is(err, auth.ErrOIDCAuthentication):
+		log.Warn().Err(err).Str("provider", provider).Msg("OIDC authentication failed")
+		return nil, echo.NewHTTPError(http.StatusUnauthorized, "authentication failed")
+	default:
+		log.Error().Err(err).Str("provider", provider).Msg("Failed to complete OIDC login")
+		return nil, echo.NewHTTPError(http.StatusBadGateway, "failed to reach the provider")
+	}
+}
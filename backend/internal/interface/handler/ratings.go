@@ -0,0 +1,57 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/kaitoyama/kaitoyama-server-template/internal/usecase"
+	"github.com/kaitoyama/kaitoyama-server-template/openapi/models"
+	"github.com/labstack/echo/v4"
+	"github.com/rs/zerolog/log"
+)
+
+// GetRatings returns users ordered by rating
+func (h *Handler) GetRatings(c echo.Context) error {
+	limit := usecase.DefaultRatingLimit
+	if s := c.QueryParam("limit"); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil || n < 1 || n > usecase.MaxRatingLimit {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": "Invalid limit",
+			})
+		}
+		limit = n
+	}
+	offset := 0
+	if s := c.QueryParam("offset"); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil || n < 0 {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": "Invalid offset",
+			})
+		}
+		offset = n
+	}
+
+	entries, err := h.ratingUsecase.ListRatings(c.Request().Context(), limit, offset)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to list ratings")
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Failed to list ratings",
+		})
+	}
+
+	response := make([]models.RatingEntry, 0, len(entries))
+	for _, entry := range entries {
+		response = append(response, models.RatingEntry{
+			Rank:        entry.Rank,
+			UserId:      entry.UserID,
+			Username:    entry.Username,
+			Rating:      entry.Rating,
+			Deviation:   entry.Deviation,
+			GamesPlayed: entry.GamesPlayed,
+		})
+	}
+
+	return c.JSON(http.StatusOK, response)
+}
@@ -1,6 +1,8 @@
 package handler
 
 import (
+	"errors"
+	"math"
 	"net/http"
 	"strings"
 	"time"
@@ -12,6 +14,11 @@ import (
 	"github.com/labstack/echo/v4"
 )
 
+const (
+	gameTimerTick    = 100 * time.Millisecond // ゲームタイマーの確認間隔
+	gameEndCountdown = 10 * time.Second       // 終了前カウントダウンの長さ
+)
+
 // GetRooms returns a list of all rooms
 func (h *Handler) GetRooms(c echo.Context) error {
 	rooms := h.roomUsecase.GetRooms()
@@ -40,32 +47,32 @@ func (h *Handler) PostRoomsRoomIdActions(c echo.Context, roomId int) error {
 		UserName: user.Username,
 	}
 
-	switch req.Action {
+	if err := h.PerformRoomAction(roomId, player, req.Action); err != nil {
+		return respondActionError(c, err)
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+// PerformRoomAction performs an action on a specific room.
+// RESTとWebSocketの両方から呼ばれる共通処理
+func (h *Handler) PerformRoomAction(roomId int, player domain.Player, action models.PostRoomsRoomIdActionsJSONBodyAction) error {
+	switch action {
 	case models.JOIN:
 		updatedRoom, err := h.roomUsecase.AddPlayerToRoom(roomId, player)
 		if err != nil {
-			return c.JSON(http.StatusInternalServerError, map[string]string{
-				"error": "Failed to join room: " + err.Error(),
-			})
+			return newActionError(http.StatusInternalServerError, "Failed to join room: "+err.Error())
 		}
 
 		// WebSocketでルームに参加
 		if h.WebSocketHandler != nil {
 			err = h.WebSocketHandler.JoinRoom(player.ID, roomId)
 			if err != nil {
-				return c.JSON(http.StatusInternalServerError, map[string]string{
-					"error": "Failed to join WebSocket room: " + err.Error(),
-				})
+				return newActionError(http.StatusInternalServerError, "Failed to join WebSocket room: "+err.Error())
 			}
 		}
 
 		// ルーム情報を構築
-		var playerInfos []wsManager.PlayerInfo
-		for _, p := range updatedRoom.Players {
-			playerInfos = append(playerInfos, wsManager.ConvertToPlayerInfo(
-				p.ID, p.UserName, p.IsReady, p.HasClosedResult, p.Score,
-			))
-		}
+		playerInfos := h.WebSocketHandler.PlayerInfos(updatedRoom.Players)
 
 		roomInfo := wsManager.ConvertToRoomInfo(
 			updatedRoom.ID,
@@ -79,14 +86,12 @@ func (h *Handler) PostRoomsRoomIdActions(c echo.Context, roomId int) error {
 		if h.WebSocketHandler != nil {
 			h.WebSocketHandler.SendPlayerJoinedEventToRoom(player.ID, player.UserName, roomInfo)
 		}
-		return c.NoContent(http.StatusNoContent)
+		return nil
 
 	case models.READY:
 		updatedRoom, err := h.roomUsecase.UpdatePlayerReadyStatus(roomId, player.ID, true)
 		if err != nil {
-			return c.JSON(http.StatusInternalServerError, map[string]string{
-				"error": "Failed to update ready status: " + err.Error(),
-			})
+			return newActionError(http.StatusInternalServerError, "Failed to update ready status: "+err.Error())
 		}
 		// WebSocketでルーム全員に通知
 		h.WebSocketHandler.SendPlayerEventToRoom(roomId, wsManager.EventPlayerReady, player.ID, player.UserName)
@@ -100,14 +105,12 @@ func (h *Handler) PostRoomsRoomIdActions(c echo.Context, roomId int) error {
 			}
 		}
 
-		return c.NoContent(http.StatusNoContent)
+		return nil
 
 	case models.CANCEL:
 		updatedRoom, err := h.roomUsecase.UpdatePlayerReadyStatus(roomId, player.ID, false)
 		if err != nil {
-			return c.JSON(http.StatusInternalServerError, map[string]string{
-				"error": "Failed to cancel ready status: " + err.Error(),
-			})
+			return newActionError(http.StatusInternalServerError, "Failed to cancel ready status: "+err.Error())
 		}
 
 		h.WebSocketHandler.SendPlayerEventToRoom(roomId, wsManager.EventPlayerCanceled, player.ID, player.UserName)
@@ -118,29 +121,23 @@ func (h *Handler) PostRoomsRoomIdActions(c echo.Context, roomId int) error {
 			// 現在は特別な処理なし
 		}
 
-		return c.NoContent(http.StatusNoContent)
+		return nil
 
 	case models.START:
 		room, err := h.roomUsecase.GetRoomByID(roomId)
 		if err != nil {
-			return c.JSON(http.StatusNotFound, map[string]string{
-				"error": "Room not found",
-			})
+			return newActionError(http.StatusNotFound, "Room not found")
 		}
 
 		// ゲーム開始権限チェック（最初のプレイヤーのみが開始可能）
 		firstPlayer := room.GetFirstPlayer()
 		if firstPlayer == nil || firstPlayer.ID != player.ID {
-			return c.JSON(http.StatusForbidden, map[string]string{
-				"error": "Only the first player can start the game",
-			})
+			return newActionError(http.StatusForbidden, "Only the first player can start the game")
 		}
 
 		_, err = h.roomUsecase.StartGame(roomId)
 		if err != nil {
-			return c.JSON(http.StatusConflict, map[string]string{
-				"error": "Cannot start game: " + err.Error(),
-			})
+			return newActionError(http.StatusConflict, "Cannot start game: "+err.Error())
 		}
 		// WebSocketでルーム全員にゲーム開始を通知
 		if h.WebSocketHandler != nil {
@@ -152,16 +149,14 @@ func (h *Handler) PostRoomsRoomIdActions(c echo.Context, roomId int) error {
 		if h.roomUsecase.CanStartGameTimer(roomId) {
 			go h.handleGameStart(roomId)
 		}
-		return c.NoContent(http.StatusNoContent)
+		return nil
 
 	case models.ABORT:
 		// WebSocketからルームを退出
 		if h.WebSocketHandler != nil {
 			err := h.WebSocketHandler.LeaveRoom(player.ID)
 			if err != nil {
-				return c.JSON(http.StatusInternalServerError, map[string]string{
-					"error": "Failed to leave WebSocket room: " + err.Error(),
-				})
+				return newActionError(http.StatusInternalServerError, "Failed to leave WebSocket room: "+err.Error())
 			}
 		}
 
@@ -173,12 +168,7 @@ func (h *Handler) PostRoomsRoomIdActions(c echo.Context, roomId int) error {
 
 		// ルーム情報を構築（退出後の状態）
 		if updatedRoom != nil && h.WebSocketHandler != nil {
-			var playerInfos []wsManager.PlayerInfo
-			for _, p := range updatedRoom.Players {
-				playerInfos = append(playerInfos, wsManager.ConvertToPlayerInfo(
-					p.ID, p.UserName, p.IsReady, p.HasClosedResult, p.Score,
-				))
-			}
+			playerInfos := h.WebSocketHandler.PlayerInfos(updatedRoom.Players)
 
 			roomInfo := wsManager.ConvertToRoomInfo(
 				updatedRoom.ID,
@@ -192,24 +182,43 @@ func (h *Handler) PostRoomsRoomIdActions(c echo.Context, roomId int) error {
 			h.WebSocketHandler.SendPlayerLeftEventToRoom(player.ID, player.UserName, roomInfo)
 		}
 
-		return c.NoContent(http.StatusNoContent)
+		return nil
+
+	case models.PAUSE, models.RESUME:
+		room, err := h.roomUsecase.GetRoomByID(roomId)
+		if err != nil {
+			return newActionError(http.StatusNotFound, "Room not found")
+		}
+
+		// 一時停止・再開はホスト（最初のプレイヤー）のみが可能
+		firstPlayer := room.GetFirstPlayer()
+		if firstPlayer == nil || firstPlayer.ID != player.ID {
+			return newActionError(http.StatusForbidden, "Only the first player can pause or resume the game")
+		}
+
+		// 一時停止・再開の通知はRoomUsecaseのGamePauseListenerが送信する
+		if action == models.PAUSE {
+			_, err = h.roomUsecase.PauseGame(roomId)
+		} else {
+			_, err = h.roomUsecase.ResumeGame(roomId)
+		}
+		if err != nil {
+			return newActionError(http.StatusConflict, err.Error())
+		}
+		return nil
 
 	case models.CLOSERESULT:
 		_, err := h.roomUsecase.CloseResult(roomId, player.ID)
 		if err != nil {
-			return c.JSON(http.StatusInternalServerError, map[string]string{
-				"error": "Failed to close result: " + err.Error(),
-			})
+			return newActionError(http.StatusInternalServerError, "Failed to close result: "+err.Error())
 		}
 
 		// WebSocketでルーム全員に通知
 		h.WebSocketHandler.SendPlayerEventToRoom(roomId, wsManager.EventResultClosed, player.ID, player.UserName)
-		return c.NoContent(http.StatusNoContent)
+		return nil
 
 	default:
-		return c.JSON(http.StatusBadRequest, map[string]string{
-			"error": "Invalid action",
-		})
+		return newActionError(http.StatusBadRequest, "Invalid action")
 	}
 }
 
@@ -233,37 +242,40 @@ func (h *Handler) PostRoomsRoomIdFormulas(c echo.Context, roomId int) error {
 		UserName: user.Username,
 	}
 
+	board, err := h.SubmitFormula(roomId, player, req.Formula, req.Version)
+	if err != nil {
+		return respondActionError(c, err)
+	}
+
+	// HTTPレスポンス（提出者に対する結果）
+	return c.JSON(http.StatusOK, board)
+}
+
+// SubmitFormula applies a formula to the room board and notifies room members.
+// RESTとWebSocketの両方から呼ばれる共通処理
+func (h *Handler) SubmitFormula(roomId int, player domain.Player, formula string, version int) (*models.Board, error) {
 	// すべてのチェックをApplyFormulaWithVersion内で原子的に実行
 	// ここでの事前チェックは削除してTOC-TOU問題を回避
-	board, gainScore, err := h.roomUsecase.ApplyFormulaWithVersion(roomId, player.ID, req.Formula, req.Version)
+	board, gainScore, err := h.roomUsecase.ApplyFormulaWithVersion(roomId, player.ID, formula, version)
 	if err != nil {
 		// バージョン衝突エラーの場合は409を返す
 		if strings.Contains(err.Error(), "他のプレイヤーによって更新されています") ||
 			strings.Contains(err.Error(), "無効なバージョンです") {
-			return c.JSON(http.StatusConflict, map[string]string{
-				"error": err.Error(),
-			})
+			return nil, newActionError(http.StatusConflict, err.Error())
 		}
 		// ルーム・プレイヤー・状態エラーの場合
-		if strings.Contains(err.Error(), "room with ID") && strings.Contains(err.Error(), "not found") {
-			return c.JSON(http.StatusNotFound, map[string]string{
-				"error": err.Error(),
-			})
+		if errors.Is(err, domain.ErrRoomNotFound) {
+			return nil, newActionError(http.StatusNotFound, err.Error())
 		}
 		if strings.Contains(err.Error(), "not in this room") {
-			return c.JSON(http.StatusForbidden, map[string]string{
-				"error": err.Error(),
-			})
+			return nil, newActionError(http.StatusForbidden, err.Error())
 		}
-		if strings.Contains(err.Error(), "game is not in progress") {
-			return c.JSON(http.StatusConflict, map[string]string{
-				"error": err.Error(),
-			})
+		if strings.Contains(err.Error(), "game is not in progress") ||
+			strings.Contains(err.Error(), "game is paused") {
+			return nil, newActionError(http.StatusConflict, err.Error())
 		}
 		// その他のエラーは400を返す
-		return c.JSON(http.StatusBadRequest, map[string]string{
-			"error": err.Error(),
-		})
+		return nil, newActionError(http.StatusBadRequest, err.Error())
 	}
 
 	// 盤面データを1次元配列に変換
@@ -284,12 +296,11 @@ func (h *Handler) PostRoomsRoomIdFormulas(c echo.Context, roomId int) error {
 		h.WebSocketHandler.SendBoardUpdateEventTyped(roomId, player.ID, player.UserName, boardData, gainScore)
 	}
 
-	// HTTPレスポンス（提出者に対する結果）
-	return c.JSON(http.StatusOK, models.Board{
+	return &models.Board{
 		Content:   content,
 		Version:   board.Version,
 		GainScore: gainScore,
-	})
+	}, nil
 }
 
 // GetRoomsRoomIdResult returns the results of a specific room
@@ -357,13 +368,13 @@ func (h *Handler) handleGameStart(roomID int) {
 	}
 
 	// カウントダウン完了後、ゲームを実際に開始
-	_, err := h.roomUsecase.CompleteCountdown(roomID)
+	room, err := h.roomUsecase.CompleteCountdown(roomID)
 	if err != nil {
 		return
 	}
 
-	// 新しいボードを生成
-	newBoard := domain.NewBoard()
+	// ゲーム開始時に決まったシードから新しいボードを生成（結果と一緒にシードを保存する）
+	newBoard := domain.NewSeededBoard(room.Seed)
 
 	// ボードをroomに追加
 	_, err = h.roomUsecase.UpdateGameBoard(roomID, newBoard)
@@ -393,42 +404,67 @@ func (h *Handler) handleGameStart(roomID int) {
 	go h.handleGameTimer(roomID)
 }
 
+// RestartGameTimers starts the game timers of games restored at startup
+func (h *Handler) RestartGameTimers() {
+	for _, roomID := range h.roomUsecase.GameRoomIDs() {
+		if h.roomUsecase.CanStartGameTimer(roomID) {
+			go h.handleGameTimer(roomID)
+		}
+	}
+}
+
 // handleGameTimer は120秒のゲームタイマーとラスト10秒のカウントダウンを処理する
+// 一時停止中は残り時間が凍結され、再開後はその続きから進行する
 func (h *Handler) handleGameTimer(roomID int) {
 	defer h.roomUsecase.StopGameTimer(roomID) // タイマー終了時にフラグをクリア
 
-	// 110秒待機（120秒 - 10秒のカウントダウン）
-	time.Sleep(110 * time.Second)
-
-	// ゲームがまだ進行中かチェック
-	room, err := h.roomUsecase.GetRoomByID(roomID)
-	if err != nil || room.State != domain.StateGameInProgress {
-		return // ゲームが既に終了している場合は何もしない
-	}
-
-	// ラスト10秒のカウントダウン開始を通知
-	if h.WebSocketHandler != nil {
-		h.WebSocketHandler.SendCountdownStartEventToRoom(roomID, "Game ending in 10 seconds", 10)
-	}
+	ticker := time.NewTicker(gameTimerTick)
+	defer ticker.Stop()
 
-	// 10秒のカウントダウン
-	for i := 10; i > 0; i-- {
+	countdownStarted := false
+	lastCount := 0
 
-		// 各秒でゲームがまだ進行中かチェック
-		room, err := h.roomUsecase.GetRoomByID(roomID)
-		if err != nil || room.State != domain.StateGameInProgress {
-			return // ゲームが既に終了している場合は中断
+	for range ticker.C {
+		clock, err := h.roomUsecase.GetGameClock(roomID)
+		if err != nil {
+			return
+		}
+		remaining := clock.Remaining
+
+		switch clock.State {
+		case domain.StatePaused:
+			// 一時停止中は残り時間が減らないので待つだけ（通知はGamePauseListenerが送る）
+			continue
+		case domain.StateGameInProgress:
+			// 残り時間を確認して進める
+		default:
+			return // ゲームが既に終了している場合は何もしない
 		}
 
-		if h.WebSocketHandler != nil {
-			h.WebSocketHandler.SendCountdownEventToRoom(roomID, i)
+		if remaining <= 0 {
+			break
 		}
 
-		time.Sleep(1 * time.Second)
+		// ラスト10秒のカウントダウン
+		if remaining <= gameEndCountdown {
+			if !countdownStarted {
+				countdownStarted = true
+				if h.WebSocketHandler != nil {
+					h.WebSocketHandler.SendCountdownStartEventToRoom(roomID, "Game ending in 10 seconds", int(gameEndCountdown.Seconds()))
+				}
+			}
+			count := int(math.Ceil(remaining.Seconds()))
+			if count != lastCount {
+				lastCount = count
+				if h.WebSocketHandler != nil {
+					h.WebSocketHandler.SendCountdownEventToRoom(roomID, count)
+				}
+			}
+		}
 	}
 
 	// タイマー終了、ゲームを終了する
-	_, err = h.roomUsecase.EndGame(roomID)
+	_, err := h.roomUsecase.EndGame(roomID)
 	if err != nil {
 		return
 	}
@@ -0,0 +1,61 @@
+package handler
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/kaitoyama/kaitoyama-server-template/internal/domain"
+	"github.com/kaitoyama/kaitoyama-server-template/internal/usecase"
+	"github.com/kaitoyama/kaitoyama-server-template/openapi/models"
+)
+
+func TestHandler_OnlyHostCanPauseAndResume(t *testing.T) {
+	rooms := usecase.NewRoomUsecase(nil)
+	h := &Handler{roomUsecase: rooms}
+
+	host := domain.Player{ID: 1, UserName: "alice"}
+	guest := domain.Player{ID: 2, UserName: "bob"}
+	for _, player := range []domain.Player{host, guest} {
+		if _, err := rooms.AddPlayerToRoom(1, player); err != nil {
+			t.Fatalf("AddPlayerToRoom() error = %v", err)
+		}
+		if _, err := rooms.UpdatePlayerReadyStatus(1, player.ID, true); err != nil {
+			t.Fatalf("UpdatePlayerReadyStatus() error = %v", err)
+		}
+	}
+	if _, err := rooms.StartGame(1); err != nil {
+		t.Fatalf("StartGame() error = %v", err)
+	}
+	if _, err := rooms.CompleteCountdown(1); err != nil {
+		t.Fatalf("CompleteCountdown() error = %v", err)
+	}
+
+	steps := []struct {
+		player     domain.Player
+		action     models.PostRoomsRoomIdActionsJSONBodyAction
+		wantStatus int // 0の場合は成功
+		wantState  domain.RoomState
+	}{
+		{guest, models.PAUSE, http.StatusForbidden, domain.StateGameInProgress},
+		{host, models.PAUSE, 0, domain.StatePaused},
+		{guest, models.RESUME, http.StatusForbidden, domain.StatePaused},
+		{host, models.RESUME, 0, domain.StateGameInProgress},
+	}
+	for _, step := range steps {
+		err := h.PerformRoomAction(1, step.player, step.action)
+		if step.wantStatus == 0 && err != nil {
+			t.Fatalf("%s by %s: error = %v", step.action, step.player.UserName, err)
+		}
+		if step.wantStatus != 0 && (err == nil || actionErrorStatus(err) != step.wantStatus) {
+			t.Fatalf("%s by %s: error = %v, want status %d", step.action, step.player.UserName, err, step.wantStatus)
+		}
+
+		room, err := rooms.GetRoomByID(1)
+		if err != nil {
+			t.Fatalf("GetRoomByID() error = %v", err)
+		}
+		if room.State != step.wantState {
+			t.Errorf("%s by %s: state = %s, want %s", step.action, step.player.UserName, room.State, step.wantState)
+		}
+	}
+}
@@ -1,77 +1,70 @@
 package handler
 
 import (
+	"errors"
 	"net/http"
 
+	"github.com/kaitoyama/kaitoyama-server-template/internal/infrastructure/auth"
 	"github.com/kaitoyama/kaitoyama-server-template/internal/usecase"
 	"github.com/kaitoyama/kaitoyama-server-template/openapi/models"
 	"github.com/labstack/echo/v4"
+	"github.com/rs/zerolog/log"
 )
 
+// PostUsers registers a new user. 既存のユーザー名は409を返し、ログインはしない
+// 旧クライアント向けに残しているPOST /auth/registerの別名で、ログインはPOST /auth/loginを使う
 func (h *Handler) PostUsers(c echo.Context) error {
 	var user models.UserCreate
 	if err := c.Bind(&user); err != nil {
 		return c.JSON(http.StatusBadRequest, map[string]string{"message": "invalid request body"})
 	}
+	return h.register(c, user)
+}
 
-	// まず既存ユーザーを確認
-	_, err := h.userUsecase.GetUserByUsername(c.Request().Context(), user.Username)
-	if err == nil {
-		// 既存ユーザーの場合：ログイン処理
-		authenticatedUser, authErr := h.userUsecase.AuthenticateUser(c.Request().Context(), user.Username, user.Password)
-		if authErr != nil {
-			return c.JSON(http.StatusUnauthorized, map[string]string{"message": "invalid credentials"})
-		}
-
-		// JWTトークンを生成
-		token, tokenErr := h.jwtService.GenerateToken(authenticatedUser.ID, authenticatedUser.Username)
-		if tokenErr != nil {
-			return c.JSON(http.StatusInternalServerError, map[string]string{"message": "failed to generate token"})
-		}
-
-		// レスポンスを作成
-		response := models.AuthResponse{
-			Token: token,
-			User: struct {
-				Id       int    `json:"id"`
-				Username string `json:"username"`
-			}{
-				Id:       int(authenticatedUser.ID),
-				Username: authenticatedUser.Username,
-			},
-		}
-
-		return c.JSON(http.StatusOK, response)
-	}
-
-	// 新規ユーザーの場合：登録処理
-	createReq := usecase.CreateUserRequest{
-		Username: user.Username,
-		Password: user.Password,
+// GetUsersMe returns the authenticated user's profile and statistics
+func (h *Handler) GetUsersMe(c echo.Context) error {
+	user, ok := auth.GetUserFromContext(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{
+			"error": "User not authenticated",
+		})
 	}
+	return h.respondUserStats(c, int(user.UserID))
+}
 
-	createResp, createErr := h.userUsecase.CreateUser(c.Request().Context(), createReq)
-	if createErr != nil {
-		return c.JSON(http.StatusInternalServerError, map[string]string{"message": "failed to create user"})
-	}
+// GetUsersUserIdStats returns the statistics of the specified user
+func (h *Handler) GetUsersUserIdStats(c echo.Context, userId int) error {
+	return h.respondUserStats(c, userId)
+}
 
-	// JWTトークンを生成
-	token, tokenErr := h.jwtService.GenerateToken(int32(createResp.UserID), createResp.Username)
-	if tokenErr != nil {
-		return c.JSON(http.StatusInternalServerError, map[string]string{"message": "failed to generate token"})
+func (h *Handler) respondUserStats(c echo.Context, userID int) error {
+	profile, err := h.userUsecase.GetUserProfile(c.Request().Context(), userID)
+	if errors.Is(err, usecase.ErrUserNotFound) {
+		return c.JSON(http.StatusNotFound, map[string]string{
+			"error": "User not found",
+		})
 	}
-
-	// レスポンスを作成
-	response := models.AuthResponse{
-		Token: token,
-		User: struct {
-			Id       int    `json:"id"`
-			Username string `json:"username"`
-		}{
-			Id:       int(createResp.UserID),
-			Username: createResp.Username,
-		},
+	if err != nil {
+		log.Error().Err(err).Int("user_id", userID).Msg("Failed to get user stats")
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Failed to get user stats",
+		})
 	}
 
-	return c.JSON(http.StatusCreated, response)
+	stats := profile.Stats
+	return c.JSON(http.StatusOK, models.UserStats{
+		UserId:              profile.UserID,
+		Username:            profile.Username,
+		IsGuest:             profile.IsGuest,
+		GamesPlayed:         stats.GamesPlayed,
+		Wins:                stats.Wins,
+		BestScore:           stats.BestScore,
+		AverageScore:        stats.AverageScore(),
+		CurrentStreak:       stats.CurrentStreak,
+		LongestStreak:       stats.LongestStreak,
+		LongestAnswerStreak: stats.LongestAnswerStreak,
+		AcceptedFormulas:    stats.AcceptedFormulas,
+		RejectedFormulas:    stats.RejectedFormulas,
+		Accuracy:            stats.Accuracy(),
+	})
 }
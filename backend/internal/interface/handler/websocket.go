@@ -2,49 +2,53 @@ package handler
 
 import (
 	"context"
+	"encoding/json"
+	"net/http"
 	"strconv"
 	"time"
 
 	"github.com/coder/websocket"
 	"github.com/google/uuid"
+	"github.com/kaitoyama/kaitoyama-server-template/internal/domain"
+	"github.com/kaitoyama/kaitoyama-server-template/internal/infrastructure/auth"
 	wsManager "github.com/kaitoyama/kaitoyama-server-template/internal/infrastructure/websocket"
 	"github.com/kaitoyama/kaitoyama-server-template/internal/usecase"
+	"github.com/kaitoyama/kaitoyama-server-template/openapi/models"
 	"github.com/labstack/echo/v4"
 	"github.com/rs/zerolog/log"
 )
 
 type WebSocketHandler struct {
-	manager     *wsManager.Manager
-	roomUsecase *usecase.RoomUsecase
-	userUsecase *usecase.UserUsecase
+	manager        *wsManager.Manager
+	roomUsecase    *usecase.RoomUsecase
+	authService    *auth.AuthService
+	originPatterns []string // 接続を許可するOriginのホストのパターン（同一オリジンは常に許可）
+	actions        *Handler // クライアントから受信したアクションの実行先（REST APIと共通）
 }
 
-func NewWebSocketHandler(manager *wsManager.Manager, roomUsecase *usecase.RoomUsecase, userUsecase *usecase.UserUsecase) *WebSocketHandler {
+func NewWebSocketHandler(manager *wsManager.Manager, roomUsecase *usecase.RoomUsecase, authService *auth.AuthService) *WebSocketHandler {
 	return &WebSocketHandler{
 		manager:     manager,
 		roomUsecase: roomUsecase,
-		userUsecase: userUsecase,
+		authService: authService,
 	}
 }
 
-func (h *WebSocketHandler) HandleWebSocket(c echo.Context) error {
-	// ユーザー名をクエリパラメータから取得
-	username := c.QueryParam("username")
-	if username == "" {
-		log.Error().Msg("Username is required for WebSocket connection")
-		// WebSocketアップグレード前なので、まだJSONレスポンスが可能
-		return c.JSON(400, map[string]string{"error": "username is required"})
-	}
+// SetAllowedOrigins sets the Origin host patterns allowed to connect from other origins
+func (h *WebSocketHandler) SetAllowedOrigins(patterns []string) {
+	h.originPatterns = patterns
+}
 
-	// データベースからユーザーを検索
-	user, err := h.userUsecase.GetUserByUsername(c.Request().Context(), username)
+func (h *WebSocketHandler) HandleWebSocket(c echo.Context) error {
+	// X-Forwarded-User・チケット・JWTのいずれかでユーザーを確認
+	claims, err := h.authService.AuthenticateWebSocket(c)
 	if err != nil {
-		log.Error().Err(err).Str("username", username).Msg("User not found for WebSocket connection")
+		log.Warn().Err(err).Msg("Unauthenticated WebSocket connection")
 		// WebSocketアップグレード前なので、まだJSONレスポンスが可能
-		return c.JSON(404, map[string]string{"error": "user not found"})
+		return err
 	}
 
-	userID := int(user.ID)
+	userID := int(claims.UserID)
 
 	// ルームIDをクエリパラメータから取得
 	roomIDStr := c.QueryParam("room_id")
@@ -55,10 +59,18 @@ func (h *WebSocketHandler) HandleWebSocket(c echo.Context) error {
 		}
 	}
 
+	// 最後に受信したroomイベントのシーケンス番号（再接続時の取りこぼし再送用）
+	var lastSeq *uint64
+	if lastSeqStr := c.QueryParam("last_seq"); lastSeqStr != "" {
+		if seq, err := strconv.ParseUint(lastSeqStr, 10, 64); err == nil {
+			lastSeq = &seq
+		}
+	}
+
 	// WebSocket接続をアップグレード（CORS対応のオプション追加）
 	conn, err := websocket.Accept(c.Response().Writer, c.Request(), &websocket.AcceptOptions{
 		Subprotocols:   []string{"echo"},
-		OriginPatterns: []string{"localhost:5173"},
+		OriginPatterns: h.originPatterns,
 	})
 	if err != nil {
 		log.Error().Err(err).Msg("Failed to upgrade WebSocket connection")
@@ -73,40 +85,55 @@ func (h *WebSocketHandler) HandleWebSocket(c echo.Context) error {
 	ctx, cancel := context.WithCancel(context.Background())
 
 	// クライアントをマネージャーに登録
-	h.manager.AddClient(clientID, userID, initialRoomID, conn, cancel)
+	h.manager.AddClient(clientID, userID, initialRoomID, lastSeq, conn, cancel)
 
 	// 接続完了メッセージを送信
 	if err := h.SendConnectionEvent(userID, clientID, "Connected successfully", time.Now().Unix()); err != nil {
 		log.Error().Err(err).Int("user_id", userID).Msg("Failed to send welcome message")
 	}
 
-	// 接続を維持し、切断を監視
-	go h.handleConnection(ctx, conn, clientID)
+	// 再接続の場合は取りこぼしたroomイベントを再送
+	if lastSeq != nil {
+		h.manager.ReplayRoomEvents(clientID, *lastSeq)
+	}
+
+	// roomが復元された場合は現在の全状態を送信（再送イベントより後に届く）
+	if h.manager.IsClientInRoom(clientID) {
+		if err := h.manager.SendRoomSnapshot(clientID); err != nil {
+			log.Error().Err(err).Str("client_id", clientID).Msg("Failed to send room snapshot")
+		}
+	}
+
+	player := domain.Player{
+		ID:       userID,
+		UserName: claims.Username,
+	}
+
+	// 接続を維持し、クライアントからのメッセージを処理
+	go h.handleConnection(ctx, conn, clientID, player)
 
 	return nil
 }
 
-func (h *WebSocketHandler) handleConnection(ctx context.Context, conn *websocket.Conn, clientID string) {
+func (h *WebSocketHandler) handleConnection(ctx context.Context, conn *websocket.Conn, clientID string, player domain.Player) {
 	defer func() {
 		h.manager.RemoveClient(clientID)
 		conn.Close(websocket.StatusNormalClosure, "Connection closed")
 	}()
 
-	// 一方向通信なので、クライアントからのメッセージは基本的に受信しない
-	// ただし、接続の生存確認のためにpingを監視
+	// クライアントからのアクションメッセージを受信する
+	// 生存確認はManagerのハートビート（Ping/Pong）で行うため、読み取りにはタイムアウトを設けない
+	// （Pongの受信もReadの中で処理される）
 	for {
 		select {
 		case <-ctx.Done():
 			log.Info().Str("client_id", clientID).Msg("WebSocket connection context cancelled")
 			return
 		default:
-			// タイムアウト付きでメッセージを読み取り（主にpingフレーム用）
-			ctx, cancel := context.WithTimeout(ctx, 300*time.Second)
-			_, _, err := conn.Read(ctx)
-			cancel()
+			_, data, err := conn.Read(ctx)
 
 			if err != nil {
-				// 通常の切断またはタイムアウトの場合
+				// 通常の切断の場合
 				if websocket.CloseStatus(err) == websocket.StatusNormalClosure ||
 					websocket.CloseStatus(err) == websocket.StatusGoingAway {
 					log.Info().Str("client_id", clientID).Msg("WebSocket connection closed normally")
@@ -115,8 +142,90 @@ func (h *WebSocketHandler) handleConnection(ctx context.Context, conn *websocket
 				}
 				return
 			}
+
+			// 受信順に処理することで同一接続内のアクションの順序を保証
+			h.handleClientMessage(clientID, player, data)
+		}
+	}
+}
+
+// handleClientMessage はクライアントから受信したアクションを実行し、request_id付きの応答を返す
+func (h *WebSocketHandler) handleClientMessage(clientID string, player domain.Player, data []byte) {
+	var msg wsManager.ClientMessage
+	if err := json.Unmarshal(data, &msg); err != nil {
+		h.sendReply(clientID, wsManager.NewErrorEvent("", "", http.StatusBadRequest, "Invalid message format"))
+		return
+	}
+
+	// スナップショット要求はルーム操作ではないため直接処理
+	if msg.Action == wsManager.ActionGetRoomSnapshot {
+		if err := h.manager.SendRoomSnapshot(clientID); err != nil {
+			h.sendReply(clientID, wsManager.NewErrorEvent(msg.RequestID, msg.Action, http.StatusConflict, err.Error()))
+			return
+		}
+		h.sendReply(clientID, wsManager.NewAckEvent(msg.RequestID, msg.Action, nil))
+		return
+	}
+
+	if h.actions == nil {
+		h.sendReply(clientID, wsManager.NewErrorEvent(msg.RequestID, msg.Action, http.StatusServiceUnavailable, "Actions are not available"))
+		return
+	}
+
+	var result interface{}
+	var err error
+	switch msg.Action {
+	case wsManager.ActionSubmitFormula:
+		result, err = h.actions.SubmitFormula(msg.RoomID, player, msg.Formula, msg.Version)
+	default:
+		err = h.actions.PerformRoomAction(msg.RoomID, player, models.PostRoomsRoomIdActionsJSONBodyAction(msg.Action))
+	}
+
+	if err != nil {
+		log.Warn().Err(err).
+			Str("client_id", clientID).
+			Str("request_id", msg.RequestID).
+			Str("action", msg.Action).
+			Msg("WebSocket action failed")
+		h.sendReply(clientID, wsManager.NewErrorEvent(msg.RequestID, msg.Action, actionErrorStatus(err), err.Error()))
+		return
+	}
+
+	h.sendReply(clientID, wsManager.NewAckEvent(msg.RequestID, msg.Action, result))
+}
+
+// GetEventSchema returns the JSON Schema of the WebSocket events
+func (h *WebSocketHandler) GetEventSchema(c echo.Context) error {
+	schema, err := wsManager.GenerateEventSchema()
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to generate WebSocket event schema")
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"message": "Failed to generate schema",
+		})
+	}
+	return c.Blob(http.StatusOK, "application/schema+json", schema)
+}
+
+func (h *WebSocketHandler) sendReply(clientID string, event wsManager.WebSocketEvent) {
+	if err := h.manager.SendEventToClient(clientID, event); err != nil {
+		log.Error().Err(err).Str("client_id", clientID).Msg("Failed to send reply to client")
+	}
+}
+
+// PlayerInfos converts room players to PlayerInfo with the latency measured by heartbeats
+func (h *WebSocketHandler) PlayerInfos(players []domain.Player) []wsManager.PlayerInfo {
+	var playerInfos []wsManager.PlayerInfo
+	for _, p := range players {
+		info := wsManager.ConvertToPlayerInfo(p.ID, p.UserName, p.IsReady, p.HasClosedResult, p.Score)
+		// WebSocketHandlerが未設定の場合はレイテンシなしで変換
+		if h != nil {
+			if latency, ok := h.manager.UserLatency(p.ID); ok {
+				info.LatencyMs = latency.Milliseconds()
+			}
 		}
+		playerInfos = append(playerInfos, info)
 	}
+	return playerInfos
 }
 
 // 全クライアントにメッセージを送信
@@ -189,10 +298,10 @@ func (h *WebSocketHandler) SendBoardUpdateEvent(roomID int, userID int, userName
 
 // 新しい統一されたイベント送信メソッド群
 
-// SendConnectionEvent sends a connection event to a user
+// SendConnectionEvent sends a connection event to the newly connected session only
 func (h *WebSocketHandler) SendConnectionEvent(userID int, clientID string, message string, timestamp int64) error {
 	event := wsManager.NewConnectionEvent(clientID, userID, message, timestamp)
-	return h.manager.SendEventToUser(userID, event)
+	return h.manager.SendEventToClient(clientID, event)
 }
 
 // SendPlayerEventToRoom sends a player event to all room members
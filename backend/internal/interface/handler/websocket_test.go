@@ -0,0 +1,65 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/coder/websocket"
+	"github.com/kaitoyama/kaitoyama-server-template/internal/domain"
+	wsManager "github.com/kaitoyama/kaitoyama-server-template/internal/infrastructure/websocket"
+)
+
+func TestWebSocketHandler_UnknownActionRepliesWithError(t *testing.T) {
+	manager := wsManager.NewManager()
+	h := &WebSocketHandler{
+		manager: manager,
+		actions: &Handler{},
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := websocket.Accept(w, r, nil)
+		if err != nil {
+			return
+		}
+		ctx, cancel := context.WithCancel(context.Background())
+		manager.AddClient("client-1", 1, nil, nil, conn, cancel)
+		go h.handleConnection(ctx, conn, "client-1", domain.Player{ID: 1, UserName: "alice"})
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	conn, _, err := websocket.Dial(ctx, "ws"+strings.TrimPrefix(server.URL, "http"), nil)
+	if err != nil {
+		t.Fatalf("Dial() error = %v", err)
+	}
+	defer conn.CloseNow()
+
+	if err := conn.Write(ctx, websocket.MessageText, []byte(`{"request_id":"req-1","action":"DANCE","room_id":1}`)); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+
+	_, data, err := conn.Read(ctx)
+	if err != nil {
+		t.Fatalf("Read() error = %v", err)
+	}
+	var got struct {
+		Event   string                      `json:"event"`
+		Content wsManager.ErrorEventContent `json:"content"`
+	}
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("failed to unmarshal reply %s: %v", data, err)
+	}
+
+	if got.Event != wsManager.EventError {
+		t.Fatalf("got event %q, want %q", got.Event, wsManager.EventError)
+	}
+	if got.Content.RequestID != "req-1" || got.Content.Action != "DANCE" || got.Content.Code != http.StatusBadRequest {
+		t.Errorf("got error reply %+v, want request_id req-1, action DANCE and code %d", got.Content, http.StatusBadRequest)
+	}
+}
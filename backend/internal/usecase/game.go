@@ -0,0 +1,48 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/kaitoyama/kaitoyama-server-template/internal/domain"
+)
+
+type GameUsecase struct {
+	gameResults domain.GameResultRepository
+}
+
+func NewGameUsecase(gameResults domain.GameResultRepository) *GameUsecase {
+	return &GameUsecase{
+		gameResults: gameResults,
+	}
+}
+
+// GameReplayResponse はゲームの記録と、記録から再構築した各バージョンの盤面
+type GameReplayResponse struct {
+	Replay *domain.GameReplay
+	Result *domain.ReplayResult
+}
+
+// GetGameReplay returns the recorded game together with every board version reconstructed from it.
+// 記録から最終結果を再現できない場合はエラーを返す
+func (g *GameUsecase) GetGameReplay(ctx context.Context, gameID int64) (*GameReplayResponse, error) {
+	replay, err := g.gameResults.GetGameReplay(ctx, gameID)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := replay.Replay()
+	if err != nil {
+		return nil, fmt.Errorf("failed to replay game %d: %w", gameID, err)
+	}
+	for _, p := range replay.Players {
+		if result.Scores[p.UserID] != p.Score {
+			return nil, fmt.Errorf("failed to replay game %d: user %d scored %d on replay, recorded %d", gameID, p.UserID, result.Scores[p.UserID], p.Score)
+		}
+	}
+
+	return &GameReplayResponse{
+		Replay: replay,
+		Result: result,
+	}, nil
+}
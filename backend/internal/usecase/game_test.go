@@ -0,0 +1,212 @@
+package usecase
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/kaitoyama/kaitoyama-server-template/internal/domain"
+)
+
+// memoryGameResults は保存されたゲーム結果をメモリに保持するGameResultRepository
+type memoryGameResults struct {
+	results []domain.GameResult
+}
+
+func (m *memoryGameResults) SaveGameResult(ctx context.Context, result domain.GameResult) (int64, error) {
+	m.results = append(m.results, result)
+	return int64(len(m.results)), nil
+}
+
+func (m *memoryGameResults) GetGameReplay(ctx context.Context, gameID int64) (*domain.GameReplay, error) {
+	if gameID < 1 || int(gameID) > len(m.results) {
+		return nil, domain.ErrGameNotFound
+	}
+	result := m.results[gameID-1]
+	return &domain.GameReplay{
+		GameID:       gameID,
+		GameUUID:     result.GameUUID,
+		RoomID:       result.RoomID,
+		Seed:         result.Seed,
+		StartedAt:    result.StartedAt,
+		EndedAt:      result.EndedAt,
+		InitialBoard: result.InitialBoard,
+		Players:      result.Players,
+		Moves:        result.Moves,
+	}, nil
+}
+
+// solveLine は数字の組から10になる逆ポーランド記法の数式を探す
+func solveLine(numbers []int) (string, bool) {
+	calculator := domain.NewFormulaCalculator()
+	shapes := []string{"nnonono", "nnnoono", "nnonnoo", "nnnonoo", "nnnnooo"}
+	ops := []byte("+-*/")
+
+	var permute func(rest []int, picked []int) (string, bool)
+	permute = func(rest []int, picked []int) (string, bool) {
+		if len(rest) == 0 {
+			for _, shape := range shapes {
+				for o := 0; o < len(ops)*len(ops)*len(ops); o++ {
+					opSeq := []byte{ops[o%4], ops[o/4%4], ops[o/16%4]}
+					expr := make([]byte, 0, len(shape))
+					ni, oi := 0, 0
+					for _, c := range shape {
+						if c == 'n' {
+							expr = append(expr, byte('0'+picked[ni]))
+							ni++
+						} else {
+							expr = append(expr, opSeq[oi])
+							oi++
+						}
+					}
+					result, err := calculator.EvaluateFormula(string(expr))
+					if err == nil && calculator.CheckTarget10(result) {
+						return string(expr), true
+					}
+				}
+			}
+			return "", false
+		}
+		for i := range rest {
+			next := append(append([]int{}, rest[:i]...), rest[i+1:]...)
+			if expr, ok := permute(next, append(picked, rest[i])); ok {
+				return expr, true
+			}
+		}
+		return "", false
+	}
+	return permute(numbers, nil)
+}
+
+// findMove は盤面の行・列から10を作れる数式を探す
+func findMove(board domain.GameBoard) (string, bool) {
+	for i := 0; i < board.Size; i++ {
+		row := append([]int{}, board.Board[i]...)
+		if expr, ok := solveLine(row); ok {
+			return expr, true
+		}
+		col := make([]int, board.Size)
+		for j := 0; j < board.Size; j++ {
+			col[j] = board.Board[j][i]
+		}
+		if expr, ok := solveLine(col); ok {
+			return expr, true
+		}
+	}
+	return "", false
+}
+
+func TestGameReplay_ReproducesFinalBoardAndScores(t *testing.T) {
+	results := &memoryGameResults{}
+	rooms := NewRoomUsecase(results)
+	const roomID = 1
+
+	for _, p := range []domain.Player{{ID: 1, UserName: "alice"}, {ID: 2, UserName: "bob"}} {
+		if _, err := rooms.AddPlayerToRoom(roomID, p); err != nil {
+			t.Fatalf("AddPlayerToRoom() error = %v", err)
+		}
+		if _, err := rooms.UpdatePlayerReadyStatus(roomID, p.ID, true); err != nil {
+			t.Fatalf("UpdatePlayerReadyStatus() error = %v", err)
+		}
+	}
+	if _, err := rooms.StartGame(roomID); err != nil {
+		t.Fatalf("StartGame() error = %v", err)
+	}
+	room, err := rooms.CompleteCountdown(roomID)
+	if err != nil {
+		t.Fatalf("CompleteCountdown() error = %v", err)
+	}
+	// 毎回同じ展開になるようシードを固定する
+	room.Seed = 20240601
+	if _, err := rooms.UpdateGameBoard(roomID, domain.NewSeededBoard(room.Seed)); err != nil {
+		t.Fatalf("UpdateGameBoard() error = %v", err)
+	}
+
+	accepted := 0
+	for turn := 0; turn < 30; turn++ {
+		board := room.GameBoards[len(room.GameBoards)-1]
+		expr, ok := findMove(board)
+		if !ok {
+			break
+		}
+		playerID := 1 + turn%3%2 // 1, 2, 1, 1, 2, 1, ... で連続正解も発生させる
+
+		// 10にならない数式は拒否され、リプレイには含まれない
+		if turn%5 == 0 {
+			if _, _, err := rooms.ApplyFormulaWithVersion(roomID, playerID, "1111+++", board.Version); err == nil {
+				t.Fatalf("ApplyFormulaWithVersion(%q) succeeded, want rejection", "1111+++")
+			}
+		}
+
+		if _, _, err := rooms.ApplyFormulaWithVersion(roomID, playerID, expr, board.Version); err != nil {
+			t.Fatalf("turn %d: ApplyFormulaWithVersion(%q) error = %v", turn, expr, err)
+		}
+		accepted++
+	}
+	if accepted < 5 {
+		t.Fatalf("only %d moves were accepted, want at least 5", accepted)
+	}
+
+	finalBoard := room.GameBoards[len(room.GameBoards)-1].Copy()
+	scores := make(map[int]int)
+	for _, p := range room.Players {
+		scores[p.ID] = p.Score
+	}
+	if _, err := rooms.EndGame(roomID); err != nil {
+		t.Fatalf("EndGame() error = %v", err)
+	}
+
+	res, err := NewGameUsecase(results).GetGameReplay(context.Background(), 1)
+	if err != nil {
+		t.Fatalf("GetGameReplay() error = %v", err)
+	}
+	if len(res.Replay.Moves) != accepted {
+		t.Errorf("replay has %d moves, want %d", len(res.Replay.Moves), accepted)
+	}
+	if len(res.Result.Boards) != accepted+1 {
+		t.Errorf("replay reconstructed %d boards, want %d", len(res.Result.Boards), accepted+1)
+	}
+	if !reflect.DeepEqual(res.Result.Final.Board, finalBoard.Board) || res.Result.Final.Version != finalBoard.Version {
+		t.Errorf("replayed final board = %v (version %d), want %v (version %d)",
+			res.Result.Final.Board, res.Result.Final.Version, finalBoard.Board, finalBoard.Version)
+	}
+	if !reflect.DeepEqual(res.Result.Scores, scores) {
+		t.Errorf("replayed scores = %v, want %v", res.Result.Scores, scores)
+	}
+}
+
+func TestGameReplay_DetectsTamperedMoves(t *testing.T) {
+	board := domain.NewSeededBoard(7)
+	expr, ok := findMove(board)
+	if !ok {
+		t.Skip("no solvable line on the seeded board")
+	}
+	move := domain.AttemptMove(&board, expr, board.Version)
+
+	replay := domain.GameReplay{
+		Seed:         7,
+		InitialBoard: domain.NewSeededBoard(7).Board,
+		Players:      []domain.GamePlayerResult{{UserID: 1}},
+		Moves: []domain.GameMove{{
+			UserID:           1,
+			Expression:       expr,
+			SubmittedVersion: 1,
+			Version:          2,
+			Changes:          move.Matches,
+			GainedScore:      len(move.Matches) * 10,
+		}},
+	}
+	if _, err := replay.Replay(); err != nil {
+		t.Fatalf("Replay() error = %v", err)
+	}
+
+	replay.Moves[0].GainedScore++
+	if _, err := replay.Replay(); err == nil {
+		t.Error("Replay() with a tampered score error = nil, want error")
+	}
+	replay.Moves[0].GainedScore--
+	replay.InitialBoard = domain.NewSeededBoard(8).Board
+	if _, err := replay.Replay(); err == nil {
+		t.Error("Replay() with a different initial board error = nil, want error")
+	}
+}
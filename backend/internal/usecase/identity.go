@@ -0,0 +1,151 @@
+package usecase
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/kaitoyama/kaitoyama-server-template/internal/db"
+)
+
+var (
+	// ErrIdentityLinked is returned when the external account is already linked to another user
+	ErrIdentityLinked = errors.New("identity already linked to another user")
+	// ErrGuestCannotLink is returned when linking an external account to a guest.
+	// ゲストは期限が過ぎると削除されるため、先に本登録してから紐付ける
+	ErrGuestCannotLink = errors.New("guest users cannot link external accounts")
+)
+
+// ExternalIdentity is an account authenticated by an external OAuth2/OIDC provider
+type ExternalIdentity struct {
+	Provider string
+	Subject  string // IDトークンのsub（プロバイダー内で不変の識別子）
+	Username string // プロバイダーでのユーザー名（新しいuserを作るときの名前）
+	// LinkByUsername はプロバイダーの設定。trueの場合、初回ログイン時に同じ名前のX-Forwarded-Userのユーザーに紐付ける
+	LinkByUsername bool
+}
+
+// LoginWithIdentity returns the user linked to the external account, creating one on the first login.
+// 紐付けがない場合、LinkByUsernameなら同じ名前のパスワードを持たないユーザー（同じプロバイダーを使う認証プロキシ経由で作られたもの）に紐付け、
+// それ以外は外部アカウントの名前で新しいuserを作る。名前が使われている・ポリシーに合わない場合はErrUsernameTaken・ErrInvalidUsername
+// （パスワードでログインしてから LinkIdentity で紐付ける）
+func (u *UserUsecase) LoginWithIdentity(ctx context.Context, identity ExternalIdentity) (*CreateUserResponse, error) {
+	user, err := u.linkedUser(ctx, identity)
+	if err == nil {
+		return user, nil
+	}
+	if !errors.Is(err, ErrUserNotFound) {
+		return nil, err
+	}
+
+	existing, err := u.querier.GetUserByUsername(ctx, identity.Username)
+	if err == nil {
+		if identity.LinkByUsername && !existing.PasswordHash.Valid && !existing.IsGuest {
+			// パスワードを持つユーザーや、既に別の外部アカウントと紐付いたユーザーは名前だけでは乗っ取らせない
+			count, err := u.querier.CountUserIdentities(ctx, existing.ID)
+			if err != nil {
+				return nil, fmt.Errorf("failed to count identities of user %d: %w", existing.ID, err)
+			}
+			if count == 0 {
+				if err := u.LinkIdentity(ctx, existing.ID, identity); err != nil {
+					return nil, err
+				}
+				return &CreateUserResponse{UserID: int64(existing.ID), Username: existing.Username}, nil
+			}
+		}
+		return nil, ErrUsernameTaken
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("failed to get user %s: %w", identity.Username, err)
+	}
+
+	if err := ValidateUsername(identity.Username); err != nil {
+		return nil, err
+	}
+	created, err := u.CreateUserWithoutPassword(ctx, CreateUserRequest{Username: identity.Username})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create user %s: %w", identity.Username, err)
+	}
+	if err := u.LinkIdentity(ctx, int32(created.UserID), identity); err != nil {
+		// 紐付けられなかったuserは残さない（同じ外部アカウントの同時ログインで先に紐付いた場合など）
+		if deleteErr := u.querier.DeleteUser(ctx, int32(created.UserID)); deleteErr != nil {
+			return nil, fmt.Errorf("failed to delete unlinked user %d: %w", created.UserID, deleteErr)
+		}
+		if user, linkedErr := u.linkedUser(ctx, identity); linkedErr == nil {
+			return user, nil
+		}
+		return nil, err
+	}
+	return created, nil
+}
+
+// LinkIdentity links the external account to the user.
+// 既に同じuserに紐付いている場合は何もせず、別のuserに紐付いている場合はErrIdentityLinked
+func (u *UserUsecase) LinkIdentity(ctx context.Context, userID int32, identity ExternalIdentity) error {
+	user, err := u.querier.GetUser(ctx, userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrUserNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get user %d: %w", userID, err)
+	}
+	if user.IsGuest {
+		return ErrGuestCannotLink
+	}
+
+	linked, err := u.querier.GetUserIdentity(ctx, db.GetUserIdentityParams{
+		Provider: identity.Provider,
+		Subject:  identity.Subject,
+	})
+	if err == nil {
+		if linked.UserID != userID {
+			return ErrIdentityLinked
+		}
+		return nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("failed to get identity %s/%s: %w", identity.Provider, identity.Subject, err)
+	}
+
+	err = u.querier.CreateUserIdentity(ctx, db.CreateUserIdentityParams{
+		UserID:    userID,
+		Provider:  identity.Provider,
+		Subject:   identity.Subject,
+		CreatedAt: time.Now(),
+	})
+	if err != nil {
+		// 確認から作成までの間に紐付けられた
+		if linked, getErr := u.querier.GetUserIdentity(ctx, db.GetUserIdentityParams{
+			Provider: identity.Provider,
+			Subject:  identity.Subject,
+		}); getErr == nil {
+			if linked.UserID != userID {
+				return ErrIdentityLinked
+			}
+			return nil
+		}
+		return fmt.Errorf("failed to link identity %s/%s to user %d: %w", identity.Provider, identity.Subject, userID, err)
+	}
+	return nil
+}
+
+// linkedUser は外部アカウントに紐付いたuser。紐付けがない場合はErrUserNotFound
+func (u *UserUsecase) linkedUser(ctx context.Context, identity ExternalIdentity) (*CreateUserResponse, error) {
+	linked, err := u.querier.GetUserIdentity(ctx, db.GetUserIdentityParams{
+		Provider: identity.Provider,
+		Subject:  identity.Subject,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrUserNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get identity %s/%s: %w", identity.Provider, identity.Subject, err)
+	}
+	user, err := u.querier.GetUser(ctx, linked.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user %d: %w", linked.UserID, err)
+	}
+	return &CreateUserResponse{UserID: int64(user.ID), Username: user.Username}, nil
+}
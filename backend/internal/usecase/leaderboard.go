@@ -0,0 +1,281 @@
+package usecase
+
+import (
+	"context"
+	"database/sql"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/kaitoyama/kaitoyama-server-template/internal/db"
+)
+
+// LeaderboardWindow はリーダーボードの集計期間
+type LeaderboardWindow string
+
+const (
+	LeaderboardAllTime LeaderboardWindow = "all"
+	LeaderboardMonthly LeaderboardWindow = "monthly"
+	LeaderboardWeekly  LeaderboardWindow = "weekly"
+	LeaderboardDaily   LeaderboardWindow = "daily"
+)
+
+// LeaderboardMode はユーザーごとの最高スコアのみを並べるか、全スコアを並べるか
+type LeaderboardMode string
+
+const (
+	LeaderboardBest LeaderboardMode = "best"
+	LeaderboardAll  LeaderboardMode = "all"
+)
+
+const (
+	DefaultLeaderboardLimit = 20
+	MaxLeaderboardLimit     = 100
+)
+
+var (
+	ErrInvalidLeaderboardWindow = errors.New("invalid leaderboard window")
+	ErrInvalidLeaderboardMode   = errors.New("invalid leaderboard mode")
+	ErrInvalidLeaderboardCursor = errors.New("invalid leaderboard cursor")
+)
+
+type LeaderboardUsecase struct {
+	querier db.Querier
+	now     func() time.Time
+}
+
+func NewLeaderboardUsecase(querier db.Querier) *LeaderboardUsecase {
+	return &LeaderboardUsecase{
+		querier: querier,
+		now:     time.Now,
+	}
+}
+
+type LeaderboardRequest struct {
+	Window LeaderboardWindow
+	Mode   LeaderboardMode
+	Limit  int
+	Cursor string // 前ページのNextCursor。空なら先頭から
+	UserID int    // 自分の順位を求めるユーザー
+}
+
+type LeaderboardEntry struct {
+	Rank       int
+	UserID     int
+	Username   string
+	Score      int
+	ScoreID    int       // LeaderboardAllのときのみ
+	AchievedAt time.Time // LeaderboardAllのときのみ
+}
+
+// LeaderboardRank は呼び出したユーザー自身の順位
+type LeaderboardRank struct {
+	Rank  int
+	Score int
+}
+
+type LeaderboardResponse struct {
+	Window     LeaderboardWindow
+	Mode       LeaderboardMode
+	Entries    []LeaderboardEntry
+	NextCursor string           // 次のページがなければ空
+	Me         *LeaderboardRank // 期間内にスコアがなければnil
+}
+
+// leaderboardCursor は最後に返したエントリの位置
+// 同点の順位を続きのページでも引き継ぐため、順位と通し番号も含める
+type leaderboardCursor struct {
+	Score    int `json:"s"`
+	ID       int `json:"i"` // LeaderboardAllではscore.id、LeaderboardBestではuser_id
+	Rank     int `json:"r"`
+	Position int `json:"p"` // 1始まりの通し番号
+}
+
+func encodeLeaderboardCursor(cursor leaderboardCursor) string {
+	data, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeLeaderboardCursor(s string) (leaderboardCursor, error) {
+	var cursor leaderboardCursor
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return cursor, ErrInvalidLeaderboardCursor
+	}
+	if err := json.Unmarshal(data, &cursor); err != nil {
+		return cursor, ErrInvalidLeaderboardCursor
+	}
+	if cursor.Rank < 1 || cursor.Position < cursor.Rank {
+		return cursor, ErrInvalidLeaderboardCursor
+	}
+	return cursor, nil
+}
+
+// since は集計期間の開始時刻を返す（直近24時間・7日・30日）
+func (l *LeaderboardUsecase) since(window LeaderboardWindow) (time.Time, error) {
+	now := l.now()
+	switch window {
+	case LeaderboardAllTime:
+		return time.Unix(0, 0), nil
+	case LeaderboardMonthly:
+		return now.AddDate(0, 0, -30), nil
+	case LeaderboardWeekly:
+		return now.AddDate(0, 0, -7), nil
+	case LeaderboardDaily:
+		return now.Add(-24 * time.Hour), nil
+	default:
+		return time.Time{}, ErrInvalidLeaderboardWindow
+	}
+}
+
+// GetLeaderboard returns one page of the leaderboard and the caller's own rank.
+// 同点は同順位（1, 1, 3, ...）
+func (l *LeaderboardUsecase) GetLeaderboard(ctx context.Context, req LeaderboardRequest) (*LeaderboardResponse, error) {
+	since, err := l.since(req.Window)
+	if err != nil {
+		return nil, err
+	}
+	if req.Mode != LeaderboardBest && req.Mode != LeaderboardAll {
+		return nil, ErrInvalidLeaderboardMode
+	}
+	if req.Limit <= 0 {
+		req.Limit = DefaultLeaderboardLimit
+	}
+	if req.Limit > MaxLeaderboardLimit {
+		req.Limit = MaxLeaderboardLimit
+	}
+
+	// 先頭ページはスコアの上限より上から始める
+	cursor := leaderboardCursor{Score: math.MaxInt32}
+	if req.Cursor != "" {
+		cursor, err = decodeLeaderboardCursor(req.Cursor)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// 次のページの有無を調べるため1件多く取得する
+	var entries []LeaderboardEntry
+	if req.Mode == LeaderboardBest {
+		rows, err := l.querier.ListLeaderboardBestScores(ctx, db.ListLeaderboardBestScoresParams{
+			CreatedAt: since,
+			Value:     cursor.Score,
+			Value_2:   cursor.Score,
+			UserID:    int32(cursor.ID),
+			Limit:     int32(req.Limit + 1),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list best scores: %w", err)
+		}
+		for _, row := range rows {
+			entries = append(entries, LeaderboardEntry{
+				UserID:   int(row.UserID),
+				Username: row.Username,
+				Score:    int(row.BestValue),
+			})
+		}
+	} else {
+		rows, err := l.querier.ListLeaderboardScores(ctx, db.ListLeaderboardScoresParams{
+			CreatedAt: since,
+			Value:     int32(cursor.Score),
+			Value_2:   int32(cursor.Score),
+			ID:        int32(cursor.ID),
+			Limit:     int32(req.Limit + 1),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list scores: %w", err)
+		}
+		for _, row := range rows {
+			entries = append(entries, LeaderboardEntry{
+				UserID:     int(row.UserID),
+				Username:   row.Username,
+				Score:      int(row.Value),
+				ScoreID:    int(row.ID),
+				AchievedAt: row.CreatedAt,
+			})
+		}
+	}
+
+	hasMore := len(entries) > req.Limit
+	if hasMore {
+		entries = entries[:req.Limit]
+	}
+	last := assignLeaderboardRanks(entries, cursor, req.Mode)
+
+	response := &LeaderboardResponse{
+		Window:  req.Window,
+		Mode:    req.Mode,
+		Entries: entries,
+	}
+	if response.Entries == nil {
+		response.Entries = []LeaderboardEntry{}
+	}
+	if hasMore {
+		response.NextCursor = encodeLeaderboardCursor(last)
+	}
+
+	me, err := l.userRank(ctx, req.UserID, req.Mode, since)
+	if err != nil {
+		return nil, err
+	}
+	response.Me = me
+
+	return response, nil
+}
+
+// assignLeaderboardRanks はcursorの続きとして順位を付け、最後のエントリのカーソルを返す
+func assignLeaderboardRanks(entries []LeaderboardEntry, cursor leaderboardCursor, mode LeaderboardMode) leaderboardCursor {
+	for i := range entries {
+		cursor.Position++
+		if cursor.Rank == 0 || entries[i].Score != cursor.Score {
+			cursor.Rank = cursor.Position
+		}
+		entries[i].Rank = cursor.Rank
+
+		cursor.Score = entries[i].Score
+		if mode == LeaderboardBest {
+			cursor.ID = entries[i].UserID
+		} else {
+			cursor.ID = entries[i].ScoreID
+		}
+	}
+	return cursor
+}
+
+// userRank は期間内のユーザーの最高スコアとその順位を返す
+func (l *LeaderboardUsecase) userRank(ctx context.Context, userID int, mode LeaderboardMode, since time.Time) (*LeaderboardRank, error) {
+	best, err := l.querier.GetUserBestScore(ctx, db.GetUserBestScoreParams{
+		UserID:    int32(userID),
+		CreatedAt: since,
+	})
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("failed to get best score: %w", err)
+	}
+	if !best.Valid {
+		return nil, nil
+	}
+
+	var above int64
+	if mode == LeaderboardBest {
+		above, err = l.querier.CountBestScoresAbove(ctx, db.CountBestScoresAboveParams{
+			CreatedAt: since,
+			BestValue: best.Int32,
+		})
+	} else {
+		above, err = l.querier.CountScoresAbove(ctx, db.CountScoresAboveParams{
+			CreatedAt: since,
+			Value:     best.Int32,
+		})
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to count higher scores: %w", err)
+	}
+
+	return &LeaderboardRank{
+		Rank:  int(above) + 1,
+		Score: int(best.Int32),
+	}, nil
+}
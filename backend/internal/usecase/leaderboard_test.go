@@ -0,0 +1,115 @@
+package usecase
+
+import (
+	"context"
+	"database/sql"
+	"sort"
+	"testing"
+
+	"github.com/kaitoyama/kaitoyama-server-template/internal/db"
+)
+
+// fakeLeaderboardQuerier はユーザーごとの最高スコアだけを持つdb.Querierのスタブ
+type fakeLeaderboardQuerier struct {
+	db.Querier
+	best map[int32]int32
+}
+
+func (f *fakeLeaderboardQuerier) ListLeaderboardBestScores(ctx context.Context, arg db.ListLeaderboardBestScoresParams) ([]db.ListLeaderboardBestScoresRow, error) {
+	rows := []db.ListLeaderboardBestScoresRow{}
+	for userID, value := range f.best {
+		after := arg.Value.(int)
+		if int(value) < after || (int(value) == after && userID > arg.UserID) {
+			rows = append(rows, db.ListLeaderboardBestScoresRow{UserID: userID, BestValue: value})
+		}
+	}
+	sort.Slice(rows, func(i, j int) bool {
+		if rows[i].BestValue != rows[j].BestValue {
+			return rows[i].BestValue > rows[j].BestValue
+		}
+		return rows[i].UserID < rows[j].UserID
+	})
+	if len(rows) > int(arg.Limit) {
+		rows = rows[:arg.Limit]
+	}
+	return rows, nil
+}
+
+func (f *fakeLeaderboardQuerier) GetUserBestScore(ctx context.Context, arg db.GetUserBestScoreParams) (sql.NullInt32, error) {
+	value, ok := f.best[arg.UserID]
+	return sql.NullInt32{Int32: value, Valid: ok}, nil
+}
+
+func (f *fakeLeaderboardQuerier) CountBestScoresAbove(ctx context.Context, arg db.CountBestScoresAboveParams) (int64, error) {
+	var count int64
+	for _, value := range f.best {
+		if value > arg.BestValue.(int32) {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func TestLeaderboardUsecase_PagesKeepTiedRanks(t *testing.T) {
+	querier := &fakeLeaderboardQuerier{best: map[int32]int32{
+		1: 100, 2: 90, 3: 90, 4: 90, 5: 50, 6: 10,
+	}}
+	leaderboard := NewLeaderboardUsecase(querier)
+
+	type entry struct{ userID, rank int }
+	var got []entry
+	cursor := ""
+	pages := 0
+	for {
+		res, err := leaderboard.GetLeaderboard(context.Background(), LeaderboardRequest{
+			Window: LeaderboardWeekly,
+			Mode:   LeaderboardBest,
+			Limit:  2,
+			Cursor: cursor,
+			UserID: 6,
+		})
+		if err != nil {
+			t.Fatalf("GetLeaderboard() error = %v", err)
+		}
+		pages++
+		for _, e := range res.Entries {
+			got = append(got, entry{e.UserID, e.Rank})
+		}
+		if pages == 1 {
+			if res.Me == nil || res.Me.Rank != 6 || res.Me.Score != 10 {
+				t.Errorf("Me = %+v, want rank 6 score 10", res.Me)
+			}
+		}
+		if res.NextCursor == "" {
+			break
+		}
+		cursor = res.NextCursor
+	}
+
+	want := []entry{{1, 1}, {2, 2}, {3, 2}, {4, 2}, {5, 5}, {6, 6}}
+	if pages != 3 {
+		t.Errorf("got %d pages, want 3", pages)
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %d entries, want %d", len(got), len(want))
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("entry %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestLeaderboardUsecase_RejectsInvalidInput(t *testing.T) {
+	leaderboard := NewLeaderboardUsecase(&fakeLeaderboardQuerier{})
+
+	for _, req := range []LeaderboardRequest{
+		{Window: "yearly", Mode: LeaderboardBest},
+		{Window: LeaderboardDaily, Mode: "worst"},
+		{Window: LeaderboardDaily, Mode: LeaderboardBest, Cursor: "not-a-cursor"},
+	} {
+		if _, err := leaderboard.GetLeaderboard(context.Background(), req); err == nil {
+			t.Errorf("GetLeaderboard(%+v) error = nil, want error", req)
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/kaitoyama/kaitoyama-server-template/internal/db"
+)
+
+const (
+	DefaultRatingLimit = 50
+	MaxRatingLimit     = 100
+)
+
+type RatingUsecase struct {
+	querier db.Querier
+}
+
+func NewRatingUsecase(querier db.Querier) *RatingUsecase {
+	return &RatingUsecase{
+		querier: querier,
+	}
+}
+
+type RatingEntry struct {
+	Rank        int
+	UserID      int
+	Username    string
+	Rating      float64
+	Deviation   float64
+	GamesPlayed int
+}
+
+// ListRatings returns users ordered by rating. offsetは先頭から読み飛ばす件数
+func (r *RatingUsecase) ListRatings(ctx context.Context, limit, offset int) ([]RatingEntry, error) {
+	if limit <= 0 {
+		limit = DefaultRatingLimit
+	}
+	if limit > MaxRatingLimit {
+		limit = MaxRatingLimit
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	rows, err := r.querier.ListRatings(ctx, db.ListRatingsParams{
+		Limit:  int32(limit),
+		Offset: int32(offset),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list ratings: %w", err)
+	}
+
+	entries := make([]RatingEntry, 0, len(rows))
+	for i, row := range rows {
+		entries = append(entries, RatingEntry{
+			Rank:        offset + i + 1,
+			UserID:      int(row.UserID),
+			Username:    row.Username,
+			Rating:      row.Rating,
+			Deviation:   row.Deviation,
+			GamesPlayed: int(row.GamesPlayed),
+		})
+	}
+	return entries, nil
+}
@@ -1,6 +1,7 @@
 package usecase
 
 import (
+	"context"
 	"fmt"
 	"sync"
 	"time"
@@ -10,19 +11,44 @@ import (
 	"github.com/rs/zerolog/log"
 )
 
+// RoomChangeListener はroomの概要（人数・READY状態・開閉・ゲーム状態）の変化通知を受け取る
+// roomのロックを保持したまま呼ばれるため、実装はブロックせずに戻ること
+type RoomChangeListener interface {
+	RoomChanged(roomID int)
+}
+
+// GamePauseListener はゲームの一時停止・再開の通知を受け取る（ホストの操作・全員切断による自動一時停止の両方）
+// roomのロックを保持したまま呼ばれるため、実装はブロックせずに戻ること
+type GamePauseListener interface {
+	GamePaused(roomID int, remaining time.Duration, autoPaused bool)
+	GameResumed(roomID int, remaining time.Duration)
+}
+
 type RoomUsecase struct {
-	rooms      map[int]*domain.Room
-	mutex      sync.RWMutex
-	gameTimers map[int]bool // ゲームタイマー重複実行防止用
-	timerMutex sync.Mutex   // gameTimers用の専用mutex
+	rooms          domain.RoomRepository
+	mutex          sync.RWMutex       // changeListener・submissions・roomStore用
+	gameTimers     map[int]bool       // ゲームタイマー重複実行防止用
+	timerMutex     sync.Mutex         // gameTimers用の専用mutex
+	changeListener RoomChangeListener // ロビー通知用
+	pauseListener  GamePauseListener  // 一時停止・再開の通知用
+	gameResults    domain.GameResultRepository
+	submissions    domain.SubmissionLogger // 数式の提出記録（nilの場合は記録しない）
+	roomStore      domain.RoomStore        // roomの状態の永続化（nilの場合は保存しない）
 }
 
-func NewRoomUsecase() *RoomUsecase {
+// NewRoomUsecase creates a room usecase that keeps the rooms in memory. gameResultsがnilの場合はゲーム結果を保存しない
+func NewRoomUsecase(gameResults domain.GameResultRepository) *RoomUsecase {
+	return NewRoomUsecaseWithRepository(domain.NewMemoryRoomRepository(), gameResults)
+}
+
+// NewRoomUsecaseWithRepository creates a room usecase backed by the given repository
+func NewRoomUsecaseWithRepository(rooms domain.RoomRepository, gameResults domain.GameResultRepository) *RoomUsecase {
 	usecase := &RoomUsecase{
-		rooms:      make(map[int]*domain.Room),
-		mutex:      sync.RWMutex{},
-		gameTimers: make(map[int]bool),
-		timerMutex: sync.Mutex{},
+		rooms:       rooms,
+		mutex:       sync.RWMutex{},
+		gameTimers:  make(map[int]bool),
+		timerMutex:  sync.Mutex{},
+		gameResults: gameResults,
 	}
 
 	// 10個のroomを初期化
@@ -31,492 +57,685 @@ func NewRoomUsecase() *RoomUsecase {
 	return usecase
 }
 
-// 10個のroomを初期化する
-func (r *RoomUsecase) initializeRooms() {
-	for i := 1; i <= 10; i++ {
-		room := domain.NewRoom(i, fmt.Sprintf("Room %d", i))
-		r.rooms[i] = room
-	}
+// SetRoomChangeListener sets the listener notified when a room summary changes
+func (r *RoomUsecase) SetRoomChangeListener(listener RoomChangeListener) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.changeListener = listener
 }
 
-// すべてのroomを取得
-func (r *RoomUsecase) GetRooms() []models.Room {
+// SetGamePauseListener sets the listener notified when a game is paused or resumed
+func (r *RoomUsecase) SetGamePauseListener(listener GamePauseListener) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.pauseListener = listener
+}
+
+// SetSubmissionLogger sets the logger that records every formula submission
+func (r *RoomUsecase) SetSubmissionLogger(logger domain.SubmissionLogger) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.submissions = logger
+}
+
+// SetRoomStore sets the store that persists every room change
+func (r *RoomUsecase) SetRoomStore(store domain.RoomStore) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.roomStore = store
+}
+
+// RestoreRooms replaces the rooms with the ones saved before the last shutdown.
+// 進行中だったゲームは自動一時停止として復元し、復元できないゲームは中断する
+func (r *RoomUsecase) RestoreRooms(ctx context.Context) error {
 	r.mutex.RLock()
-	defer r.mutex.RUnlock()
+	store := r.roomStore
+	r.mutex.RUnlock()
 
-	var rooms []models.Room
-	for _, domainRoom := range r.rooms {
-		// domain.RoomからAPI用のmodels.Roomに変換
-		users := make([]models.User, len(domainRoom.Players))
-		for i, player := range domainRoom.Players {
-			users[i] = models.User{
-				Username: player.UserName,
-				IsReady:  player.IsReady,
-			}
-		}
+	if store == nil {
+		return nil
+	}
+	records, err := store.LoadRooms(ctx)
+	if err != nil {
+		return err
+	}
 
-		apiRoom := models.Room{
-			RoomId:   domainRoom.ID,
-			RoomName: domainRoom.Name,
-			Users:    users,
-			IsOpened: domainRoom.IsOpened,
+	now := time.Now()
+	for _, record := range records {
+		room, abortReason := domain.RestoreRoom(record, now)
+		if abortReason != nil {
+			log.Warn().Err(abortReason).Int("room_id", record.ID).Msg("Aborted game while restoring room")
+		}
+		// 中断・一時停止などの復元後の状態を保存し直す（リポジトリに入れる前なのでロックは不要）
+		r.saveRoom(room)
+		if err := r.rooms.SaveRoom(room); err != nil {
+			return fmt.Errorf("failed to save restored room %d: %w", room.ID, err)
 		}
-		rooms = append(rooms, apiRoom)
+
+		log.Info().
+			Int("room_id", room.ID).
+			Str("state", room.State.String()).
+			Int("player_count", len(room.Players)).
+			Msg("Room restored")
 	}
+	return nil
+}
 
-	return rooms
+// GameRoomIDs returns the rooms whose game is in progress or paused
+func (r *RoomUsecase) GameRoomIDs() []int {
+	var roomIDs []int
+	for _, room := range r.rooms.ListRooms() {
+		r.rooms.ViewRoom(room.ID, func(room *domain.Room) error {
+			if room.State == domain.StateGameInProgress || room.State == domain.StatePaused {
+				roomIDs = append(roomIDs, room.ID)
+			}
+			return nil
+		})
+	}
+	return roomIDs
 }
 
-// roomIDでroomを取得
-func (r *RoomUsecase) GetRoomByID(roomID int) (*domain.Room, error) {
+// saveRoom はroomの状態を保存する（roomのロックを保持した状態で呼ぶこと）
+func (r *RoomUsecase) saveRoom(room *domain.Room) {
 	r.mutex.RLock()
 	defer r.mutex.RUnlock()
-
-	room, exists := r.rooms[roomID]
-	if !exists {
-		return nil, fmt.Errorf("room with ID %d not found", roomID)
+	if r.roomStore != nil {
+		r.roomStore.SaveRoom(room.Record(time.Now()))
 	}
-
-	return room, nil
 }
 
-func (r *RoomUsecase) AddPlayerToRoom(roomID int, player domain.Player) (*domain.Room, error) {
-	r.mutex.Lock()
-	defer r.mutex.Unlock()
+// roomChanged はroomの状態を保存し、変化をリスナーへ伝える（roomのロックを保持した状態で呼ぶこと）
+func (r *RoomUsecase) roomChanged(room *domain.Room) {
+	r.saveRoom(room)
 
-	room, exists := r.rooms[roomID]
-	if !exists {
-		return nil, fmt.Errorf("room with ID %d not found", roomID)
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	if r.changeListener != nil {
+		r.changeListener.RoomChanged(room.ID)
 	}
+}
 
-	// プレイヤーがすでに存在するかチェック
-	for _, p := range room.Players {
-		if p.ID == player.ID {
-			return nil, fmt.Errorf("player with ID %d already exists in room %d", player.ID, roomID)
-		}
+// gamePaused は一時停止をリスナーへ伝える（roomのロックを保持した状態で呼ぶこと）
+func (r *RoomUsecase) gamePaused(room *domain.Room) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	if r.pauseListener != nil {
+		r.pauseListener.GamePaused(room.ID, room.PausedRemaining, room.AutoPaused)
 	}
-
-	// 新しいプレイヤーの接続状態を初期化
-	player.IsConnected = true
-	player.LastSeenAt = nil
-
-	room.Players = append(room.Players, player)
-	return room, nil
 }
 
-func (r *RoomUsecase) UpdatePlayerReadyStatus(roomID int, playerID int, isReady bool) (*domain.Room, error) {
-	r.mutex.Lock()
-	defer r.mutex.Unlock()
-
-	room, exists := r.rooms[roomID]
-	if !exists {
-		return nil, fmt.Errorf("room with ID %d not found", roomID)
+// gameResumed は再開をリスナーへ伝える（roomのロックを保持した状態で呼ぶこと）
+func (r *RoomUsecase) gameResumed(room *domain.Room, now time.Time) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	if r.pauseListener != nil {
+		r.pauseListener.GameResumed(room.ID, room.RemainingTime(now))
 	}
+}
 
-	// プレイヤーを見つけて更新
-	playerFound := false
-	for i, player := range room.Players {
-		if player.ID == playerID {
-			room.Players[i].IsReady = isReady
-			playerFound = true
-			break
+// 10個のroomを初期化する（リポジトリに既にあるroomはそのまま）
+func (r *RoomUsecase) initializeRooms() {
+	for i := 1; i <= 10; i++ {
+		if _, err := r.rooms.GetRoom(i); err == nil {
+			continue
+		}
+		room := domain.NewRoom(i, fmt.Sprintf("Room %d", i))
+		if err := r.rooms.SaveRoom(room); err != nil {
+			log.Error().Err(err).Int("room_id", i).Msg("Failed to initialize room")
 		}
 	}
+}
 
-	if !playerFound {
-		return nil, fmt.Errorf("player with ID %d not found in room %d", playerID, roomID)
-	}
+// すべてのroomを取得
+func (r *RoomUsecase) GetRooms() []models.Room {
+	var rooms []models.Room
+	for _, room := range r.rooms.ListRooms() {
+		r.rooms.ViewRoom(room.ID, func(domainRoom *domain.Room) error {
+			// domain.RoomからAPI用のmodels.Roomに変換
+			users := make([]models.User, len(domainRoom.Players))
+			for i, player := range domainRoom.Players {
+				users[i] = models.User{
+					Username: player.UserName,
+					IsReady:  player.IsReady,
+				}
+			}
 
-	// 全員がREADYになったら状態を更新
-	if room.AreAllPlayersReady() && room.State == domain.StateWaitingForPlayers {
-		room.TransitionTo(domain.StateAllReady)
-		// 全員がreadyになったらroomをクローズ
-		room.IsOpened = false
-	} else if !room.AreAllPlayersReady() && room.State == domain.StateAllReady {
-		room.TransitionTo(domain.StateWaitingForPlayers)
-		// 状態がWaitingForPlayersに戻った場合、部屋を再度開放
-		room.IsOpened = true
+			apiRoom := models.Room{
+				RoomId:   domainRoom.ID,
+				RoomName: domainRoom.Name,
+				Users:    users,
+				IsOpened: domainRoom.IsOpened,
+			}
+			rooms = append(rooms, apiRoom)
+			return nil
+		})
 	}
 
-	return room, nil
+	return rooms
 }
 
-// StartGame starts the game for the specified room
-func (r *RoomUsecase) StartGame(roomID int) (*domain.Room, error) {
-	r.mutex.Lock()
-	defer r.mutex.Unlock()
+// roomIDでroomを取得
+func (r *RoomUsecase) GetRoomByID(roomID int) (*domain.Room, error) {
+	return r.rooms.GetRoom(roomID)
+}
 
-	room, exists := r.rooms[roomID]
-	if !exists {
-		return nil, fmt.Errorf("room with ID %d not found", roomID)
-	}
+func (r *RoomUsecase) AddPlayerToRoom(roomID int, player domain.Player) (*domain.Room, error) {
+	return r.rooms.UpdateRoom(roomID, func(room *domain.Room) error {
+		// プレイヤーがすでに存在するかチェック
+		for _, p := range room.Players {
+			if p.ID == player.ID {
+				return fmt.Errorf("player with ID %d already exists in room %d", player.ID, roomID)
+			}
+		}
 
-	err := room.StartGame()
-	if err != nil {
-		return nil, fmt.Errorf("failed to start game: %w", err)
-	}
+		// 新しいプレイヤーの接続状態を初期化
+		player.IsConnected = true
+		player.LastSeenAt = nil
 
-	return room, nil
+		room.Players = append(room.Players, player)
+		r.roomChanged(room)
+		return nil
+	})
 }
 
-// CloseResult closes the result display for a player in the specified room
-func (r *RoomUsecase) CloseResult(roomID int, playerID int) (*domain.Room, error) {
-	r.mutex.Lock()
-	defer r.mutex.Unlock()
+func (r *RoomUsecase) UpdatePlayerReadyStatus(roomID int, playerID int, isReady bool) (*domain.Room, error) {
+	return r.rooms.UpdateRoom(roomID, func(room *domain.Room) error {
+		// プレイヤーを見つけて更新
+		playerFound := false
+		for i, player := range room.Players {
+			if player.ID == playerID {
+				room.Players[i].IsReady = isReady
+				playerFound = true
+				break
+			}
+		}
 
-	room, exists := r.rooms[roomID]
-	if !exists {
-		return nil, fmt.Errorf("room with ID %d not found", roomID)
-	}
+		if !playerFound {
+			return fmt.Errorf("player with ID %d not found in room %d", playerID, roomID)
+		}
 
-	err := room.CloseResult(playerID)
-	if err != nil {
-		return nil, fmt.Errorf("failed to close result: %w", err)
-	}
+		// 全員がREADYになったら状態を更新
+		if room.AreAllPlayersReady() && room.State == domain.StateWaitingForPlayers {
+			room.TransitionTo(domain.StateAllReady)
+			// 全員がreadyになったらroomをクローズ
+			room.IsOpened = false
+		} else if !room.AreAllPlayersReady() && room.State == domain.StateAllReady {
+			room.TransitionTo(domain.StateWaitingForPlayers)
+			// 状態がWaitingForPlayersに戻った場合、部屋を再度開放
+			room.IsOpened = true
+		}
 
-	return room, nil
+		r.roomChanged(room)
+		return nil
+	})
+}
+
+// StartGame starts the game for the specified room
+func (r *RoomUsecase) StartGame(roomID int) (*domain.Room, error) {
+	return r.rooms.UpdateRoom(roomID, func(room *domain.Room) error {
+		if err := room.StartGame(); err != nil {
+			return fmt.Errorf("failed to start game: %w", err)
+		}
+		r.roomChanged(room)
+		return nil
+	})
+}
+
+// CloseResult closes the result display for a player in the specified room
+func (r *RoomUsecase) CloseResult(roomID int, playerID int) (*domain.Room, error) {
+	return r.rooms.UpdateRoom(roomID, func(room *domain.Room) error {
+		if err := room.CloseResult(playerID); err != nil {
+			return fmt.Errorf("failed to close result: %w", err)
+		}
+		r.roomChanged(room)
+		return nil
+	})
 }
 
 // CompleteCountdown completes the countdown and transitions to game in progress
 func (r *RoomUsecase) CompleteCountdown(roomID int) (*domain.Room, error) {
-	r.mutex.Lock()
-	defer r.mutex.Unlock()
+	return r.rooms.UpdateRoom(roomID, func(room *domain.Room) error {
+		if err := room.CompleteCountdown(); err != nil {
+			return fmt.Errorf("failed to complete countdown: %w", err)
+		}
+		r.roomChanged(room)
+		return nil
+	})
+}
 
-	room, exists := r.rooms[roomID]
-	if !exists {
-		return nil, fmt.Errorf("room with ID %d not found", roomID)
-	}
+// PauseGame pauses the game in progress for the specified room
+func (r *RoomUsecase) PauseGame(roomID int) (*domain.Room, error) {
+	return r.rooms.UpdateRoom(roomID, func(room *domain.Room) error {
+		if err := room.PauseGame(time.Now(), false); err != nil {
+			return fmt.Errorf("failed to pause game: %w", err)
+		}
+		r.roomChanged(room)
+		r.gamePaused(room)
+		return nil
+	})
+}
+
+// ResumeGame resumes the paused game for the specified room
+func (r *RoomUsecase) ResumeGame(roomID int) (*domain.Room, error) {
+	return r.rooms.UpdateRoom(roomID, func(room *domain.Room) error {
+		now := time.Now()
+		if err := room.ResumeGame(now); err != nil {
+			return fmt.Errorf("failed to resume game: %w", err)
+		}
+		r.roomChanged(room)
+		r.gameResumed(room, now)
+		return nil
+	})
+}
+
+// GetGameClock returns the current state and remaining game time of the specified room
+func (r *RoomUsecase) GetGameClock(roomID int) (domain.GameClock, error) {
+	var clock domain.GameClock
+	err := r.rooms.ViewRoom(roomID, func(room *domain.Room) error {
+		clock = room.Clock(time.Now())
+		return nil
+	})
+	return clock, err
+}
 
-	err := room.CompleteCountdown()
+// GetRoomSnapshot returns a copy of the room state for reconnecting clients
+func (r *RoomUsecase) GetRoomSnapshot(roomID int) (*domain.RoomSnapshot, error) {
+	var snapshot domain.RoomSnapshot
+	err := r.rooms.ViewRoom(roomID, func(room *domain.Room) error {
+		snapshot = room.Snapshot(time.Now())
+		return nil
+	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to complete countdown: %w", err)
+		return nil, err
 	}
-
-	return room, nil
+	return &snapshot, nil
 }
 
 // UpdateGameBoard updates the game board for the specified room
 func (r *RoomUsecase) UpdateGameBoard(roomID int, newBoard domain.GameBoard) (*domain.Room, error) {
-	r.mutex.Lock()
-	defer r.mutex.Unlock()
-
-	room, exists := r.rooms[roomID]
-	if !exists {
-		return nil, fmt.Errorf("room with ID %d not found", roomID)
-	}
-
-	room.GameBoards = append(room.GameBoards, newBoard)
-	return room, nil
+	return r.rooms.UpdateRoom(roomID, func(room *domain.Room) error {
+		room.AddGameBoard(newBoard)
+		r.saveRoom(room)
+		return nil
+	})
 }
 
 // RemovePlayerFromRoom removes a player from the specified room
 func (r *RoomUsecase) RemovePlayerFromRoom(roomID int, playerID int) (*domain.Room, error) {
-	r.mutex.Lock()
-	defer r.mutex.Unlock()
-
-	room, exists := r.rooms[roomID]
-	if !exists {
-		return nil, fmt.Errorf("room with ID %d not found", roomID)
-	}
-
-	// プレイヤーを見つけて削除（安全性向上）
-	playerFound := false
-	for i := 0; i < len(room.Players); i++ {
-		if room.Players[i].ID == playerID {
-			// 境界チェック付きでスライスから削除
-			if i < len(room.Players)-1 {
-				room.Players = append(room.Players[:i], room.Players[i+1:]...)
-			} else {
-				room.Players = room.Players[:i]
+	return r.rooms.UpdateRoom(roomID, func(room *domain.Room) error {
+		// プレイヤーを見つけて削除（安全性向上）
+		playerFound := false
+		for i := 0; i < len(room.Players); i++ {
+			if room.Players[i].ID == playerID {
+				// ゲーム中の退出は最下位として結果に残す
+				room.RecordLeaver(room.Players[i])
+				// 境界チェック付きでスライスから削除
+				if i < len(room.Players)-1 {
+					room.Players = append(room.Players[:i], room.Players[i+1:]...)
+				} else {
+					room.Players = room.Players[:i]
+				}
+				playerFound = true
+				break
 			}
-			playerFound = true
-			break
 		}
-	}
 
-	if !playerFound {
-		return nil, fmt.Errorf("player with ID %d not found in room %d", playerID, roomID)
-	}
+		if !playerFound {
+			return fmt.Errorf("player with ID %d not found in room %d", playerID, roomID)
+		}
 
-	// 参加者が0人になった場合、ルームをリセット
-	if len(room.Players) == 0 {
-		// 状態に関わらず、ルームを完全に初期状態に戻す
-		room.State = domain.StateWaitingForPlayers
-		room.GameBoards = []domain.GameBoard{domain.NewBoard()}
-		room.ResultLog = []domain.Result{}
-		room.IsOpened = true
-		room.LastCorrectPlayerID = 0
-		room.StreakCount = 0
-		// プレイヤーリストは既に空なので、個々のリセットは不要
-	} else {
-		// まだプレイヤーがいる場合、READY状態をチェック
-		if !room.AreAllPlayersReady() && room.State == domain.StateAllReady {
-			room.TransitionTo(domain.StateWaitingForPlayers)
-			// 状態がWaitingForPlayersに戻った場合、部屋を再度開放
+		// 参加者が0人になった場合、ルームをリセット
+		if len(room.Players) == 0 {
+			// 状態に関わらず、ルームを完全に初期状態に戻す
+			room.State = domain.StateWaitingForPlayers
+			room.GameBoards = []domain.GameBoard{domain.NewBoard()}
+			room.ResultLog = []domain.Result{}
 			room.IsOpened = true
+			room.LastCorrectPlayerID = 0
+			room.StreakCount = 0
+			room.Leavers = nil
+			// プレイヤーリストは既に空なので、個々のリセットは不要
+		} else {
+			// まだプレイヤーがいる場合、READY状態をチェック
+			if !room.AreAllPlayersReady() && room.State == domain.StateAllReady {
+				room.TransitionTo(domain.StateWaitingForPlayers)
+				// 状態がWaitingForPlayersに戻った場合、部屋を再度開放
+				room.IsOpened = true
+			}
 		}
-	}
 
-	return room, nil
+		r.roomChanged(room)
+		return nil
+	})
 }
 
-// EndGame ends the game for the specified room
+// EndGame ends the game for the specified room and persists the result
 func (r *RoomUsecase) EndGame(roomID int) (*domain.Room, error) {
-	r.mutex.Lock()
-	defer r.mutex.Unlock()
+	var result domain.GameResult
+	room, err := r.rooms.UpdateRoom(roomID, func(room *domain.Room) error {
+		if err := room.EndGame(); err != nil {
+			return fmt.Errorf("failed to end game: %w", err)
+		}
 
-	room, exists := r.rooms[roomID]
-	if !exists {
-		return nil, fmt.Errorf("room with ID %d not found", roomID)
-	}
+		// ゲーム終了時にタイマーを確実に停止
+		r.StopGameTimer(roomID)
+
+		// ResetRoomでスコアが失われる前に結果を確定
+		result = room.Result(time.Now())
 
-	err := room.EndGame()
+		r.roomChanged(room)
+		return nil
+	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to end game: %w", err)
+		return nil, err
 	}
 
-	// ゲーム終了時にタイマーを確実に停止
-	r.StopGameTimer(roomID)
+	// DBへの書き込みは他のroomの操作を止めないようロックの外で行う
+	r.saveGameResult(result)
 
 	return room, nil
 }
 
-// ApplyFormulaWithVersion はバージョンを考慮した細かい衝突検出付きの数式適用
-func (r *RoomUsecase) ApplyFormulaWithVersion(roomID int, playerID int, formula string, submittedVersion int) (*domain.GameBoard, int, error) {
-	r.mutex.Lock()
-	defer r.mutex.Unlock()
-
-	//ルームが存在するかをチェック
-	room, exists := r.rooms[roomID]
-	if !exists {
-		return nil, 0, fmt.Errorf("room with ID %d not found", roomID)
+// saveGameResult はゲーム結果を保存する。失敗してもゲームの進行は止めずにログに残す
+func (r *RoomUsecase) saveGameResult(result domain.GameResult) {
+	if r.gameResults == nil || len(result.Players) == 0 {
+		return
 	}
 
-	// プレイヤーが参加しているかをチェック
-	playerInRoom := false
-	for _, p := range room.Players {
-		if p.ID == playerID {
-			playerInRoom = true
-			break
-		}
-	}
-	if !playerInRoom {
-		return nil, 0, fmt.Errorf("player is not in this room")
-	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
 
-	// ゲーム進行中かをチェック
-	if room.State != domain.StateGameInProgress {
-		return nil, 0, fmt.Errorf("game is not in progress")
-	}
+	gameID, err := r.gameResults.SaveGameResult(ctx, result)
+	if err != nil {
+		log.Error().
+			Err(err).
+			Int("room_id", result.RoomID).
+			Int("player_count", len(result.Players)).
+			Msg("Failed to save game result")
+		return
+	}
+
+	log.Info().
+		Int64("game_id", gameID).
+		Int("room_id", result.RoomID).
+		Int("player_count", len(result.Players)).
+		Msg("Game result saved")
+}
 
-	if len(room.GameBoards) == 0 {
-		return nil, 0, fmt.Errorf("no game board available")
-	}
-	currentBoard := &room.GameBoards[len(room.GameBoards)-1]
+// ApplyFormulaWithVersion はバージョンを考慮した細かい衝突検出付きの数式適用
+func (r *RoomUsecase) ApplyFormulaWithVersion(roomID int, playerID int, formula string, submittedVersion int) (*domain.GameBoard, int, error) {
+	receivedAt := time.Now()
+	r.mutex.RLock()
+	submissions := r.submissions
+	r.mutex.RUnlock()
 
-	// バージョン付きの細かい衝突検出を実行
-	success, errMessage, matchCount := domain.AttemptMoveWithVersion(currentBoard, formula, submittedVersion)
+	var safeBoard *domain.GameBoard
+	var gainScore int
+	var submission *domain.FormulaSubmission
+	_, err := r.rooms.UpdateRoom(roomID, func(room *domain.Room) error {
+		// プレイヤーが参加しているかをチェック
+		playerInRoom := false
+		for _, p := range room.Players {
+			if p.ID == playerID {
+				playerInRoom = true
+				break
+			}
+		}
+		if !playerInRoom {
+			return fmt.Errorf("player is not in this room")
+		}
 
-	if !success {
-		return nil, 0, fmt.Errorf("%s", errMessage)
-	}
+		// ゲーム進行中かをチェック（一時停止中は受け付けない）
+		if room.State == domain.StatePaused {
+			return fmt.Errorf("game is paused")
+		}
+		if room.State != domain.StateGameInProgress {
+			return fmt.Errorf("game is not in progress")
+		}
 
-	// 連続正解数とスコア計算を原子的に実行
-	var gainScore int
-	playerFound := false
-
-	// プレイヤーを見つけてスコア更新を1回だけ実行
-	for i := range room.Players {
-		if room.Players[i].ID == playerID {
-			// 連続正解数をカウント
-			if room.LastCorrectPlayerID == playerID {
-				room.StreakCount++
-			} else {
-				room.StreakCount = 1
-				room.LastCorrectPlayerID = playerID
+		if len(room.GameBoards) == 0 {
+			return fmt.Errorf("no game board available")
+		}
+		currentBoard := &room.GameBoards[len(room.GameBoards)-1]
+
+		// バージョン付きの細かい衝突検出を実行
+		boardVersion := currentBoard.Version
+		move := domain.AttemptMove(currentBoard, formula, submittedVersion)
+		matchCount := len(move.Matches)
+		submission = &domain.FormulaSubmission{
+			GameUUID:         room.GameUUID,
+			RoomID:           roomID,
+			UserID:           playerID,
+			SubmittedVersion: submittedVersion,
+			BoardVersion:     boardVersion,
+			Expression:       formula,
+			Outcome:          move.Outcome,
+			Matches:          move.Matches,
+			SubmittedAt:      receivedAt,
+		}
+
+		if move.Outcome != domain.MoveSuccess {
+			submission.Latency = time.Since(receivedAt)
+			// 正答率の集計のため誤った数式を数える。他のプレイヤーとの衝突は誤答ではないので数えない
+			if move.Outcome.IsRejection() {
+				for i := range room.Players {
+					if room.Players[i].ID == playerID {
+						room.Players[i].RejectedFormulas++
+						break
+					}
+				}
 			}
+			return fmt.Errorf("%s", move.Message)
+		}
 
-			// スコア計算: 消した組数 * (5+5*"連続正解数")点
-			gainScore = matchCount * (5 + 5*room.StreakCount)
+		// 連続正解数とスコア計算を原子的に実行（プレイヤーのスコアに1回だけ加算）
+		var playerFound bool
+		gainScore, playerFound = room.AwardCorrectAnswer(playerID, matchCount)
+		if !playerFound {
+			return fmt.Errorf("player with ID %d not found in room", playerID)
+		}
 
-			// プレイヤーのスコアに加算（1回のみ保証）
-			room.Players[i].Score += gainScore
-			playerFound = true
-			break
+		// リプレイ用に受理された数式と盤面の変更を記録
+		room.Moves = append(room.Moves, domain.GameMove{
+			UserID:           playerID,
+			Expression:       formula,
+			SubmittedVersion: submittedVersion,
+			Version:          currentBoard.Version,
+			Changes:          move.Matches,
+			GainedScore:      gainScore,
+			SubmittedAt:      receivedAt,
+		})
+		submission.GainedScore = gainScore
+		submission.Latency = time.Since(receivedAt)
+		r.saveRoom(room)
+
+		// データレース回避のためGameBoardのディープコピーを返す
+		safeBoard = &domain.GameBoard{
+			Version:       currentBoard.Version,
+			Size:          currentBoard.Size,
+			Board:         make([][]int, currentBoard.Size),
+			ChangeHistory: make(map[int][]domain.Matches),
 		}
-	}
 
-	if !playerFound {
-		return nil, 0, fmt.Errorf("player with ID %d not found in room", playerID)
-	}
+		// 盤面データをコピー
+		for i := 0; i < currentBoard.Size; i++ {
+			safeBoard.Board[i] = make([]int, currentBoard.Size)
+			copy(safeBoard.Board[i], currentBoard.Board[i])
+		}
 
-	// データレース回避のためGameBoardのディープコピーを返す
-	safeBoard := &domain.GameBoard{
-		Version:       currentBoard.Version,
-		Size:          currentBoard.Size,
-		Board:         make([][]int, currentBoard.Size),
-		ChangeHistory: make(map[int][]domain.Matches),
-	}
+		// 変更履歴もコピー（必要に応じて）
+		for k, v := range currentBoard.ChangeHistory {
+			safeBoard.ChangeHistory[k] = v
+		}
+		return nil
+	})
 
-	// 盤面データをコピー
-	for i := 0; i < currentBoard.Size; i++ {
-		safeBoard.Board[i] = make([]int, currentBoard.Size)
-		copy(safeBoard.Board[i], currentBoard.Board[i])
+	// 提出記録はroomのロックを解放してから渡す
+	if submission != nil && submissions != nil {
+		submissions.LogSubmission(*submission)
 	}
-
-	// 変更履歴もコピー（必要に応じて）
-	for k, v := range currentBoard.ChangeHistory {
-		safeBoard.ChangeHistory[k] = v
+	if err != nil {
+		return nil, 0, err
 	}
-
 	return safeBoard, gainScore, nil
 }
 
 // SetPlayerDisconnected marks a player as disconnected but keeps them in the room
 func (r *RoomUsecase) SetPlayerDisconnected(roomID int, playerID int) (*domain.Room, error) {
-	r.mutex.Lock()
-	defer r.mutex.Unlock()
-
-	room, exists := r.rooms[roomID]
-	if !exists {
-		return nil, fmt.Errorf("room with ID %d not found", roomID)
-	}
+	return r.rooms.UpdateRoom(roomID, func(room *domain.Room) error {
+		// プレイヤーを見つけて切断状態に設定
+		playerFound := false
+		now := time.Now()
+		for i, player := range room.Players {
+			if player.ID == playerID {
+				room.Players[i].IsConnected = false
+				room.Players[i].LastSeenAt = &now
+				playerFound = true
+
+				log.Info().
+					Int("room_id", roomID).
+					Int("player_id", playerID).
+					Str("player_name", player.UserName).
+					Msg("Player marked as disconnected in room")
+				break
+			}
+		}
 
-	// プレイヤーを見つけて切断状態に設定
-	playerFound := false
-	now := time.Now()
-	for i, player := range room.Players {
-		if player.ID == playerID {
-			room.Players[i].IsConnected = false
-			room.Players[i].LastSeenAt = &now
-			playerFound = true
-
-			log.Info().
-				Int("room_id", roomID).
-				Int("player_id", playerID).
-				Str("player_name", player.UserName).
-				Msg("Player marked as disconnected in room")
-			break
+		if !playerFound {
+			return fmt.Errorf("player with ID %d not found in room %d", playerID, roomID)
 		}
-	}
 
-	if !playerFound {
-		return nil, fmt.Errorf("player with ID %d not found in room %d", playerID, roomID)
-	}
+		// 全員が切断した場合はゲームを自動で一時停止
+		if room.State == domain.StateGameInProgress && !room.HasConnectedPlayers() {
+			if err := room.PauseGame(now, true); err == nil {
+				log.Info().
+					Int("room_id", roomID).
+					Dur("remaining", room.PausedRemaining).
+					Msg("Game auto-paused because all players disconnected")
+				r.gamePaused(room)
+			}
+		}
 
-	return room, nil
+		r.roomChanged(room)
+		return nil
+	})
 }
 
 // SetPlayerReconnected marks a player as reconnected
 func (r *RoomUsecase) SetPlayerReconnected(roomID int, playerID int) (*domain.Room, error) {
-	r.mutex.Lock()
-	defer r.mutex.Unlock()
-
-	room, exists := r.rooms[roomID]
-	if !exists {
-		return nil, fmt.Errorf("room with ID %d not found", roomID)
-	}
+	return r.rooms.UpdateRoom(roomID, func(room *domain.Room) error {
+		// プレイヤーを見つけて再接続状態に設定
+		playerFound := false
+		for i, player := range room.Players {
+			if player.ID == playerID {
+				room.Players[i].IsConnected = true
+				room.Players[i].LastSeenAt = nil
+				playerFound = true
+
+				log.Info().
+					Int("room_id", roomID).
+					Int("player_id", playerID).
+					Str("player_name", player.UserName).
+					Msg("Player reconnected to room")
+				break
+			}
+		}
 
-	// プレイヤーを見つけて再接続状態に設定
-	playerFound := false
-	for i, player := range room.Players {
-		if player.ID == playerID {
-			room.Players[i].IsConnected = true
-			room.Players[i].LastSeenAt = nil
-			playerFound = true
-
-			log.Info().
-				Int("room_id", roomID).
-				Int("player_id", playerID).
-				Str("player_name", player.UserName).
-				Msg("Player reconnected to room")
-			break
+		if !playerFound {
+			return fmt.Errorf("player with ID %d not found in room %d", playerID, roomID)
 		}
-	}
 
-	if !playerFound {
-		return nil, fmt.Errorf("player with ID %d not found in room %d", playerID, roomID)
-	}
+		// 自動一時停止中であれば再接続をきっかけに再開
+		if room.State == domain.StatePaused && room.AutoPaused {
+			now := time.Now()
+			if err := room.ResumeGame(now); err == nil {
+				log.Info().
+					Int("room_id", roomID).
+					Int("player_id", playerID).
+					Msg("Auto-paused game resumed by player reconnection")
+				r.gameResumed(room, now)
+			}
+		}
 
-	return room, nil
+		r.roomChanged(room)
+		return nil
+	})
 }
 
 // RemoveDisconnectedPlayer removes a player who has been disconnected for too long
 func (r *RoomUsecase) RemoveDisconnectedPlayer(roomID int, playerID int) (*domain.Room, error) {
-	r.mutex.Lock()
-	defer r.mutex.Unlock()
-
-	room, exists := r.rooms[roomID]
-	if !exists {
-		return nil, fmt.Errorf("room with ID %d not found", roomID)
-	}
-
-	// プレイヤーを見つけて削除（安全性向上）
-	playerFound := false
-	for i := 0; i < len(room.Players); i++ {
-		if room.Players[i].ID == playerID && !room.Players[i].IsConnected {
-			playerName := room.Players[i].UserName
-			// 境界チェック付きでスライスから削除
-			if i < len(room.Players)-1 {
-				room.Players = append(room.Players[:i], room.Players[i+1:]...)
-			} else {
-				room.Players = room.Players[:i]
+	return r.rooms.UpdateRoom(roomID, func(room *domain.Room) error {
+		// プレイヤーを見つけて削除（安全性向上）
+		playerFound := false
+		for i := 0; i < len(room.Players); i++ {
+			if room.Players[i].ID == playerID && !room.Players[i].IsConnected {
+				playerName := room.Players[i].UserName
+				// ゲーム中に切断したまま戻らなかったプレイヤーも最下位として結果に残す
+				room.RecordLeaver(room.Players[i])
+				// 境界チェック付きでスライスから削除
+				if i < len(room.Players)-1 {
+					room.Players = append(room.Players[:i], room.Players[i+1:]...)
+				} else {
+					room.Players = room.Players[:i]
+				}
+				playerFound = true
+
+				log.Info().
+					Int("room_id", roomID).
+					Int("player_id", playerID).
+					Str("player_name", playerName).
+					Msg("Disconnected player permanently removed from room")
+				break
 			}
-			playerFound = true
-
-			log.Info().
-				Int("room_id", roomID).
-				Int("player_id", playerID).
-				Str("player_name", playerName).
-				Msg("Disconnected player permanently removed from room")
-			break
 		}
-	}
 
-	if !playerFound {
-		return nil, fmt.Errorf("disconnected player with ID %d not found in room %d", playerID, roomID)
-	}
-
-	// 参加者が0人になった場合、ルームをリセット
-	if len(room.Players) == 0 {
-		err := room.ResetRoom()
-		if err != nil {
-			// StateGameEndedでない場合は強制的にWaitingForPlayersに戻す
-			room.State = domain.StateWaitingForPlayers
+		if !playerFound {
+			return fmt.Errorf("disconnected player with ID %d not found in room %d", playerID, roomID)
 		}
-		// ゲームボードもリセット
-		room.GameBoards = []domain.GameBoard{domain.NewBoard()}
-		room.ResultLog = []domain.Result{}
-		room.IsOpened = true // ルームを再度開放
-	} else {
-		// まだプレイヤーがいる場合、READY状態をチェック（接続中のプレイヤーのみ）
-		if !r.areConnectedPlayersReady(room) && room.State == domain.StateAllReady {
-			room.TransitionTo(domain.StateWaitingForPlayers)
-			// 状態がWaitingForPlayersに戻った場合、部屋を再度開放
-			room.IsOpened = true
+
+		// 参加者が0人になった場合、ルームをリセット
+		if len(room.Players) == 0 {
+			err := room.ResetRoom()
+			if err != nil {
+				// StateGameEndedでない場合は強制的にWaitingForPlayersに戻す
+				room.State = domain.StateWaitingForPlayers
+			}
+			// ゲームボードもリセット
+			room.GameBoards = []domain.GameBoard{domain.NewBoard()}
+			room.ResultLog = []domain.Result{}
+			room.Leavers = nil
+			room.IsOpened = true // ルームを再度開放
+		} else {
+			// まだプレイヤーがいる場合、READY状態をチェック（接続中のプレイヤーのみ）
+			if !r.areConnectedPlayersReady(room) && room.State == domain.StateAllReady {
+				room.TransitionTo(domain.StateWaitingForPlayers)
+				// 状態がWaitingForPlayersに戻った場合、部屋を再度開放
+				room.IsOpened = true
+			}
 		}
-	}
 
-	return room, nil
+		r.roomChanged(room)
+		return nil
+	})
 }
 
 // GetDisconnectedPlayers returns all disconnected players in all rooms
 func (r *RoomUsecase) GetDisconnectedPlayers() map[int][]domain.Player {
-	r.mutex.RLock()
-	defer r.mutex.RUnlock()
-
 	disconnectedPlayers := make(map[int][]domain.Player)
 
-	for roomID, room := range r.rooms {
-		var roomDisconnected []domain.Player
-		for _, player := range room.Players {
-			if !player.IsConnected {
-				roomDisconnected = append(roomDisconnected, player)
+	for _, room := range r.rooms.ListRooms() {
+		r.rooms.ViewRoom(room.ID, func(room *domain.Room) error {
+			var roomDisconnected []domain.Player
+			for _, player := range room.Players {
+				if !player.IsConnected {
+					roomDisconnected = append(roomDisconnected, player)
+				}
 			}
-		}
-		if len(roomDisconnected) > 0 {
-			disconnectedPlayers[roomID] = roomDisconnected
-		}
+			if len(roomDisconnected) > 0 {
+				disconnectedPlayers[room.ID] = roomDisconnected
+			}
+			return nil
+		})
 	}
 
 	return disconnectedPlayers
@@ -0,0 +1,275 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kaitoyama/kaitoyama-server-template/internal/domain"
+)
+
+// countingRoomRepository はUpdateRoomの呼び出し回数を数えるRoomRepository
+type countingRoomRepository struct {
+	domain.RoomRepository
+	updates int
+}
+
+func (c *countingRoomRepository) UpdateRoom(roomID int, fn func(room *domain.Room) error) (*domain.Room, error) {
+	c.updates++
+	return c.RoomRepository.UpdateRoom(roomID, fn)
+}
+
+func TestRoomUsecase_UsesRepository(t *testing.T) {
+	repository := &countingRoomRepository{RoomRepository: domain.NewMemoryRoomRepository()}
+	existing := domain.NewRoom(3, "Existing room")
+	existing.Players = []domain.Player{{ID: 9, UserName: "carol"}}
+	repository.SaveRoom(existing)
+
+	rooms := NewRoomUsecaseWithRepository(repository, nil)
+
+	// 既にリポジトリにあるroomは初期化で上書きしない
+	if got := rooms.GetRooms(); len(got) != 10 || got[2].RoomName != "Existing room" || len(got[2].Users) != 1 {
+		t.Fatalf("GetRooms() = %+v", got)
+	}
+
+	if _, err := rooms.AddPlayerToRoom(1, domain.Player{ID: 1, UserName: "alice"}); err != nil {
+		t.Fatalf("AddPlayerToRoom() error = %v", err)
+	}
+	if _, err := rooms.UpdatePlayerReadyStatus(1, 1, true); err != nil {
+		t.Fatalf("UpdatePlayerReadyStatus() error = %v", err)
+	}
+	if repository.updates != 2 {
+		t.Errorf("UpdateRoom called %d times, want 2", repository.updates)
+	}
+	room, _ := rooms.GetRoomByID(1)
+	if room.State != domain.StateAllReady || room.IsOpened {
+		t.Errorf("room state = %s, opened %v, want closed AllReady", room.State, room.IsOpened)
+	}
+
+	// 存在しないroomはどの操作でもErrRoomNotFound
+	if _, err := rooms.StartGame(42); !errors.Is(err, domain.ErrRoomNotFound) {
+		t.Errorf("StartGame() error = %v, want ErrRoomNotFound", err)
+	}
+	if _, _, err := rooms.ApplyFormulaWithVersion(42, 1, "12+3+4+", 1); !errors.Is(err, domain.ErrRoomNotFound) {
+		t.Errorf("ApplyFormulaWithVersion() error = %v, want ErrRoomNotFound", err)
+	}
+	if _, err := rooms.GetGameClock(42); !errors.Is(err, domain.ErrRoomNotFound) {
+		t.Errorf("GetGameClock() error = %v, want ErrRoomNotFound", err)
+	}
+}
+
+// capturingGameResultRepository は保存されたゲーム結果を記録するGameResultRepository
+type capturingGameResultRepository struct {
+	domain.GameResultRepository
+	results []domain.GameResult
+}
+
+func (c *capturingGameResultRepository) SaveGameResult(ctx context.Context, result domain.GameResult) (int64, error) {
+	c.results = append(c.results, result)
+	return int64(len(c.results)), nil
+}
+
+func TestRoomUsecase_LeaverIsRankedLast(t *testing.T) {
+	results := &capturingGameResultRepository{}
+	rooms := NewRoomUsecase(results)
+
+	for _, player := range []domain.Player{{ID: 1, UserName: "alice"}, {ID: 2, UserName: "bob"}} {
+		if _, err := rooms.AddPlayerToRoom(1, player); err != nil {
+			t.Fatalf("AddPlayerToRoom() error = %v", err)
+		}
+		if _, err := rooms.UpdatePlayerReadyStatus(1, player.ID, true); err != nil {
+			t.Fatalf("UpdatePlayerReadyStatus() error = %v", err)
+		}
+	}
+	if _, err := rooms.StartGame(1); err != nil {
+		t.Fatalf("StartGame() error = %v", err)
+	}
+	if _, err := rooms.CompleteCountdown(1); err != nil {
+		t.Fatalf("CompleteCountdown() error = %v", err)
+	}
+
+	// 負けそうなプレイヤーが途中で退出してもゲーム結果から消えない
+	if _, err := rooms.RemovePlayerFromRoom(1, 2); err != nil {
+		t.Fatalf("RemovePlayerFromRoom() error = %v", err)
+	}
+	if _, err := rooms.EndGame(1); err != nil {
+		t.Fatalf("EndGame() error = %v", err)
+	}
+
+	if len(results.results) != 1 {
+		t.Fatalf("saved %d results, want 1", len(results.results))
+	}
+	players := results.results[0].Players
+	if len(players) != 2 {
+		t.Fatalf("result has %d players, want 2: %+v", len(players), players)
+	}
+	if players[0].UserID != 1 || players[0].Rank != 1 || players[0].Left {
+		t.Errorf("players[0] = %+v, want alice ranked 1st", players[0])
+	}
+	if players[1].UserID != 2 || players[1].Rank != 2 || !players[1].Left {
+		t.Errorf("players[1] = %+v, want bob ranked last as a leaver", players[1])
+	}
+
+	// 次のゲームには退出者を持ち越さない
+	room, err := rooms.CloseResult(1, 1)
+	if err != nil {
+		t.Fatalf("CloseResult() error = %v", err)
+	}
+	if room.State != domain.StateWaitingForPlayers || len(room.Leavers) != 0 {
+		t.Errorf("leavers = %+v after reset, want none", room.Leavers)
+	}
+}
+
+// recordingPauseListener は一時停止・再開の通知を順に記録するGamePauseListener
+type recordingPauseListener struct {
+	events []string
+}
+
+func (l *recordingPauseListener) GamePaused(roomID int, remaining time.Duration, autoPaused bool) {
+	if autoPaused {
+		l.events = append(l.events, "auto_paused")
+		return
+	}
+	l.events = append(l.events, "paused")
+}
+
+func (l *recordingPauseListener) GameResumed(roomID int, remaining time.Duration) {
+	l.events = append(l.events, "resumed")
+}
+
+func TestRoomUsecase_NotifiesPauseAndResume(t *testing.T) {
+	rooms := NewRoomUsecase(nil)
+	listener := &recordingPauseListener{}
+	rooms.SetGamePauseListener(listener)
+
+	if _, err := rooms.AddPlayerToRoom(1, domain.Player{ID: 1, UserName: "alice"}); err != nil {
+		t.Fatalf("AddPlayerToRoom() error = %v", err)
+	}
+	if _, err := rooms.UpdatePlayerReadyStatus(1, 1, true); err != nil {
+		t.Fatalf("UpdatePlayerReadyStatus() error = %v", err)
+	}
+	if _, err := rooms.StartGame(1); err != nil {
+		t.Fatalf("StartGame() error = %v", err)
+	}
+	if _, err := rooms.CompleteCountdown(1); err != nil {
+		t.Fatalf("CompleteCountdown() error = %v", err)
+	}
+
+	// ホストの操作による一時停止・再開
+	if _, err := rooms.PauseGame(1); err != nil {
+		t.Fatalf("PauseGame() error = %v", err)
+	}
+	if _, err := rooms.ResumeGame(1); err != nil {
+		t.Fatalf("ResumeGame() error = %v", err)
+	}
+	// 失敗した操作は通知しない
+	if _, err := rooms.ResumeGame(1); err == nil {
+		t.Fatal("ResumeGame() on a running game succeeded")
+	}
+
+	// 全員の切断による自動一時停止と、再接続による再開
+	if _, err := rooms.SetPlayerDisconnected(1, 1); err != nil {
+		t.Fatalf("SetPlayerDisconnected() error = %v", err)
+	}
+	if _, err := rooms.SetPlayerReconnected(1, 1); err != nil {
+		t.Fatalf("SetPlayerReconnected() error = %v", err)
+	}
+
+	want := []string{"paused", "resumed", "auto_paused", "resumed"}
+	if len(listener.events) != len(want) {
+		t.Fatalf("events = %v, want %v", listener.events, want)
+	}
+	for i := range want {
+		if listener.events[i] != want[i] {
+			t.Errorf("events = %v, want %v", listener.events, want)
+			break
+		}
+	}
+}
+
+// startTestGame は指定したプレイヤーでroom 1のゲームを開始する
+func startTestGame(t *testing.T, rooms *RoomUsecase, players ...domain.Player) {
+	t.Helper()
+	for _, player := range players {
+		if _, err := rooms.AddPlayerToRoom(1, player); err != nil {
+			t.Fatalf("AddPlayerToRoom() error = %v", err)
+		}
+		if _, err := rooms.UpdatePlayerReadyStatus(1, player.ID, true); err != nil {
+			t.Fatalf("UpdatePlayerReadyStatus() error = %v", err)
+		}
+	}
+	if _, err := rooms.StartGame(1); err != nil {
+		t.Fatalf("StartGame() error = %v", err)
+	}
+	if _, err := rooms.CompleteCountdown(1); err != nil {
+		t.Fatalf("CompleteCountdown() error = %v", err)
+	}
+}
+
+func TestRoomUsecase_AutoPausesAndResumesOnConnectionChanges(t *testing.T) {
+	rooms := NewRoomUsecase(nil)
+	startTestGame(t, rooms, domain.Player{ID: 1, UserName: "alice"}, domain.Player{ID: 2, UserName: "bob"})
+
+	// 接続中のプレイヤーが残っている間は一時停止しない
+	room, err := rooms.SetPlayerDisconnected(1, 1)
+	if err != nil {
+		t.Fatalf("SetPlayerDisconnected() error = %v", err)
+	}
+	if room.State != domain.StateGameInProgress {
+		t.Fatalf("state = %s after one player disconnected, want %s", room.State, domain.StateGameInProgress)
+	}
+
+	// 最後のプレイヤーが切断すると自動で一時停止する
+	room, err = rooms.SetPlayerDisconnected(1, 2)
+	if err != nil {
+		t.Fatalf("SetPlayerDisconnected() error = %v", err)
+	}
+	if room.State != domain.StatePaused || !room.AutoPaused {
+		t.Fatalf("state = %s (auto %v) after all players disconnected, want auto-paused", room.State, room.AutoPaused)
+	}
+
+	// 誰かが再接続すると再開する
+	room, err = rooms.SetPlayerReconnected(1, 2)
+	if err != nil {
+		t.Fatalf("SetPlayerReconnected() error = %v", err)
+	}
+	if room.State != domain.StateGameInProgress || room.AutoPaused {
+		t.Errorf("state = %s (auto %v) after reconnect, want %s", room.State, room.AutoPaused, domain.StateGameInProgress)
+	}
+}
+
+func TestRoomUsecase_ReconnectDoesNotResumeManualPause(t *testing.T) {
+	rooms := NewRoomUsecase(nil)
+	startTestGame(t, rooms, domain.Player{ID: 1, UserName: "alice"})
+
+	if _, err := rooms.PauseGame(1); err != nil {
+		t.Fatalf("PauseGame() error = %v", err)
+	}
+	if _, err := rooms.SetPlayerDisconnected(1, 1); err != nil {
+		t.Fatalf("SetPlayerDisconnected() error = %v", err)
+	}
+	room, err := rooms.SetPlayerReconnected(1, 1)
+	if err != nil {
+		t.Fatalf("SetPlayerReconnected() error = %v", err)
+	}
+	if room.State != domain.StatePaused {
+		t.Errorf("state = %s after reconnect, want the manual pause to remain", room.State)
+	}
+}
+
+func TestRoomUsecase_RejectsFormulaWhilePaused(t *testing.T) {
+	rooms := NewRoomUsecase(nil)
+	startTestGame(t, rooms, domain.Player{ID: 1, UserName: "alice"})
+
+	room, err := rooms.PauseGame(1)
+	if err != nil {
+		t.Fatalf("PauseGame() error = %v", err)
+	}
+	version := room.GameBoards[len(room.GameBoards)-1].Version
+
+	if _, _, err := rooms.ApplyFormulaWithVersion(1, 1, "1+2+3+4", version); err == nil || !strings.Contains(err.Error(), "paused") {
+		t.Errorf("ApplyFormulaWithVersion() error = %v, want a paused game error", err)
+	}
+}
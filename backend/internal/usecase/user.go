@@ -2,20 +2,102 @@ package usecase
 
 import (
 	"context"
+	"crypto/rand"
 	"database/sql"
+	"encoding/hex"
 	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+	"unicode"
 
 	"github.com/kaitoyama/kaitoyama-server-template/internal/db"
+	"github.com/kaitoyama/kaitoyama-server-template/internal/domain"
+	"github.com/rs/zerolog/log"
 	"golang.org/x/crypto/bcrypt"
 )
 
 type UserUsecase struct {
 	querier db.Querier
+	stats   domain.UserStatsRepository
+	// guestTTL はゲストユーザーを本登録しないまま残しておく期間
+	guestTTL time.Duration
 }
 
-func NewUserUsecase(querier db.Querier) *UserUsecase {
+var (
+	// ErrUserNotFound is returned when the requested user does not exist
+	ErrUserNotFound = errors.New("user not found")
+	// ErrUsernameTaken is returned when registering a username that already exists
+	ErrUsernameTaken = errors.New("username already taken")
+	// ErrInvalidUsername is returned when the username violates the username policy
+	ErrInvalidUsername = errors.New("invalid username")
+	// ErrWeakPassword is returned when the password violates the password policy
+	ErrWeakPassword = errors.New("weak password")
+	// ErrNotGuest is returned when upgrading a user that is not a guest
+	ErrNotGuest = errors.New("user is not a guest")
+	// ErrInvalidCredentials is returned when the username or password is wrong.
+	// ユーザー名の存在を推測されないよう、どちらが間違っているかは区別しない
+	ErrInvalidCredentials = errors.New("invalid credentials")
+)
+
+const (
+	MinUsernameLength = 3
+	MaxUsernameLength = 32
+	MinPasswordLength = 8
+	// MaxPasswordLength はbcryptが扱える72バイト
+	MaxPasswordLength = 72
+	// GuestUsernamePrefix はゲストユーザーの自動生成の名前の接頭辞（登録するユーザー名には使えない）
+	GuestUsernamePrefix = "guest-"
+)
+
+// usernamePattern は英数字・_・-だけのユーザー名（URLやメンションにそのまま使える）
+var usernamePattern = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// ValidateUsername checks the username policy: 3-32 characters of letters, digits, '_' and '-'.
+// ゲストの名前と紛らわしい"guest-"で始まる名前は使えない
+func ValidateUsername(username string) error {
+	if len(username) < MinUsernameLength || len(username) > MaxUsernameLength {
+		return fmt.Errorf("%w: must be %d to %d characters", ErrInvalidUsername, MinUsernameLength, MaxUsernameLength)
+	}
+	if !usernamePattern.MatchString(username) {
+		return fmt.Errorf("%w: only letters, digits, '_' and '-' are allowed", ErrInvalidUsername)
+	}
+	if strings.HasPrefix(strings.ToLower(username), GuestUsernamePrefix) {
+		return fmt.Errorf("%w: the prefix %q is reserved for guests", ErrInvalidUsername, GuestUsernamePrefix)
+	}
+	return nil
+}
+
+// ValidatePassword checks the password policy:
+// 8-72 bytes with at least one letter and one digit, and not containing the username
+func ValidatePassword(username, password string) error {
+	if len(password) < MinPasswordLength || len(password) > MaxPasswordLength {
+		return fmt.Errorf("%w: must be %d to %d bytes", ErrWeakPassword, MinPasswordLength, MaxPasswordLength)
+	}
+	var hasLetter, hasDigit bool
+	for _, r := range password {
+		switch {
+		case unicode.IsLetter(r):
+			hasLetter = true
+		case unicode.IsDigit(r):
+			hasDigit = true
+		}
+	}
+	if !hasLetter || !hasDigit {
+		return fmt.Errorf("%w: must contain both letters and digits", ErrWeakPassword)
+	}
+	if username != "" && strings.Contains(strings.ToLower(password), strings.ToLower(username)) {
+		return fmt.Errorf("%w: must not contain the username", ErrWeakPassword)
+	}
+	return nil
+}
+
+func NewUserUsecase(querier db.Querier, stats domain.UserStatsRepository, guestTTL time.Duration) *UserUsecase {
 	return &UserUsecase{
-		querier: querier,
+		querier:  querier,
+		stats:    stats,
+		guestTTL: guestTTL,
 	}
 }
 
@@ -29,6 +111,35 @@ type CreateUserResponse struct {
 	Username string
 }
 
+// RegisterUser creates a user with a password after checking the username and password policies.
+// 既存のユーザー名の場合はErrUsernameTaken
+func (u *UserUsecase) RegisterUser(ctx context.Context, req CreateUserRequest) (*CreateUserResponse, error) {
+	if err := ValidateUsername(req.Username); err != nil {
+		return nil, err
+	}
+	if err := ValidatePassword(req.Username, req.Password); err != nil {
+		return nil, err
+	}
+
+	_, err := u.querier.GetUserByUsername(ctx, req.Username)
+	if err == nil {
+		return nil, ErrUsernameTaken
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("failed to get user %s: %w", req.Username, err)
+	}
+
+	resp, err := u.CreateUser(ctx, req)
+	if err != nil {
+		// 確認から作成までの間に同じユーザー名で登録された（ドライバごとの一意制約エラーを区別せずに確認し直す）
+		if _, getErr := u.querier.GetUserByUsername(ctx, req.Username); getErr == nil {
+			return nil, ErrUsernameTaken
+		}
+		return nil, fmt.Errorf("failed to create user %s: %w", req.Username, err)
+	}
+	return resp, nil
+}
+
 func (u *UserUsecase) CreateUser(ctx context.Context, req CreateUserRequest) (*CreateUserResponse, error) {
 	// パスワードをハッシュ化
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
@@ -82,22 +193,197 @@ func (u *UserUsecase) GetUserByUsername(ctx context.Context, username string) (*
 	return &user, nil
 }
 
+// AuthenticateUser checks the password of the user.
+// ユーザーが存在しない・パスワードが未設定・パスワードが違う場合はErrInvalidCredentials
 func (u *UserUsecase) AuthenticateUser(ctx context.Context, username, password string) (*db.User, error) {
 	// ユーザー情報を取得
 	user, err := u.querier.GetUserByUsername(ctx, username)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("%w: user not found", ErrInvalidCredentials)
+	}
 	if err != nil {
-		return nil, errors.New("user not found")
+		return nil, fmt.Errorf("failed to get user %s: %w", username, err)
 	}
 
-	// パスワードを検証
+	// パスワードを検証（X-Forwarded-Userで作られたユーザーはパスワードを持たない）
 	if !user.PasswordHash.Valid {
-		return nil, errors.New("user has no password")
+		return nil, fmt.Errorf("%w: user has no password", ErrInvalidCredentials)
 	}
 
 	err = bcrypt.CompareHashAndPassword([]byte(user.PasswordHash.String), []byte(password))
 	if err != nil {
-		return nil, errors.New("invalid password")
+		return nil, fmt.Errorf("%w: invalid password", ErrInvalidCredentials)
 	}
 
 	return &user, nil
 }
+
+// UserProfile はユーザー名と通算成績
+type UserProfile struct {
+	UserID   int
+	Username string
+	IsGuest  bool
+	Stats    domain.UserStats
+}
+
+// GetUserProfile returns the user's name and the stats summarized at game end
+func (u *UserUsecase) GetUserProfile(ctx context.Context, userID int) (*UserProfile, error) {
+	user, err := u.querier.GetUser(ctx, int32(userID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrUserNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user %d: %w", userID, err)
+	}
+
+	stats, err := u.stats.GetUserStats(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &UserProfile{
+		UserID:   int(user.ID),
+		Username: user.Username,
+		IsGuest:  user.IsGuest,
+		Stats:    stats,
+	}, nil
+}
+
+// CreateGuest creates a guest user with a generated name and no password.
+// 本登録されないままguestTTLが過ぎると、DeleteExpiredGuestsで成績ごと削除される
+func (u *UserUsecase) CreateGuest(ctx context.Context) (*CreateUserResponse, error) {
+	expiresAt := sql.NullTime{Time: time.Now().Add(u.guestTTL), Valid: true}
+	// 生成した名前が既存のゲストと衝突した場合は作り直す
+	var lastErr error
+	for attempt := 0; attempt < 3; attempt++ {
+		username, err := newGuestUsername()
+		if err != nil {
+			return nil, err
+		}
+		result, err := u.querier.CreateGuestUser(ctx, db.CreateGuestUserParams{
+			Username:       username,
+			GuestExpiresAt: expiresAt,
+		})
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		userID, err := result.LastInsertId()
+		if err != nil {
+			return nil, err
+		}
+		return &CreateUserResponse{
+			UserID:   userID,
+			Username: username,
+		}, nil
+	}
+	return nil, fmt.Errorf("failed to create guest user: %w", lastErr)
+}
+
+// UpgradeGuest converts the guest into a full account with a username and password.
+// ユーザーIDはそのままのため、ゲストの間の成績・スコア・レーティングは引き継がれる
+func (u *UserUsecase) UpgradeGuest(ctx context.Context, userID int32, req CreateUserRequest) (*CreateUserResponse, error) {
+	if err := ValidateUsername(req.Username); err != nil {
+		return nil, err
+	}
+	if err := ValidatePassword(req.Username, req.Password); err != nil {
+		return nil, err
+	}
+
+	existing, err := u.querier.GetUserByUsername(ctx, req.Username)
+	if err == nil && existing.ID != userID {
+		return nil, ErrUsernameTaken
+	}
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("failed to get user %s: %w", req.Username, err)
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, err
+	}
+	result, err := u.querier.UpgradeGuestUser(ctx, db.UpgradeGuestUserParams{
+		Username:     req.Username,
+		PasswordHash: sql.NullString{String: string(hashedPassword), Valid: true},
+		ID:           userID,
+	})
+	if err != nil {
+		// 確認から更新までの間に同じユーザー名で登録された
+		if existing, getErr := u.querier.GetUserByUsername(ctx, req.Username); getErr == nil && existing.ID != userID {
+			return nil, ErrUsernameTaken
+		}
+		return nil, fmt.Errorf("failed to upgrade guest %d: %w", userID, err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return nil, err
+	}
+	if affected == 0 {
+		// 本登録済み・削除済みのユーザー
+		return nil, ErrNotGuest
+	}
+
+	return &CreateUserResponse{
+		UserID:   int64(userID),
+		Username: req.Username,
+	}, nil
+}
+
+// DeleteExpiredGuests deletes the guests that were not upgraded in time and returns how many were deleted or anonymised.
+// 終了したゲームに参加したゲストは、他のプレイヤーのリプレイ・レーティングの履歴が消えないよう削除せずに匿名化し、セッションを失効させる
+func (u *UserUsecase) DeleteExpiredGuests(ctx context.Context) (int64, error) {
+	now := sql.NullTime{Time: time.Now(), Valid: true}
+	anonymized, err := u.querier.AnonymizeExpiredGuestUsers(ctx, now)
+	if err != nil {
+		return 0, fmt.Errorf("failed to anonymize expired guests: %w", err)
+	}
+	if err := u.querier.RevokeAnonymizedGuestRefreshTokens(ctx, now); err != nil {
+		return 0, fmt.Errorf("failed to revoke sessions of anonymized guests: %w", err)
+	}
+	deleted, err := u.querier.DeleteExpiredGuestUsers(ctx, now)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete expired guests: %w", err)
+	}
+
+	anonymizedCount, err := anonymized.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+	deletedCount, err := deleted.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+	return anonymizedCount + deletedCount, nil
+}
+
+// StartGuestCleanup deletes or anonymizes the expired guests every interval until ctx is done
+func (u *UserUsecase) StartGuestCleanup(ctx context.Context, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				cleaned, err := u.DeleteExpiredGuests(ctx)
+				if err != nil {
+					log.Error().Err(err).Msg("Failed to clean up guest users")
+					continue
+				}
+				if cleaned > 0 {
+					log.Info().Int64("cleaned", cleaned).Msg("Deleted or anonymized expired guest users")
+				}
+			}
+		}
+	}()
+}
+
+// newGuestUsername は"guest-"とランダムな8桁の16進数の名前を返す
+func newGuestUsername() (string, error) {
+	b := make([]byte, 4)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate guest username: %w", err)
+	}
+	return GuestUsernamePrefix + hex.EncodeToString(b), nil
+}
@@ -0,0 +1,109 @@
+package usecase
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/kaitoyama/kaitoyama-server-template/internal/db"
+)
+
+// fakeUserQuerier はユーザー名で引けるユーザーだけを持つdb.Querierのスタブ
+type fakeUserQuerier struct {
+	db.Querier
+	users map[string]db.User
+}
+
+type fakeResult struct{ id int64 }
+
+func (r fakeResult) LastInsertId() (int64, error) { return r.id, nil }
+func (r fakeResult) RowsAffected() (int64, error) { return 1, nil }
+
+func (f *fakeUserQuerier) GetUserByUsername(ctx context.Context, username string) (db.User, error) {
+	user, ok := f.users[username]
+	if !ok {
+		return db.User{}, sql.ErrNoRows
+	}
+	return user, nil
+}
+
+func (f *fakeUserQuerier) CreateUserWithPassword(ctx context.Context, arg db.CreateUserWithPasswordParams) (sql.Result, error) {
+	id := int32(len(f.users) + 1)
+	f.users[arg.Username] = db.User{ID: id, Username: arg.Username, PasswordHash: arg.PasswordHash}
+	return fakeResult{id: int64(id)}, nil
+}
+
+func TestValidateUsername(t *testing.T) {
+	tests := []struct {
+		username string
+		valid    bool
+	}{
+		{"alice", true},
+		{"a_b-1", true},
+		{"ab", false},
+		{"abcdefghijklmnopqrstuvwxyz0123456", false},
+		{"alice bob", false},
+		{"アリス", false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		err := ValidateUsername(tt.username)
+		if tt.valid && err != nil {
+			t.Errorf("ValidateUsername(%q) error = %v, want nil", tt.username, err)
+		}
+		if !tt.valid && !errors.Is(err, ErrInvalidUsername) {
+			t.Errorf("ValidateUsername(%q) error = %v, want ErrInvalidUsername", tt.username, err)
+		}
+	}
+}
+
+func TestValidatePassword(t *testing.T) {
+	tests := []struct {
+		password string
+		valid    bool
+	}{
+		{"correct1horse", true},
+		{"short1", false},
+		{"onlyletters", false},
+		{"1234567890", false},
+		{"xALICE123", false}, // ユーザー名を含む
+		{string(make([]byte, 73)) + "a1", false},
+	}
+	for _, tt := range tests {
+		err := ValidatePassword("alice", tt.password)
+		if tt.valid && err != nil {
+			t.Errorf("ValidatePassword(%q) error = %v, want nil", tt.password, err)
+		}
+		if !tt.valid && !errors.Is(err, ErrWeakPassword) {
+			t.Errorf("ValidatePassword(%q) error = %v, want ErrWeakPassword", tt.password, err)
+		}
+	}
+}
+
+func TestUserUsecase_RegisterAndAuthenticate(t *testing.T) {
+	ctx := context.Background()
+	users := NewUserUsecase(&fakeUserQuerier{users: map[string]db.User{}}, nil, time.Hour)
+
+	created, err := users.RegisterUser(ctx, CreateUserRequest{Username: "alice", Password: "correct1horse"})
+	if err != nil {
+		t.Fatalf("RegisterUser() error = %v", err)
+	}
+	if _, err := users.RegisterUser(ctx, CreateUserRequest{Username: "alice", Password: "another1horse"}); !errors.Is(err, ErrUsernameTaken) {
+		t.Errorf("RegisterUser(duplicate) error = %v, want ErrUsernameTaken", err)
+	}
+
+	user, err := users.AuthenticateUser(ctx, "alice", "correct1horse")
+	if err != nil || int64(user.ID) != created.UserID {
+		t.Errorf("AuthenticateUser() = %+v, %v, want user %d", user, err, created.UserID)
+	}
+	for _, c := range []struct{ username, password string }{
+		{"alice", "wrong1horse"},
+		{"alicee", "correct1horse"},
+	} {
+		if _, err := users.AuthenticateUser(ctx, c.username, c.password); !errors.Is(err, ErrInvalidCredentials) {
+			t.Errorf("AuthenticateUser(%q, %q) error = %v, want ErrInvalidCredentials", c.username, c.password, err)
+		}
+	}
+}
@@ -1,18 +1,42 @@
 package main
 
 import (
+	"context"
 	"database/sql"
+	"embed"
+	"errors"
+	"flag"
 	"fmt"
+	"io/fs"
+	"net/http"
 	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	_ "github.com/go-sql-driver/mysql"
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
 
 	"github.com/kaitoyama/kaitoyama-server-template/internal/infrastructure/config"
+	dbInfra "github.com/kaitoyama/kaitoyama-server-template/internal/infrastructure/db"
 )
 
-func initDB(cfg *config.Config) (*sql.DB, error) {
+//go:embed db/migrations/*.sql db/sqlite/migrations/*.sql
+var migrationFiles embed.FS
+
+// shutdownTimeout は停止時に処理中のリクエストを待つ時間
+const shutdownTimeout = 10 * time.Second
+
+func initDB(cfg *config.Config) (*dbInfra.Database, error) {
+	switch cfg.DBDriver {
+	case dbInfra.DriverSQLite:
+		return dbInfra.OpenSQLite(cfg.SQLitePath)
+	case dbInfra.DriverMySQL:
+	default:
+		return nil, fmt.Errorf("unknown DB_DRIVER %q (expected %q or %q)", cfg.DBDriver, dbInfra.DriverMySQL, dbInfra.DriverSQLite)
+	}
+
 	dsn := fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?parseTime=true",
 		cfg.DBUser,
 		cfg.DBPassword,
@@ -21,7 +45,7 @@ func initDB(cfg *config.Config) (*sql.DB, error) {
 		cfg.DBName,
 	)
 
-	database, err := sql.Open("mysql", dsn)
+	database, err := sql.Open(dbInfra.DriverMySQL, dsn)
 	if err != nil {
 		return nil, err
 	}
@@ -30,7 +54,60 @@ func initDB(cfg *config.Config) (*sql.DB, error) {
 		return nil, err
 	}
 
-	return database, nil
+	return dbInfra.NewDatabase(database, dbInfra.DriverMySQL), nil
+}
+
+// runMigrations applies the embedded migrations (dryRunの場合は適用予定のマイグレーションを表示するだけ)
+func runMigrations(database *dbInfra.Database, dryRun bool) error {
+	dir := "db/migrations"
+	if database.Driver == dbInfra.DriverSQLite {
+		dir = "db/sqlite/migrations"
+	}
+	files, err := fs.Sub(migrationFiles, dir)
+	if err != nil {
+		return err
+	}
+	migrator, err := dbInfra.NewMigrator(database, files)
+	if err != nil {
+		return err
+	}
+
+	migrations, err := migrator.Migrate(context.Background(), dryRun)
+	if err != nil {
+		return err
+	}
+	if !dryRun {
+		log.Info().Int("applied", len(migrations)).Msg("Database schema is up to date")
+		return nil
+	}
+
+	if len(migrations) == 0 {
+		fmt.Println("-- No pending migrations")
+	}
+	for _, migration := range migrations {
+		fmt.Printf("-- Migration %04d_%s\n", migration.Version, migration.Name)
+		for _, statement := range dbInfra.SplitStatements(migration.SQL) {
+			fmt.Printf("%s;\n\n", statement)
+		}
+	}
+	return nil
+}
+
+// migrateCommand は`migrate`サブコマンド。マイグレーションを適用して終了する
+func migrateCommand(cfg *config.Config, args []string) {
+	flags := flag.NewFlagSet("migrate", flag.ExitOnError)
+	dryRun := flags.Bool("dry-run", false, "print the pending migrations without applying them")
+	flags.Parse(args)
+
+	database, err := initDB(cfg)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Failed to connect to database")
+	}
+	defer database.Close()
+
+	if err := runMigrations(database, *dryRun); err != nil {
+		log.Fatal().Err(err).Msg("Failed to migrate database")
+	}
 }
 
 func main() {
@@ -41,6 +118,11 @@ func main() {
 	// Load configuration
 	cfg := config.LoadConfig()
 
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		migrateCommand(cfg, os.Args[2:])
+		return
+	}
+
 	// Initialize database
 	database, err := initDB(cfg)
 	if err != nil {
@@ -48,9 +130,34 @@ func main() {
 	}
 	defer database.Close()
 
+	// Apply pending migrations（複数インスタンスが同時に起動してもロックで1つずつ適用される）
+	if cfg.DBMigrateOnStart {
+		if err := runMigrations(database, false); err != nil {
+			log.Fatal().Err(err).Msg("Failed to migrate database")
+		}
+	}
+
 	// Create Echo instance
-	e := SetupRouter(database)
+	e, closeRouter := SetupRouter(database)
+
+	// SIGINT・SIGTERMを受けたら新しいリクエストの受け付けを止め、処理中のリクエストを待ってから終了する
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
 
 	// start server
-	e.Logger.Fatal(e.Start(":" + cfg.Port))
+	go func() {
+		if err := e.Start(":" + cfg.Port); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Fatal().Err(err).Msg("Failed to start server")
+		}
+	}()
+
+	<-ctx.Done()
+	log.Info().Msg("Shutting down server")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := e.Shutdown(shutdownCtx); err != nil {
+		log.Error().Err(err).Msg("Failed to shut down server gracefully")
+	}
+	// 停止後に書き込み待ちの記録を保存する（データベースを閉じる前）
+	closeRouter()
 }
@@ -3,18 +3,44 @@
 // Code generated by github.com/oapi-codegen/oapi-codegen/v2 version v2.4.1 DO NOT EDIT.
 package models
 
+import (
+	"time"
+)
+
+// Defines values for GetLeaderboardParamsWindow.
+const (
+	GetLeaderboardParamsWindowAll     GetLeaderboardParamsWindow = "all"
+	GetLeaderboardParamsWindowDaily   GetLeaderboardParamsWindow = "daily"
+	GetLeaderboardParamsWindowMonthly GetLeaderboardParamsWindow = "monthly"
+	GetLeaderboardParamsWindowWeekly  GetLeaderboardParamsWindow = "weekly"
+)
+
+// Defines values for GetLeaderboardParamsMode.
+const (
+	GetLeaderboardParamsModeAll  GetLeaderboardParamsMode = "all"
+	GetLeaderboardParamsModeBest GetLeaderboardParamsMode = "best"
+)
+
 // Defines values for PostRoomsRoomIdActionsJSONBodyAction.
 const (
 	ABORT       PostRoomsRoomIdActionsJSONBodyAction = "ABORT"
 	CANCEL      PostRoomsRoomIdActionsJSONBodyAction = "CANCEL"
 	CLOSERESULT PostRoomsRoomIdActionsJSONBodyAction = "CLOSE_RESULT"
 	JOIN        PostRoomsRoomIdActionsJSONBodyAction = "JOIN"
+	PAUSE       PostRoomsRoomIdActionsJSONBodyAction = "PAUSE"
 	READY       PostRoomsRoomIdActionsJSONBodyAction = "READY"
+	RESUME      PostRoomsRoomIdActionsJSONBodyAction = "RESUME"
 	START       PostRoomsRoomIdActionsJSONBodyAction = "START"
 )
 
 // AuthResponse defines model for AuthResponse.
 type AuthResponse struct {
+	// ExpiresIn Lifetime of the access token in seconds
+	ExpiresIn int `json:"expires_in"`
+
+	// RefreshToken Single-use refresh token for POST /auth/refresh
+	RefreshToken string `json:"refresh_token"`
+
 	// Token JWT access token
 	Token string `json:"token"`
 	User  struct {
@@ -32,6 +58,123 @@ type Board struct {
 	Version int `json:"version"`
 }
 
+// GameReplay defines model for GameReplay.
+type GameReplay struct {
+	EndedAt time.Time `json:"endedAt"`
+	GameId  int       `json:"gameId"`
+
+	// InitialBoard Board content at game start (row-major)
+	InitialBoard []int              `json:"initialBoard"`
+	Moves        []GameReplayMove   `json:"moves"`
+	Players      []GameReplayPlayer `json:"players"`
+	RoomId       int                `json:"roomId"`
+	Seed         int64              `json:"seed"`
+	StartedAt    time.Time          `json:"startedAt"`
+}
+
+// GameReplayMove defines model for GameReplayMove.
+type GameReplayMove struct {
+	// Board Board content after the move (row-major)
+	Board       []int           `json:"board"`
+	Changes     []MatchedRegion `json:"changes"`
+	Expression  string          `json:"expression"`
+	GainedScore int             `json:"gainedScore"`
+	Seq         int             `json:"seq"`
+	SubmittedAt time.Time       `json:"submittedAt"`
+
+	// SubmittedVersion Board version the client submitted against
+	SubmittedVersion int `json:"submittedVersion"`
+	UserId           int `json:"userId"`
+
+	// Version Board version after the move was applied
+	Version int `json:"version"`
+}
+
+// GameReplayPlayer defines model for GameReplayPlayer.
+type GameReplayPlayer struct {
+	Rank     int    `json:"rank"`
+	Score    int    `json:"score"`
+	UserId   int    `json:"userId"`
+	Username string `json:"username"`
+}
+
+// Leaderboard defines model for Leaderboard.
+type Leaderboard struct {
+	Entries []LeaderboardEntry `json:"entries"`
+	Me      *LeaderboardRank   `json:"me,omitempty"`
+	Mode    string             `json:"mode"`
+
+	// NextCursor Cursor for the next page. Omitted on the last page
+	NextCursor *string `json:"nextCursor,omitempty"`
+	Window     string  `json:"window"`
+}
+
+// LeaderboardEntry defines model for LeaderboardEntry.
+type LeaderboardEntry struct {
+	// AchievedAt When the score was recorded (mode=all only)
+	AchievedAt *time.Time `json:"achievedAt,omitempty"`
+	Rank       int        `json:"rank"`
+	Score      int        `json:"score"`
+	UserId     int        `json:"userId"`
+	Username   string     `json:"username"`
+}
+
+// LeaderboardRank defines model for LeaderboardRank.
+type LeaderboardRank struct {
+	Rank  int `json:"rank"`
+	Score int `json:"score"`
+}
+
+// MatchedRegion defines model for MatchedRegion.
+type MatchedRegion struct {
+	Index    int    `json:"index"`
+	LineType string `json:"lineType"`
+
+	// Positions [row, col] of each cell
+	Positions [][]int `json:"positions"`
+}
+
+// OIDCAuthorization defines model for OIDCAuthorization.
+type OIDCAuthorization struct {
+	// AuthorizationUrl URL of the provider's authorization endpoint to navigate to
+	AuthorizationUrl string `json:"authorization_url"`
+
+	// State Value the callback must return unchanged
+	State string `json:"state"`
+}
+
+// OIDCCallbackRequest defines model for OIDCCallbackRequest.
+type OIDCCallbackRequest struct {
+	// Code Authorization code from the redirect_uri query
+	Code string `json:"code"`
+
+	// State State from the redirect_uri query
+	State string `json:"state"`
+}
+
+// OIDCProviders defines model for OIDCProviders.
+type OIDCProviders struct {
+	Providers []string `json:"providers"`
+}
+
+// RatingEntry defines model for RatingEntry.
+type RatingEntry struct {
+	// Deviation Rating deviation. Smaller means the rating is more certain
+	Deviation float64 `json:"deviation"`
+
+	// GamesPlayed Number of rated games
+	GamesPlayed int     `json:"gamesPlayed"`
+	Rank        int     `json:"rank"`
+	Rating      float64 `json:"rating"`
+	UserId      int     `json:"userId"`
+	Username    string  `json:"username"`
+}
+
+// RefreshTokenRequest defines model for RefreshTokenRequest.
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
 // Room defines model for Room.
 type Room struct {
 	IsOpened bool   `json:"isOpened"`
@@ -61,6 +204,71 @@ type UserCreate struct {
 	Username string `json:"username"`
 }
 
+// UserStats Statistics summarized at the end of each game
+type UserStats struct {
+	AcceptedFormulas int `json:"acceptedFormulas"`
+
+	// Accuracy acceptedFormulas / (acceptedFormulas + rejectedFormulas), 0 when nothing was submitted
+	Accuracy     float64 `json:"accuracy"`
+	AverageScore float64 `json:"averageScore"`
+	BestScore    int     `json:"bestScore"`
+
+	// CurrentStreak Current consecutive wins (games finished in first place)
+	CurrentStreak int `json:"currentStreak"`
+	GamesPlayed   int `json:"gamesPlayed"`
+
+	// IsGuest Guest user that has not been upgraded to a full account
+	IsGuest bool `json:"isGuest"`
+
+	// LongestAnswerStreak Longest run of consecutive correct answers by this user within one game
+	LongestAnswerStreak int `json:"longestAnswerStreak"`
+
+	// LongestStreak Longest run of consecutive wins (games finished in first place)
+	LongestStreak int `json:"longestStreak"`
+
+	// RejectedFormulas Formulas rejected as invalid, not equal to 10, or not found on the board. Conflicts with other players' moves are not counted
+	RejectedFormulas int    `json:"rejectedFormulas"`
+	UserId           int    `json:"userId"`
+	Username         string `json:"username"`
+
+	// Wins Games finished in first place (ties included). Single-player games are not counted
+	Wins int `json:"wins"`
+}
+
+// WebSocketTicket defines model for WebSocketTicket.
+type WebSocketTicket struct {
+	// ExpiresIn Seconds until the ticket expires
+	ExpiresIn int `json:"expires_in"`
+
+	// Ticket Single-use ticket for GET /ws?ticket=...
+	Ticket string `json:"ticket"`
+}
+
+// GetLeaderboardParams defines parameters for GetLeaderboard.
+type GetLeaderboardParams struct {
+	// Window Time window (daily = last 24h, weekly = last 7 days, monthly = last 30 days)
+	Window *GetLeaderboardParamsWindow `form:"window,omitempty" json:"window,omitempty"`
+
+	// Mode best = each user's best score only, all = every score
+	Mode  *GetLeaderboardParamsMode `form:"mode,omitempty" json:"mode,omitempty"`
+	Limit *int                      `form:"limit,omitempty" json:"limit,omitempty"`
+
+	// Cursor nextCursor from the previous page
+	Cursor *string `form:"cursor,omitempty" json:"cursor,omitempty"`
+}
+
+// GetLeaderboardParamsWindow defines parameters for GetLeaderboard.
+type GetLeaderboardParamsWindow string
+
+// GetLeaderboardParamsMode defines parameters for GetLeaderboard.
+type GetLeaderboardParamsMode string
+
+// GetRatingsParams defines parameters for GetRatings.
+type GetRatingsParams struct {
+	Limit  *int `form:"limit,omitempty" json:"limit,omitempty"`
+	Offset *int `form:"offset,omitempty" json:"offset,omitempty"`
+}
+
 // PostRoomsRoomIdActionsJSONBody defines parameters for PostRoomsRoomIdActions.
 type PostRoomsRoomIdActionsJSONBody struct {
 	Action PostRoomsRoomIdActionsJSONBodyAction `json:"action"`
@@ -75,6 +283,27 @@ type PostRoomsRoomIdFormulasJSONBody struct {
 	Version int    `json:"version"`
 }
 
+// PostAuthLoginJSONRequestBody defines body for PostAuthLogin for application/json ContentType.
+type PostAuthLoginJSONRequestBody = UserCreate
+
+// PostAuthLogoutJSONRequestBody defines body for PostAuthLogout for application/json ContentType.
+type PostAuthLogoutJSONRequestBody = RefreshTokenRequest
+
+// PostAuthOidcProviderCallbackJSONRequestBody defines body for PostAuthOidcProviderCallback for application/json ContentType.
+type PostAuthOidcProviderCallbackJSONRequestBody = OIDCCallbackRequest
+
+// PostAuthOidcProviderLinkJSONRequestBody defines body for PostAuthOidcProviderLink for application/json ContentType.
+type PostAuthOidcProviderLinkJSONRequestBody = OIDCCallbackRequest
+
+// PostAuthRefreshJSONRequestBody defines body for PostAuthRefresh for application/json ContentType.
+type PostAuthRefreshJSONRequestBody = RefreshTokenRequest
+
+// PostAuthRegisterJSONRequestBody defines body for PostAuthRegister for application/json ContentType.
+type PostAuthRegisterJSONRequestBody = UserCreate
+
+// PostAuthUpgradeJSONRequestBody defines body for PostAuthUpgrade for application/json ContentType.
+type PostAuthUpgradeJSONRequestBody = UserCreate
+
 // PostRoomsRoomIdActionsJSONRequestBody defines body for PostRoomsRoomIdActions for application/json ContentType.
 type PostRoomsRoomIdActionsJSONRequestBody PostRoomsRoomIdActionsJSONBody
 
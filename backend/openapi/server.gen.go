@@ -14,15 +14,55 @@ import (
 	"strings"
 
 	"github.com/getkin/kin-openapi/openapi3"
+	. "github.com/kaitoyama/kaitoyama-server-template/openapi/models"
 	"github.com/labstack/echo/v4"
 	"github.com/oapi-codegen/runtime"
 )
 
 // ServerInterface represents all server handlers.
 type ServerInterface interface {
+	// Start playing as a guest
+	// (POST /auth/guest)
+	PostAuthGuest(ctx echo.Context) error
+	// Log in with a username and password
+	// (POST /auth/login)
+	PostAuthLogin(ctx echo.Context) error
+	// Revoke the session of a refresh token
+	// (POST /auth/logout)
+	PostAuthLogout(ctx echo.Context) error
+	// List the external OAuth2/OIDC providers available for login
+	// (GET /auth/oidc/providers)
+	GetAuthOidcProviders(ctx echo.Context) error
+	// Start a login with an external OIDC provider
+	// (GET /auth/oidc/{provider}/authorize)
+	GetAuthOidcProviderAuthorize(ctx echo.Context, provider string) error
+	// Log in with the authorization code returned by the OIDC provider
+	// (POST /auth/oidc/{provider}/callback)
+	PostAuthOidcProviderCallback(ctx echo.Context, provider string) error
+	// Link an external OIDC account to the authenticated user
+	// (POST /auth/oidc/{provider}/link)
+	PostAuthOidcProviderLink(ctx echo.Context, provider string) error
+	// Exchange a refresh token for a new access token and refresh token
+	// (POST /auth/refresh)
+	PostAuthRefresh(ctx echo.Context) error
+	// Register a new user
+	// (POST /auth/register)
+	PostAuthRegister(ctx echo.Context) error
+	// Convert the authenticated guest into a full account
+	// (POST /auth/upgrade)
+	PostAuthUpgrade(ctx echo.Context) error
+	// Get the replay of a finished game
+	// (GET /games/{gameId}/replay)
+	GetGamesGameIdReplay(ctx echo.Context, gameId int) error
 	// Health check endpoint
 	// (GET /health)
 	GetHealth(ctx echo.Context) error
+	// Get the leaderboard
+	// (GET /leaderboard)
+	GetLeaderboard(ctx echo.Context, params GetLeaderboardParams) error
+	// Get the rating ranking
+	// (GET /ratings)
+	GetRatings(ctx echo.Context, params GetRatingsParams) error
 	// Get a list of rooms
 	// (GET /rooms)
 	GetRooms(ctx echo.Context) error
@@ -35,9 +75,18 @@ type ServerInterface interface {
 	// Get room results
 	// (GET /rooms/{roomId}/result)
 	GetRoomsRoomIdResult(ctx echo.Context, roomId int) error
-	// Register a new user or login existing user
+	// Register a new user
 	// (POST /users)
 	PostUsers(ctx echo.Context) error
+	// Get the authenticated user's profile and statistics
+	// (GET /users/me)
+	GetUsersMe(ctx echo.Context) error
+	// Get a user's statistics
+	// (GET /users/{userId}/stats)
+	GetUsersUserIdStats(ctx echo.Context, userId int) error
+	// Issue a single-use ticket for the WebSocket upgrade
+	// (POST /ws-ticket)
+	PostWsTicket(ctx echo.Context) error
 }
 
 // ServerInterfaceWrapper converts echo contexts to parameters.
@@ -45,6 +94,133 @@ type ServerInterfaceWrapper struct {
 	Handler ServerInterface
 }
 
+// PostAuthGuest converts echo context to params.
+func (w *ServerInterfaceWrapper) PostAuthGuest(ctx echo.Context) error {
+	var err error
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.PostAuthGuest(ctx)
+	return err
+}
+
+// PostAuthLogin converts echo context to params.
+func (w *ServerInterfaceWrapper) PostAuthLogin(ctx echo.Context) error {
+	var err error
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.PostAuthLogin(ctx)
+	return err
+}
+
+// PostAuthLogout converts echo context to params.
+func (w *ServerInterfaceWrapper) PostAuthLogout(ctx echo.Context) error {
+	var err error
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.PostAuthLogout(ctx)
+	return err
+}
+
+// GetAuthOidcProviders converts echo context to params.
+func (w *ServerInterfaceWrapper) GetAuthOidcProviders(ctx echo.Context) error {
+	var err error
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.GetAuthOidcProviders(ctx)
+	return err
+}
+
+// GetAuthOidcProviderAuthorize converts echo context to params.
+func (w *ServerInterfaceWrapper) GetAuthOidcProviderAuthorize(ctx echo.Context) error {
+	var err error
+	// ------------- Path parameter "provider" -------------
+	var provider string
+
+	err = runtime.BindStyledParameterWithOptions("simple", "provider", ctx.Param("provider"), &provider, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter provider: %s", err))
+	}
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.GetAuthOidcProviderAuthorize(ctx, provider)
+	return err
+}
+
+// PostAuthOidcProviderCallback converts echo context to params.
+func (w *ServerInterfaceWrapper) PostAuthOidcProviderCallback(ctx echo.Context) error {
+	var err error
+	// ------------- Path parameter "provider" -------------
+	var provider string
+
+	err = runtime.BindStyledParameterWithOptions("simple", "provider", ctx.Param("provider"), &provider, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter provider: %s", err))
+	}
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.PostAuthOidcProviderCallback(ctx, provider)
+	return err
+}
+
+// PostAuthOidcProviderLink converts echo context to params.
+func (w *ServerInterfaceWrapper) PostAuthOidcProviderLink(ctx echo.Context) error {
+	var err error
+	// ------------- Path parameter "provider" -------------
+	var provider string
+
+	err = runtime.BindStyledParameterWithOptions("simple", "provider", ctx.Param("provider"), &provider, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter provider: %s", err))
+	}
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.PostAuthOidcProviderLink(ctx, provider)
+	return err
+}
+
+// PostAuthRefresh converts echo context to params.
+func (w *ServerInterfaceWrapper) PostAuthRefresh(ctx echo.Context) error {
+	var err error
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.PostAuthRefresh(ctx)
+	return err
+}
+
+// PostAuthRegister converts echo context to params.
+func (w *ServerInterfaceWrapper) PostAuthRegister(ctx echo.Context) error {
+	var err error
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.PostAuthRegister(ctx)
+	return err
+}
+
+// PostAuthUpgrade converts echo context to params.
+func (w *ServerInterfaceWrapper) PostAuthUpgrade(ctx echo.Context) error {
+	var err error
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.PostAuthUpgrade(ctx)
+	return err
+}
+
+// GetGamesGameIdReplay converts echo context to params.
+func (w *ServerInterfaceWrapper) GetGamesGameIdReplay(ctx echo.Context) error {
+	var err error
+	// ------------- Path parameter "gameId" -------------
+	var gameId int
+
+	err = runtime.BindStyledParameterWithOptions("simple", "gameId", ctx.Param("gameId"), &gameId, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter gameId: %s", err))
+	}
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.GetGamesGameIdReplay(ctx, gameId)
+	return err
+}
+
 // GetHealth converts echo context to params.
 func (w *ServerInterfaceWrapper) GetHealth(ctx echo.Context) error {
 	var err error
@@ -54,6 +230,70 @@ func (w *ServerInterfaceWrapper) GetHealth(ctx echo.Context) error {
 	return err
 }
 
+// GetLeaderboard converts echo context to params.
+func (w *ServerInterfaceWrapper) GetLeaderboard(ctx echo.Context) error {
+	var err error
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params GetLeaderboardParams
+	// ------------- Optional query parameter "window" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "window", ctx.QueryParams(), &params.Window)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter window: %s", err))
+	}
+
+	// ------------- Optional query parameter "mode" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "mode", ctx.QueryParams(), &params.Mode)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter mode: %s", err))
+	}
+
+	// ------------- Optional query parameter "limit" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "limit", ctx.QueryParams(), &params.Limit)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter limit: %s", err))
+	}
+
+	// ------------- Optional query parameter "cursor" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "cursor", ctx.QueryParams(), &params.Cursor)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter cursor: %s", err))
+	}
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.GetLeaderboard(ctx, params)
+	return err
+}
+
+// GetRatings converts echo context to params.
+func (w *ServerInterfaceWrapper) GetRatings(ctx echo.Context) error {
+	var err error
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params GetRatingsParams
+	// ------------- Optional query parameter "limit" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "limit", ctx.QueryParams(), &params.Limit)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter limit: %s", err))
+	}
+
+	// ------------- Optional query parameter "offset" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "offset", ctx.QueryParams(), &params.Offset)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter offset: %s", err))
+	}
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.GetRatings(ctx, params)
+	return err
+}
+
 // GetRooms converts echo context to params.
 func (w *ServerInterfaceWrapper) GetRooms(ctx echo.Context) error {
 	var err error
@@ -120,6 +360,40 @@ func (w *ServerInterfaceWrapper) PostUsers(ctx echo.Context) error {
 	return err
 }
 
+// GetUsersMe converts echo context to params.
+func (w *ServerInterfaceWrapper) GetUsersMe(ctx echo.Context) error {
+	var err error
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.GetUsersMe(ctx)
+	return err
+}
+
+// GetUsersUserIdStats converts echo context to params.
+func (w *ServerInterfaceWrapper) GetUsersUserIdStats(ctx echo.Context) error {
+	var err error
+	// ------------- Path parameter "userId" -------------
+	var userId int
+
+	err = runtime.BindStyledParameterWithOptions("simple", "userId", ctx.Param("userId"), &userId, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter userId: %s", err))
+	}
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.GetUsersUserIdStats(ctx, userId)
+	return err
+}
+
+// PostWsTicket converts echo context to params.
+func (w *ServerInterfaceWrapper) PostWsTicket(ctx echo.Context) error {
+	var err error
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.PostWsTicket(ctx)
+	return err
+}
+
 // This is a simple interface which specifies echo.Route addition functions which
 // are present on both echo.Echo and echo.Group, since we want to allow using
 // either of them for path registration
@@ -148,42 +422,124 @@ func RegisterHandlersWithBaseURL(router EchoRouter, si ServerInterface, baseURL
 		Handler: si,
 	}
 
+	router.POST(baseURL+"/auth/guest", wrapper.PostAuthGuest)
+	router.POST(baseURL+"/auth/login", wrapper.PostAuthLogin)
+	router.POST(baseURL+"/auth/logout", wrapper.PostAuthLogout)
+	router.GET(baseURL+"/auth/oidc/providers", wrapper.GetAuthOidcProviders)
+	router.GET(baseURL+"/auth/oidc/:provider/authorize", wrapper.GetAuthOidcProviderAuthorize)
+	router.POST(baseURL+"/auth/oidc/:provider/callback", wrapper.PostAuthOidcProviderCallback)
+	router.POST(baseURL+"/auth/oidc/:provider/link", wrapper.PostAuthOidcProviderLink)
+	router.POST(baseURL+"/auth/refresh", wrapper.PostAuthRefresh)
+	router.POST(baseURL+"/auth/register", wrapper.PostAuthRegister)
+	router.POST(baseURL+"/auth/upgrade", wrapper.PostAuthUpgrade)
+	router.GET(baseURL+"/games/:gameId/replay", wrapper.GetGamesGameIdReplay)
 	router.GET(baseURL+"/health", wrapper.GetHealth)
+	router.GET(baseURL+"/leaderboard", wrapper.GetLeaderboard)
+	router.GET(baseURL+"/ratings", wrapper.GetRatings)
 	router.GET(baseURL+"/rooms", wrapper.GetRooms)
 	router.POST(baseURL+"/rooms/:roomId/actions", wrapper.PostRoomsRoomIdActions)
 	router.POST(baseURL+"/rooms/:roomId/formulas", wrapper.PostRoomsRoomIdFormulas)
 	router.GET(baseURL+"/rooms/:roomId/result", wrapper.GetRoomsRoomIdResult)
 	router.POST(baseURL+"/users", wrapper.PostUsers)
+	router.GET(baseURL+"/users/me", wrapper.GetUsersMe)
+	router.GET(baseURL+"/users/:userId/stats", wrapper.GetUsersUserIdStats)
+	router.POST(baseURL+"/ws-ticket", wrapper.PostWsTicket)
 
 }
 
 // Base64 encoded, gzipped, json marshaled Swagger object
 var swaggerSpec = []string{
 
-	"H4sIAAAAAAAC/8xY0W7bNhd+FYL/f9FummU5aYH6znXTTlkQB0q8rSiCgJaOLTYSqZFUE6/wzfIGA3a3",
-	"q12uG7AH2NsEW19jICnZkiWnToIFu4kd+fCcw3O+7+Oh3uOQpxlnwJTE/fdYhjGkxHwd5CoOQGacSdD/",
-	"Z4JnIBQF86vi58D0lwhkKGimKGe4j/e/OUEkDEFKZC0cDJckzRLAfQzz/XjyKqQjuu+Pv/e9Q+pLnwVP",
-	"wqH/1D/Pvv16uP+s0+lgB6t5phdIJSib4YWDcwmimQSN9N9lAG+5kDIFMxDlSkZSqFliBVIZn41YCwcL",
-	"+C6nAiLcf6NDVHycLs355C2EqmFebtr4blo7+DknImruJORMAVO1JN94Ts/ZcXadDZ+nDqYKUtuOxr5T",
-	"cunbX72nDk4pq/xXWBMhyFzbzghlxyEX9SJ53bZ6vgMhTa/XW38SA2JwgQoDxKdIxYAmesNIKqIAN/2t",
-	"la8swypMNbm2egacpy3AkKMMGNThoUQOSw8TzhMgTLsQnKf+FkjSdocNJOkEkLcJszabskv/FzDFffw/",
-	"d0U6t2CcO5Y2Sr03a/UpUq3kUoZxVnveVKYAZJ4oDYNmwWSz+73uJjo1e78kiHMXjhVGcmOTx+3slwGQ",
-	"aF7LekoS2drkdhnIEjIH4W2VYbG/MuqmPIcCNNQb2WZEygtuuV8v3lFCKEMKLhVaGlXrWD70ejubYHYP",
-	"gatsbRm9ReYWDqZsyo3WUGVCxPKs9+Ss1z2TIN6BQIMjv0LcPvY63U5XZ8gzYCSjuI93zCMdScWmKm4M",
-	"JFGx/joDI3+6ZkRXRlMSvwL1pbXQWdvDyCzsdbtrukmyLKGhWeq+lVahLLla4K6IymW9Yvy8tVYthai3",
-	"b/SVsZN5mhIxx31sE0ZhDOE5AhZlnDJ7UriatvKmzQbG4J573UpvjHA29aaxvQFKqFRazW3yCwc/sfnU",
-	"7XymNJASVKABhOBirTKvQCGy7m9ZF/e9lbeFS0Lt1NKGy5ZKHXFpSxWYFYPCXgNLkBSUEd43jRRflIeS",
-	"DoQURxmIKRcpIgzZmMgcOZQZ2hnUWWpVlHfJHXuerMp+0+mxOLUrQarn3GrWHaFr8zTxWJ5qBu+P/EPs",
-	"4GBv8OI1dvBwcDjcO8AOPj4ZBCfYwYPnI/M5PBgd750Fe8fjgxNN8RX0Cw83C0URuF0b6lVZNAC82wSM",
-	"bRqSuRkXp3miobXbDq13JKERKgpo7Xaadi+5mNAoAoYeQWfW0d1NqTTDSASMQoSmXCBTlsfWybOmkyFn",
-	"04SGCj3SA02BipAwxhWaQIkYiJDW7BhQmAsBTNkJ5/G96HHUgkZEDFhbaaKN84Rsz5OX5YJbE0Xmk5Rq",
-	"8hYxkeL/cZ4UidY13vu899nOF7ttx2hlrr0xwTopVmNqGe9u/LidwN+k6/aC0SLkRfNtKy0rLois8K+z",
-	"LQELetHiaQkJ/UnU463pqScPRCXSzKIl0LclZuVagWIi0QSAoTyLiIIITeaIMK5iECbI/Vh5vA59LSJV",
-	"5m/kpzAD9yePe8tNO53fnpkzUMgGkmgqePov0vIhRpLKJeVWw4kBk7lByGWDRDHibINGx3poYvFew45p",
-	"UtEdC5HlpXCzYI+LC91ddfBTt8zihvLAulR7ndTSSp0ZSvhsZo/WlSwl5hVFr+s9bCqhqVLUSGT7CcXb",
-	"bBcKiIApShKLVrikUlE2MyC8F+oCmFGpQCBiXsYYUHNTWMrWw5iVxlWb1Fxf/X599ef1Dx+urz58/PGP",
-	"v365wg7ORaJvfkplfdf1ugpYRwmSdWTML1x9x1s4637+/vm3jz/92uJB9l339WgcnB0Foxfj4Yk/Ojwb",
-	"Bwd4cbr4JwAA//8XVLaalxQAAA==",
+	"H4sIAAAAAAAC/+R8+3PURp74v9Kl77cqcBH2+AEBV6WuHOPzmnUw50dyeyxlt6WemV5ruofulo2Xosqa",
+	"yWYhQIWwCYRLcuzmEuDCxk42qWxykOSPETbhp/wLV/2QRhr1jMcYSPbyC9hSq/vTn/fTZx2P1uqUICK4",
+	"M3LW4V4V1aD6cTQU1RnE65RwJH+vM1pHTGCk3qIzdcwQX8BE/uYj7jFcF5gSZ8SZwmUkcA0BWgaiigD0",
+	"PMQ5EHQZEYAJ4MijxOeO66AzsFYPkDNypFRyHbFWR86Ig4lAFcScc67DUJkhXl1QnxYPmsWkEqADIUfA",
+	"rDSHlCkDJ6Zn50A/DEW137zMHuicHjp2plQb9I4szR7+dzaw/Er5N6v/+gKZGq7PHQp/dRCNlvyJQfzr",
+	"w/Rl7qSgccEwqUjIOkB07NW53HVzR6K1Y9WlCQ9P42OT87+fHDiOJ/kkmTnojU0emlyu/9srY8eO9PX1",
+	"2Y4LOWJFImBfk8IcMGBDofySwBrKrXQE4kLtWThLYf10iBnynZGT8ojMHqfS5XTpd8gTheXJpfOEc7Ps",
+	"Yi5T3Mp1XqKQ+cVrepQIRETuBicH3EF3yB12O/x/ynWwQDX1fREpNXhmUr8dOOQ6NUwyv5nVkDG4JtdW",
+	"ICazHmV5DA5Y+XUFMY6phS/mqggQtArMgkQyluSFARdQIKe4XxtuEzS0jskCZ8PnBKyhGVQP4JpFgImP",
+	"/FGF1DJlNSicEceHAh2QkmtjwQqsockeGA4TLDAMUlrmEaEeA3MVAAWQ20oMMAH2Mbp6oAZ/R9l+pyv5",
+	"2klUoytGIJJv/j9DZWfE+X/9LfXWb3RbfwsrL9MVZNtPvkPscXY8ob607ckorfWCPo6QnyMKJuLQsGNd",
+	"KrG2GyK2MZShaAqbOTy7sZvySRthW0hK0N+dARWqC0y41BOTlAViSlzkQY/PJV4Vksou+ORlKLwq8mdQ",
+	"RUJl2RCdqTPEE4FvKdeBwaHh559/3i5EmCC/qE4GS3ZeON0Dw4RLNSzE7oQ5/eiVThpL0yDRVxL7XoAl",
+	"OdJPAZS34cJxd4BQ6vteWH+lN1ja+GEVcgDr9QAr1m1hdEd9KrGbApejpgU/WaWbMFKenHlKuIa3u0uF",
+	"URcFuWCQLPdA+aJVsvNRrwTYo6+QojLdJ4HR1TeyIWMKQR+xJbvlR0QwvAuRzWw2TgRbsxoLtItdZiTY",
+	"8iPqt2FlCSnOL0gWQWfEWMg4ZUU+1s+VdyqUQ3BGgDqsoD4wbUTKiFoAuX5jO2EVE5+u5qFZRWg5WNuR",
+	"QuZTcx83xe8OhNG4LFAHelWMVhLFk7/qq1Wkr6IYQAkpQx5lPvLBPnn6izAIACXBmtTivSmtf0ypUFC7",
+	"XYRjB+TPmFvvoCGGB3tDxuHSjorRQNwZurxlLIYlxEdncoda8R9ggubU08xKhyn+LNC+TjmWrMWLrHaS",
+	"0VUXeDQ4Jd1qBL0q8FAQZI3ByZMlt3TKPVlyB9S/g+rfoVPZKGEXnkT+9zbspddyDSKywNuwOT15dEwG",
+	"25Th30NhxSjMvl4IWVDEwvzMVBJV1BldwT5iz3GQ+xAg4tcpJgIICghcwRUoEBDU6iComKRwyiswCJE6",
+	"xINBsAS9ZVALuQAMiZAREBJtG/0dpaJ4o+TMTigaMwfOoNMh4sIWJvoWiHOYBXINKDNaU3dgyMcMeWIh",
+	"ZBicDhFb2wUqZuXj3e1VCOiUEu5+7ROGmLx44Xr2VYvXHcHgaccSALeu1JWBW9vaYJqBApNKB4vgoxWc",
+	"snAeXfo7kK7oA7M1GASIgRqChGsc6jWYg5o0Gh5iAuJcDuXwwb7BrLmg4VKQsRUkrC1pkZWxDVfulSW+",
+	"OK6WSXFhUFpdtTjnxg5bs1E92R99ify6Q4NDfcM9wf3TGyUDv5shZh6dVq7Q+Z45me7pKKCFbN4OMOaW",
+	"W0+ltFY8BvPpOiIoj0TBQpTusERpgCDZTVgu1x0v4FsCAAY6Jex691vnuc3QtOMjidJTWJJj3NadO6Fp",
+	"BvEwEDLNVUTYrjymojRleOcxg4auzsa8PfXJZxD013JQl2HArUS2C4tOYAz0BKG5X3JqJzjHGDK2ok1T",
+	"Q85XqS3VcSKAmAChIwGzKIvH5OHA4FAnNttjxGaulp7e6W7S3nG7GcRcYI8DHtZqkOHfI18m9qRCR8RP",
+	"fbKKOacthPBQXSD/XyirhQHMW7KBYSsXQs8LGfTWirC07wb6wb7Cs+cBQ/JerUf7XVACqzJgIVRUpQWS",
+	"4UoazWfpUeo73JMahyuIwQoqJnqOHOo72NMOMr60pJ0PW1HihYwhImYFQ3DZGnfK1zKjxpEXCizTJphw",
+	"sE/pdVDGBPMq8mVxpoyZjD0D6KH9O2Z22qxsRn/YVmM+kRiGPHzqMZDMCEQVClCFXJICLCFEQFivMCjD",
+	"RkEBBOUwCGSFhYYkl3jqKPsBlbkaMUr4KmKdEDSlFwEWqtR8Fk8eZQx5AkC1AQdLa0BUMdfQrmLJL4AS",
+	"lLB3CtAha8ijz3kMMHZLriF7OS3P+EUIkjepjADIASYrMMC+q2iCTocwkLQYKLmAMvWsTEOS5i5U0NoH",
+	"xigpB9gTXCEJUFFFDJic8XMqc8cBZEh9r6iZF7Shg88yZFdJFQs6JrrhG+yTWgxg4gWhj/z9fcDUI/Ut",
+	"tVfZ7Y4Hd4zEbf5ZIkV56TM3yOqNNi3UriXaudEuKm5RRVv4KKOUbebjVbQ0S71lJOaw/Hd31eRZXS0G",
+	"IRE4UBwm1C7AfJTjGqt2FOmpHcvHZkuZmZsYnwP9q/yf9aMXrQXZNjKZA3JlTkupVBXHylQCIrBQLFnl",
+	"C4MHFwZLCxyxFcTA6InJTKp5xBnoK/WV5B1oHRFYx86IM6QeSYMtqgp5usZdSX1ualOxi+r9gUUQR7e3",
+	"bl+Mo+/ixsWtK5e3LlyOo424+Vbc+CZubsbN+3HzQty4un0piqObcfRJHL0WN2/J542v1L9XH3z7fhz9",
+	"Vxzdipufxo3P4sZHcfOLOLoRNy7G643fksWJ+fHZuYW5uanFH++f377+4dbGf7ywff3jH+9feHDv463X",
+	"/xBHd8GihtoodwXW9vt/fXjj3qNLf4uj6+rgi+qYm3Hjbwq483G0uX3+ysOvv4ujt+PoztaFNx7d+CiO",
+	"3okbl5KzHYUppsIWqSicE5QLmQJIZIaZjgaFucHSQFt9WRUSPPV5/++4jmO1k76TC59rl1C07mjjPOUl",
+	"+gASHwS0UlGaRRL5YKlUpNwkEVL2A2BYBDFGtabQ/taadsKY0kpr0nuRBRGg+UEu05gOaAWTLH/Y8TSl",
+	"lmn2Rly8RP21J4aijId8Li9CgoXoXIE4pWdGnKksFYbtVFA2EBi86HUDljQcWSZ0lYBEYUsTucooqbTc",
+	"+70QeopWpBVSJhW2DpGc1No/S3MadlEKceNe3GzGjb/HzdtKhjfi5idx8524+dfk+cdS7qTYb6oFd+L1",
+	"KI4+kCvbv7398Mb//PCXS0p4b8aND+UnjXtGctMdGle3Pvp8641vpNhG78WNix0ldkoD/3RY0Zav6Ikn",
+	"h21em2IeGgqwDwacKisSGj6gDMCAyaARMLRCl5Ufu4wI378bVntsfplRZ+pCjC5uSscS5tulMhxDse/1",
+	"5/KKFWTRFhNIkWga+14rP/kU5TefCLUIcPISEJXKaxMazE04esZgbFpCP6i2TbPlHMAViAO4FCBFQa0w",
+	"21BzNll9rj/JYKMMlvJA/fDJ5a03N+PGF8aqSsH6NG7e//H++RO/Hhv/8f6FONrQi+ZnpoxwmdV34+YV",
+	"JV+bUrL+cHvrynltYlW2OG5c/eH7t+UDafSUaP23tMOND6WMKUuZrHvw/Qdbn76rpPKWNKmNC5kT7z6K",
+	"/v7w9j35dj1azKawtaPw5vU4emvrzWvaEOsto0sPvl7/4Y9fanC2/vylAu3PcfSWR3154qP1qKM5tjDO",
+	"aIpH6dMwWENCsd5JWUhSGRBRdVxHu/NpctppF1U3w0rt3tqpp8ya+eqNhT1zC8D8zJQW/uHO9iO9p5L+",
+	"QXtHWbJIBheBb0JmwGS6BflWFwFqtjYGhGQkIisKXbg+Kft0NipbH1171LyjDMDduHErYci7D7+88uDe",
+	"u9KdjG7mPMrottWR3Dr/wdZ7/xlHmx023Ei819vb1z5T/G1zVA0jJmsvPfj2+7jxpvQYpTi8luHgzeHS",
+	"kR/vn293hduha8kRWLSjKMBkWYlPcuO34sZFKexdHdSsSCSlrqcsEU/erNrqdP/orp4LWubclS6XLx/o",
+	"SC/p3ezkDeaENE2pqPIp9ZVvKH+ePJr0Rqe5ll3ph+HSke5HP8dbriLmQEB5mMndwCCgq8h/Ynom66HK",
+	"y8Fi/VUXi5Gvc2moZ90jBWvnCCYrSFOY/IKEyOabYiJdTu2XqhR34o0G+o2grXTm/mchEsclz4WiioiQ",
+	"6EG+C8RPKyWpDTRpZXlACzuQ6NSlRJCbwCJ/ATgTZj8p4cFkuWiWE7gETSUqxZ4CJSMzyahD55hvhwhv",
+	"c0BZXenPSVsZnY/XI/XD6yof9EUc3c3Y2+yXdx9+uxFHl7fffE9ZWG14H3z7/cO372x/fT6OvlfxZfaL",
+	"S1uvX46jLzI2+WZqjTsHmhttAaX0GKJv4uhWW2TZ3d7OpDMhP6v48tkZwuNo1YSiAHMeIv9J5T7cRBO4",
+	"achLpVwrPdEWeO4htB0/o3uN2qNZFbxBNW2RGzySOZJOYS9DFcwFYt3EpuVabl25HEebQ/H6+0OD29f+",
+	"uPXpdRnHXfx8+53P5M/Ne4sLi/LfA4s6PGv3KDcPx+vvvzAoIzzpVJ6Po9s/XPxcbXPH7NG4unXlrhSZ",
+	"9aj9ZPPqO5Objf6kAsfurG5u9/NI6z27nOt812xrz5Yu0fhJPi/JtIEVTAMokG5eqtMAe2sdLc18skFi",
+	"gJUXtsfkjqar4fY2S2BS672x9ORRFWhdUHr4QsJaN+NGpHISaQJ+49G1P8XRhknDN1V6r/GFjM2iza37",
+	"78TR5Ydf3YijS5mUfDv/Nu8VBGJj+4O/PLj3VRxtgsW8QMo46s7WlUtx9G683sgCUjALm4n6l7kMu4nK",
+	"ZU06isu8QdwvLQk+nxTac5LS8uQTHlMu89OVn178xl1KWsF3U8FP1n97TCkco2QFMWFxzdTeAJNi44KS",
+	"UlW+7T+rp7DO9bN0UM+aTJSupJnA0iV2F8wcnwAcGYIlZVpQNkXZpBtY7t/3WzK+gtianpgxFWueGUHU",
+	"MzVYZKdpXNWxT7hgoZeZTTCoSBtfJQR99lSfKp9PqPuZMcReYrHWWFrHSKxb9f+pZvsyE5UWCZoz6AaG",
+	"lp3CE7lLq3FCcSYEAapAb01/X8MV3ZmqsKx64zLzE0uoTFl6kuzr42AVMQQ4XJHBHqEGgP17YuwJJExn",
+	"s9xL1w3SVoiKUgKSjasIBqLarVTwK71ij2Rp61kUUIT5djGHLlsr9ZYyfB4Z079uu7kGGHhV5C2nTfP6",
+	"tkF+XMkqqrOGXlDFkJgA+RoRX9ZmJQVZH5jDMlDW63gVMlOiURSFZLnvt2TO9Nir7I2MZeVzqbfS7Akm",
+	"YLGGFgFaQUTH9ljFrjQUHPvI0M4sVmNGdinNTmAV5LONwXFNtSL5dBXs8yEO1sCLelhpcLjqAj2FlDx6",
+	"AfhwjbugRomoth4PldRzNbwp90x65Y30pyNKLcr7qAzDQDgjDtRjHSSsqRkC9ZvZ3nFbQ1AKMueUhRna",
+	"LyS7ZcCLukFRGobnOFCPFGnUeJIrM2RyidKfyTibDXIzU2WD2wyLJYCbXyX8diBt2we4hoV9f9mtW4Nn",
+	"cE1uPyD/lEINE/ObrceoHQutmbWWWq8ztIJpyJMxNBtInvrG+alKL1nGtYk1QQr4xBBmRXcn90VdFKSy",
+	"8ET0aO58qUt0pz3vqEekI8O1xtDJ0okAe8vUjEn0AT1SoVvMwrrfMhjytDKWYKlGtZrEmsSC7kjTDt0q",
+	"lWZHzVmYrrwO6sGc0sF0986kB3fNpLYDaLnMUYcTsluWnoJj0FMrf3Y+ptjRb3UZND2VgjfdiM+cN9tB",
+	"UOxJaa1rC8CMWvBMkEpprRdsjoJA1vrlYI+Cba+oge37pXjpP6vHMc71Qy+dS+xcmFComlFfjJr1O5ja",
+	"yaOJ3pIHydxvHTHp28v8sD4TqNEciwfdmhTZgwf9eKFve3t/MgyWmL5j05PHHdeZGR89+hvHdcZGj4+N",
+	"TzmuMzs3OjPnuM7oS9Pq/7Gp6dnxhZnx2fkp+euJ0fnZcfXd7PzL49JqphdIttxh3lBDYu/KfIzCiqYi",
+	"4KFKMZbDYHfZ0yFr6/US9n1EwD7UV+mT5K5h3bLjI4J1ZAcUnlyg8CEVuEbI/o7BcNKGDfbNqb/EpKD2",
+	"IDFlCMNT2ptUFRczKqCKOHuLH05Y+FUma5UsWwSpnOlK70mSMu3HuxQlPdsh4xm9hR6C/TlLkgG07Q+N",
+	"PD/4T0MHhm397Jm/pdEVwLyUJF+56XmPJzBPzsd7qZN3Z4ivSanFRE/tJALZ16tEGnkzNcWUJcpqRmd/",
+	"z/KazSvhhNF7FczMH4JS0y9m8kX7dEtr9ipkxdTz61CWVvYmrLPtEpH8kYpEIXQUW52B2NFP0CKrxxB3",
+	"L7AVJNJUh3Rxn6K0PgtfJjONuSuvRvGYSRskBGLGN+qFSV29Q5FF9+QlKSIZ6mgWSadfW5WHOkM6bWtI",
+	"0jaeUEz7b2xfvy3rBeuNZCpgQxbEGldVk/FrMsl//UPV2rhRKM7d1e1cacJf9XVlu7g2kxmD15LGrLb3",
+	"SSFCtcstAtVNporgHUsH8zxpgv3FlthayjdY+79dYZM/8f4a6qb2FEO8jJ5mX3RrLrdDaFnsFXmOA56O",
+	"7D6R2NF6Rp3RMg70ZEL2vBbyzurhtnP9PBks7orIebVaX7aXKkI6OvczrCLsSDYroeydTok+V6WEJxDw",
+	"Wo6WNFvlB1pjdJ3qyddUG/otqYijjXToTw61xdFmrgc6br4rq7fNddOpa9pkL6gmoFzxWc6TFSbyVHut",
+	"VsgPb366faMhy9LNe7J16dGNK3HjDWUUorjxuTrlfNy4mkyn3OjaGfQqn0tm+Z4a9dunIW08oN7k+nJ6",
+	"LYk+Nvkn5WEAAm6dipSC3qJo0lugQNd72vw4Pe4g27abdx9e/Wzrw6bjOuqvFzlVIeoj/f0DJYFIn2Cw",
+	"3serdLUf1rFTzE/LycB3PrHswEf6+38zPT+zcGJm+uj82Nzk9PEF1V1/6tz/DgDIdXwk2lgAAA==",
 }
 
 // GetSwagger returns the content of the embedded swagger specification file
@@ -1,12 +1,13 @@
 package main
 
 import (
-	"database/sql"
+	"context"
 	_ "embed"
+	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/getkin/kin-openapi/openapi3"
-	"github.com/kaitoyama/kaitoyama-server-template/internal/db"
 	"github.com/kaitoyama/kaitoyama-server-template/internal/infrastructure/auth"
 	"github.com/kaitoyama/kaitoyama-server-template/internal/infrastructure/config"
 	dbInfra "github.com/kaitoyama/kaitoyama-server-template/internal/infrastructure/db"
@@ -24,7 +25,8 @@ var swaggerFile []byte
 //go:embed logo-ogp.png
 var logoOGPFile []byte
 
-func SetupRouter(database *sql.DB) *echo.Echo {
+// SetupRouter builds the server. 返り値の関数はサーバーの停止後に呼び、書き込み待ちの提出記録・roomの状態を保存して終了する
+func SetupRouter(database *dbInfra.Database) (*echo.Echo, func()) {
 	// Load configuration for JWT secret
 	cfg := config.LoadConfig()
 
@@ -57,32 +59,105 @@ func SetupRouter(database *sql.DB) *echo.Echo {
 	}
 
 	// Initialize database queries
-	queries := db.New(database)
+	queries := database.Queries(database)
 
 	// Initialize services
-	jwtService := auth.NewJWTService(cfg.JWTSecret)
-	userUsecase := usecase.NewUserUsecase(queries)
-	authService := auth.NewAuthService(jwtService, userUsecase)
-	roomUsecase := usecase.NewRoomUsecase()
+	jwtService := auth.NewJWTService(cfg.JWTSecret, cfg.AccessTokenTTL)
+	sessions := auth.NewSessionService(jwtService, queries, cfg.RefreshTokenTTL)
+	// 定期的な削除処理はSetupRouterが返すclose関数で止める（データベースを閉じる前）
+	cleanupCtx, stopCleanup := context.WithCancel(context.Background())
+	// 期限切れのリフレッシュトークンを定期的に削除
+	sessions.StartCleanup(cleanupCtx, time.Hour)
+	userUsecase := usecase.NewUserUsecase(queries, dbInfra.NewUserStatsRepository(database), cfg.GuestTTL)
+	// 本登録されないまま期限が過ぎたゲストを定期的に削除
+	userUsecase.StartGuestCleanup(cleanupCtx, time.Hour)
+	leaderboardUsecase := usecase.NewLeaderboardUsecase(queries)
+	ratingUsecase := usecase.NewRatingUsecase(queries)
+	authService := auth.NewAuthService(jwtService, userUsecase, sessions, auth.NewTicketStore(30*time.Second))
+	gameResults := dbInfra.NewGameResultRepository(database)
+	roomUsecase := usecase.NewRoomUsecase(gameResults)
+	gameUsecase := usecase.NewGameUsecase(gameResults)
 	wsManagerInstance := wsManager.NewManager()
 
 	// WebSocketマネージャーにRoomUsecaseを設定（突然切断対応）
 	wsManagerInstance.SetRoomUsecase(roomUsecase)
+	wsManagerInstance.SetSingleSessionPolicy(cfg.WSSingleSession)
+	// roomの変化をロビー（room未参加のクライアント）へ通知
+	roomUsecase.SetRoomChangeListener(wsManagerInstance)
+	// 一時停止・再開（自動一時停止を含む）をroom参加者へ通知
+	roomUsecase.SetGamePauseListener(wsManagerInstance)
+	// 数式の提出をすべてformula_submissionへ非同期に記録
+	submissionLog := dbInfra.NewSubmissionLog(database, 1024)
+	roomUsecase.SetSubmissionLogger(submissionLog)
+	// roomの変化をすべてroom_snapshotへ保存し、再起動時に前回の状態を復元
+	roomStore := dbInfra.NewRoomSnapshotStore(database, cfg.InstanceID)
+	roomUsecase.SetRoomStore(roomStore)
+	if err := roomUsecase.RestoreRooms(context.Background()); err != nil {
+		log.Error().Err(err).Msg("Failed to restore rooms, starting with empty rooms")
+	}
+	// 復元したroomのプレイヤーは再接続を待ち、タイムアウトしたらroomから削除する
+	wsManagerInstance.RestoreDisconnectedPlayers(roomUsecase.GetDisconnectedPlayers())
+
+	// 複数インスタンス構成ではバックプレーン経由でroom・ユーザー宛てイベントを共有
+	if cfg.WSBackplaneBrokerListen != "" {
+		if _, err := wsManager.StartBroker(cfg.WSBackplaneBrokerListen); err != nil {
+			log.Fatal().Err(err).Msg("Failed to start WebSocket backplane broker")
+		}
+	}
+	if cfg.WSBackplaneAddr != "" {
+		backplane, err := wsManager.DialBackplane(cfg.WSBackplaneAddr)
+		if err != nil {
+			log.Fatal().Err(err).Msg("Failed to connect to WebSocket backplane")
+		}
+		wsManagerInstance.SetBackplane(backplane)
+	}
 
-	wsHandler := handler.NewWebSocketHandler(wsManagerInstance, roomUsecase, userUsecase)
+	dbChecker := dbInfra.NewDBHealthChecker(database.DB)
+	// 外部のOAuth2/OIDCプロバイダー（OIDC_PROVIDERSで設定したもの）
+	oidcProviders := make([]auth.OIDCProviderConfig, 0, len(cfg.OIDCProviders))
+	for _, provider := range cfg.OIDCProviders {
+		oidcProviders = append(oidcProviders, auth.OIDCProviderConfig{
+			Name:           provider.Name,
+			Issuer:         provider.Issuer,
+			ClientID:       provider.ClientID,
+			ClientSecret:   provider.ClientSecret,
+			RedirectURL:    provider.RedirectURL,
+			Scopes:         provider.Scopes,
+			UsernameClaim:  provider.UsernameClaim,
+			LinkByUsername: provider.LinkByUsername,
+		})
+	}
+	oidcService := auth.NewOIDCService(oidcProviders)
+
+	apiHandler := handler.NewHandler(dbChecker, wsManagerInstance, roomUsecase, userUsecase, leaderboardUsecase, gameUsecase, ratingUsecase, sessions, authService, oidcService)
+	apiHandler.WebSocketHandler.SetAllowedOrigins(cfg.WSAllowedOrigins)
+	// 復元した進行中・一時停止中のゲームのタイマーを再開
+	apiHandler.RestartGameTimers()
 
 	// WebSocket endpoint (outside of API group to avoid OpenAPI validation)
-	e.GET("/api/ws", wsHandler.HandleWebSocket)
+	// クライアントからのアクションをREST APIと同じ処理で実行するため、apiHandlerのWebSocketHandlerを使用
+	e.GET("/api/ws", apiHandler.WebSocketHandler.HandleWebSocket)
+	// WebSocketイベントのJSON Schema（フロントエンドの型生成用）
+	e.GET("/api/ws/schema", apiHandler.WebSocketHandler.GetEventSchema)
 
 	// Setup API routes
 	api := e.Group("/api")
 
-	dbChecker := dbInfra.NewDBHealthChecker(database)
-	apiHandler := handler.NewHandler(dbChecker, wsManagerInstance, roomUsecase, userUsecase, jwtService)
-
 	// 認証不要エンドポイント
 	api.GET("/health", apiHandler.GetHealth)
 	api.POST("/users", apiHandler.PostUsers)
+	api.POST("/auth/register", apiHandler.PostAuthRegister)
+	api.POST("/auth/login", apiHandler.PostAuthLogin)
+	api.POST("/auth/guest", apiHandler.PostAuthGuest)
+	api.GET("/auth/oidc/providers", apiHandler.GetAuthOidcProviders)
+	api.GET("/auth/oidc/:provider/authorize", func(c echo.Context) error {
+		return apiHandler.GetAuthOidcProviderAuthorize(c, c.Param("provider"))
+	})
+	api.POST("/auth/oidc/:provider/callback", func(c echo.Context) error {
+		return apiHandler.PostAuthOidcProviderCallback(c, c.Param("provider"))
+	})
+	api.POST("/auth/refresh", apiHandler.PostAuthRefresh)
+	api.POST("/auth/logout", apiHandler.PostAuthLogout)
 
 	// 静的ファイル配信（OGP画像）
 	api.GET("/logo-ogp.png", func(c echo.Context) error {
@@ -93,6 +168,11 @@ func SetupRouter(database *sql.DB) *echo.Echo {
 	protectedApi := api.Group("")
 	protectedApi.Use(authService.AuthMiddleware())
 
+	protectedApi.POST("/ws-ticket", apiHandler.PostWsTicket)
+	protectedApi.POST("/auth/upgrade", apiHandler.PostAuthUpgrade)
+	protectedApi.POST("/auth/oidc/:provider/link", func(c echo.Context) error {
+		return apiHandler.PostAuthOidcProviderLink(c, c.Param("provider"))
+	})
 	protectedApi.GET("/rooms", apiHandler.GetRooms)
 	protectedApi.POST("/rooms/:roomId/actions", func(c echo.Context) error {
 		roomId, _ := strconv.Atoi(c.Param("roomId"))
@@ -106,6 +186,27 @@ func SetupRouter(database *sql.DB) *echo.Echo {
 		roomId, _ := strconv.Atoi(c.Param("roomId"))
 		return apiHandler.GetRoomsRoomIdResult(c, roomId)
 	})
+	protectedApi.GET("/games/:gameId/replay", func(c echo.Context) error {
+		gameId, err := strconv.Atoi(c.Param("gameId"))
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid game ID"})
+		}
+		return apiHandler.GetGamesGameIdReplay(c, gameId)
+	})
+	protectedApi.GET("/leaderboard", apiHandler.GetLeaderboard)
+	protectedApi.GET("/ratings", apiHandler.GetRatings)
+	protectedApi.GET("/users/me", apiHandler.GetUsersMe)
+	protectedApi.GET("/users/:userId/stats", func(c echo.Context) error {
+		userId, err := strconv.Atoi(c.Param("userId"))
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid user ID"})
+		}
+		return apiHandler.GetUsersUserIdStats(c, userId)
+	})
 
-	return e
+	return e, func() {
+		stopCleanup()
+		submissionLog.Close()
+		roomStore.Close()
+	}
 }
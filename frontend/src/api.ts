@@ -1,5 +1,5 @@
 import axios, { type AxiosResponse, type AxiosError } from "axios";
-import { getConfig } from "@/config/app";
+import { getConfig, getWsUrl } from "@/config/app";
 
 // API response type
 export interface ApiResponse<T = any> {
@@ -99,37 +99,39 @@ export class ApiClient {
   }
 
   // User management
-  async createUser(userData: UserData): Promise<ApiResponse> {
-    try {
-      const config: any = {
-        method: "POST",
-        url: `${this.baseUrl}/users`,
-        data: userData,
-        headers: {
-          "Content-Type": "application/json",
-        },
-      };
+  // 新規登録。既存のユーザー名は409で、ログインはしない
+  async registerUser(userData: UserData): Promise<ApiResponse> {
+    return this.authenticate("/auth/register", userData);
+  }
 
-      const response: AxiosResponse = await axios(config);
+  // 既存のユーザーでログイン
+  async loginUser(userData: UserData): Promise<ApiResponse> {
+    return this.authenticate("/auth/login", userData);
+  }
 
-      // レスポンスボディからtokenを取得して設定
-      if (response.data && response.data.token) {
-        this.setAuthToken(response.data.token);
-      }
+  private async authenticate(endpoint: string, userData: UserData): Promise<ApiResponse> {
+    const response = await this.makeRequest("POST", endpoint, userData);
 
-      return {
-        success: true,
-        status: response.status,
-        data: response.data,
-      };
-    } catch (error) {
-      const axiosError = error as AxiosError;
-      return {
-        success: false,
-        status: axiosError.response?.status || 0,
-        data: (axiosError.response?.data || axiosError.message) as any,
-      };
+    // レスポンスボディからtokenを取得して設定
+    if (response.success && response.data?.token) {
+      this.setAuthToken(response.data.token);
+    }
+    return response;
+  }
+
+  // WebSocket接続用の使い捨てチケット（有効期間30秒、1回の接続にだけ使える）
+  async issueWsTicket(): Promise<ApiResponse<{ ticket: string; expires_in: number }>> {
+    return this.makeRequest("POST", "/ws-ticket", undefined, true);
+  }
+
+  // WebSocketの接続先。接続のたびに新しいチケットを発行してURLに付ける
+  async getAuthenticatedWsUrl(params: string = ""): Promise<string> {
+    const response = await this.issueWsTicket();
+    if (!response.success) {
+      throw new Error(`failed to issue WebSocket ticket (status ${response.status})`);
     }
+    const ticket = `ticket=${encodeURIComponent(response.data.ticket)}`;
+    return getWsUrl(params ? `${ticket}&${params}` : ticket);
   }
 
   // Rooms
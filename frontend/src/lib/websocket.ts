@@ -111,6 +111,10 @@ export const WS_EVENTS = {
   GAME_ENDED: "game_ended",
 } as const;
 
+// 接続のたびに呼ばれ、接続先のURLを返す関数
+// 使い捨てのチケットで認証する場合は、再接続のたびに新しいチケットを発行する
+export type WsUrlProvider = () => Promise<string>;
+
 // WebSocket接続管理クラス
 export class WebSocketManager {
   private ws: WebSocket | null = null;
@@ -124,12 +128,12 @@ export class WebSocketManager {
   public connectionError = ref<string | null>(null);
   public messages = ref<string[]>([]);
 
-  constructor(wsUrl: string, onMessage?: (event: WebSocketEvent) => void) {
-    this.wsUrl = wsUrl;
+  constructor(wsUrl: string | WsUrlProvider, onMessage?: (event: WebSocketEvent) => void) {
+    this.wsUrl = typeof wsUrl === "string" ? () => Promise.resolve(wsUrl) : wsUrl;
     this.onMessage = onMessage;
   }
 
-  private wsUrl: string;
+  private wsUrl: WsUrlProvider;
   private onMessage?: (event: WebSocketEvent) => void;
 
   // WebSocket接続関数
@@ -141,8 +145,20 @@ export class WebSocketManager {
     this.isConnecting.value = true;
     this.connectionError.value = null;
 
+    this.wsUrl()
+      .then((url) => this.open(url))
+      .catch((error) => {
+        console.error("WebSocket接続先の取得に失敗:", error);
+        this.connectionError.value = "WebSocket接続の認証に失敗しました";
+        this.isConnecting.value = false;
+        this.addMessage("❌ WebSocket接続の認証に失敗");
+        this.attemptReconnect();
+      });
+  }
+
+  private open(url: string): void {
     try {
-      this.ws = new WebSocket(this.wsUrl);
+      this.ws = new WebSocket(url);
 
       // 接続開始のタイムアウト設定
       this.connectTimeout = setTimeout(() => {
@@ -413,7 +429,7 @@ export class WebSocketManager {
 }
 
 // WebSocket接続用のComposable関数
-export function useWebSocket(wsUrl: string, onMessage?: (event: WebSocketEvent) => void) {
+export function useWebSocket(wsUrl: string | WsUrlProvider, onMessage?: (event: WebSocketEvent) => void) {
   const manager = new WebSocketManager(wsUrl, onMessage);
 
   return {
@@ -2,7 +2,7 @@ import { defineStore } from "pinia";
 import { ref } from "vue";
 import { useWebSocket, type WebSocketEvent } from "@/lib/websocket";
 import { type ResultPlayer, type StartPlayer, type Room } from "@/lib/types";
-import { getWsUrl } from "@/config/app";
+import { apiClient } from "@/api";
 
 export const useNotificationStore = defineStore("notificationStore", () => {
   const notifications = ref<string[]>([]);
@@ -149,9 +149,8 @@ export const useWebSocketStore = defineStore("webSocketStore", () => {
     }
 
     currentUsername.value = username;
-    const wsUrl = getWsUrl(`username=${encodeURIComponent(username)}`);
-
-    wsManager.value = useWebSocket(wsUrl, (event: WebSocketEvent) => {
+    // usernameは表示用。接続の認証はログイン中のトークンで発行したチケットで行う
+    wsManager.value = useWebSocket(() => apiClient.getAuthenticatedWsUrl(), (event: WebSocketEvent) => {
       console.log("Global WebSocket received:", event);
       if (onMessage) {
         onMessage(event);